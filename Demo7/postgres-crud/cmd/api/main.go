@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"postgres-crud/config"
 	"postgres-crud/database"
 	"postgres-crud/internal/router"
 	"postgres-crud/model"
+	"postgres-crud/repository"
+	"postgres-crud/service"
 )
 
 func main() {
@@ -28,12 +31,61 @@ func main() {
 	// Run database migrations
 	// Migrate OrderProduct first (join table), then Order and Product
 	// This ensures the join table exists before the many-to-many relationships are set up
-	if err := database.Migrate(&model.OrderProduct{}, &model.Order{}, &model.Product{}); err != nil {
+	if err := database.Migrate(&model.OrderProduct{}, &model.Customer{}, &model.Address{}, &model.Order{}, &model.Product{}, &model.OrderStatusTransition{}, &model.StockMovement{}, &model.PriceListEntry{}, &model.ExchangeRate{}, &model.Category{}, &model.Tag{}, &model.ProductVariant{}, &model.Promotion{}, &model.OrderPromotion{}, &model.TaxRate{}, &model.OrderTaxLine{}, &model.ProductPrice{}, &model.ScheduledPrice{}, &model.Return{}, &model.ReturnLine{}, &model.Invoice{}, &model.InvoiceSequence{}); err != nil {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	// Order lines are keyed by variant as well as product since variants were added
+	if err := database.EnsurePrimaryKey("order_products", "order_id", "product_id", "variant_id"); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	// Give products created before the stock ledger existed an opening balance
+	backfilled, err := repository.NewStockMovementRepository().BackfillOpeningBalances()
+	if err != nil {
+		log.Fatal("Failed to backfill stock ledger:", err)
+	}
+	if backfilled > 0 {
+		log.Printf("Recorded opening stock balances for %d products", backfilled)
+	}
+
+	// Give order lines added before lines kept a product snapshot the current product details
+	backfilled, err = repository.NewOrderRepository().BackfillLineSnapshots()
+	if err != nil {
+		log.Fatal("Failed to backfill order line snapshots:", err)
+	}
+	if backfilled > 0 {
+		log.Printf("Recorded product snapshots for %d order lines", backfilled)
+	}
+
+	// Give products created before price history existed their current price as a starting point
+	backfilled, err = repository.NewPriceHistoryRepository().BackfillInitialPrices()
+	if err != nil {
+		log.Fatal("Failed to backfill price history:", err)
+	}
+	if backfilled > 0 {
+		log.Printf("Recorded initial prices for %d products", backfilled)
+	}
+
+	// Permanently remove records that have been in the trash past the retention period
+	if cfg.Retention.Period > 0 {
+		retention := service.NewRetentionWorker(
+			repository.NewOrderRepository(),
+			repository.NewProductRepository(),
+			repository.NewUnitOfWork(),
+			service.RetentionPolicy{
+				Period:    cfg.Retention.Period,
+				Interval:  cfg.Retention.Interval,
+				BatchSize: cfg.Retention.BatchSize,
+				DryRun:    cfg.Retention.DryRun,
+			},
+		)
+		retention.Start(context.Background())
+		log.Printf("Retention worker purging records deleted more than %s ago, every %s", cfg.Retention.Period, cfg.Retention.Interval)
+	}
+
 	// Setup router
-	r := router.SetupRouter()
+	r := router.SetupRouter(cfg)
 
 	// Start server
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
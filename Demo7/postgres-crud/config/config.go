@@ -3,12 +3,19 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	Pricing   PricingConfig
+	Admin     AdminConfig
+	Retention RetentionConfig
+	Documents DocumentConfig
 }
 
 // DatabaseConfig holds database connection configuration
@@ -27,6 +34,32 @@ type ServerConfig struct {
 	Host string
 }
 
+// PricingConfig holds order pricing configuration
+type PricingConfig struct {
+	TaxRate float64 // Default percentage, e.g. 8.25 for 8.25%, where no tax table rate applies
+}
+
+// AdminConfig holds configuration for the admin endpoints
+type AdminConfig struct {
+	APIKey string // Admin endpoints are disabled when empty
+}
+
+// RetentionConfig holds configuration for the job that permanently removes
+// soft-deleted records
+type RetentionConfig struct {
+	Period    time.Duration // Age of deletion after which records are purged; 0 disables the job
+	Interval  time.Duration // Time between runs
+	BatchSize int           // Records purged per transaction
+	DryRun    bool          // Only log what would be purged
+}
+
+// DocumentConfig holds configuration for invoices and packing slips
+type DocumentConfig struct {
+	CompanyName    string // Printed at the top of documents; omitted when empty
+	CompanyAddress string // Address lines separated by "|"
+	InvoicePrefix  string // Put in front of the invoice sequence number
+}
+
 // LoadConfig loads configuration from environment variables or uses defaults
 func LoadConfig() *Config {
 	return &Config{
@@ -42,6 +75,23 @@ func LoadConfig() *Config {
 			Port: getEnv("SERVER_PORT", "8080"),
 			Host: getEnv("SERVER_HOST", "0.0.0.0"),
 		},
+		Pricing: PricingConfig{
+			TaxRate: getEnvFloat("TAX_RATE", 0),
+		},
+		Admin: AdminConfig{
+			APIKey: getEnv("ADMIN_API_KEY", ""),
+		},
+		Retention: RetentionConfig{
+			Period:    getEnvDuration("RETENTION_PERIOD", 0),
+			Interval:  getEnvDuration("RETENTION_INTERVAL", time.Hour),
+			BatchSize: getEnvInt("RETENTION_BATCH_SIZE", 100),
+			DryRun:    getEnvBool("RETENTION_DRY_RUN", false),
+		},
+		Documents: DocumentConfig{
+			CompanyName:    getEnv("COMPANY_NAME", ""),
+			CompanyAddress: getEnv("COMPANY_ADDRESS", ""),
+			InvoicePrefix:  getEnv("INVOICE_PREFIX", "INV-"),
+		},
 	}
 }
 
@@ -51,6 +101,17 @@ func (c *DatabaseConfig) DSN() string {
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
 }
 
+// CompanyAddressLines returns the lines of the company address
+func (c *DocumentConfig) CompanyAddressLines() []string {
+	var lines []string
+	for _, line := range strings.Split(c.CompanyAddress, "|") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -58,3 +119,44 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvFloat gets an environment variable as a float or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvInt gets an environment variable as an integer or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool gets an environment variable as a boolean or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration gets an environment variable as a duration (such as "720h")
+// or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"postgres-crud/config"
 
@@ -56,3 +57,47 @@ func Migrate(models ...interface{}) error {
 	log.Println("Database migrations completed successfully")
 	return nil
 }
+
+// EnsurePrimaryKey makes columns the primary key of table. AutoMigrate never
+// changes an existing primary key, so tables whose key has grown are rekeyed
+// here. Nothing is done when the key already covers all the columns.
+func EnsurePrimaryKey(table string, columns ...string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	var count int64
+	if err := DB.Raw(`
+		SELECT COUNT(*)
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+		  ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+		WHERE tc.table_schema = CURRENT_SCHEMA()
+		  AND tc.table_name = ?
+		  AND tc.constraint_type = 'PRIMARY KEY'
+		  AND kcu.column_name IN ?`, table, columns).Scan(&count).Error; err != nil {
+		return fmt.Errorf("failed to inspect primary key of %s: %w", table, err)
+	}
+	if count == int64(len(columns)) {
+		return nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = DB.Statement.Quote(column)
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s",
+			tx.Statement.Quote(table), tx.Statement.Quote(table+"_pkey"))).Error; err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)",
+			tx.Statement.Quote(table), strings.Join(quoted, ", "))).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change primary key of %s: %w", table, err)
+	}
+
+	log.Printf("Primary key of %s changed to (%s)", table, strings.Join(columns, ", "))
+	return nil
+}
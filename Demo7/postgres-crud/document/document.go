@@ -0,0 +1,120 @@
+package document
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Format is an output format of a document
+type Format string
+
+// Supported document formats
+const (
+	FormatHTML Format = "html"
+	FormatPDF  Format = "pdf"
+)
+
+// ParseFormat returns the format named by s, ignoring case
+func ParseFormat(s string) (Format, bool) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case FormatHTML:
+		return FormatHTML, true
+	case FormatPDF:
+		return FormatPDF, true
+	}
+	return "", false
+}
+
+// ContentType returns the MIME type of documents in the format
+func (f Format) ContentType() string {
+	if f == FormatPDF {
+		return "application/pdf"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// Document is data rendered with the templates of the same name
+type Document interface {
+	// Template returns the base name of the document's templates
+	Template() string
+	// Title returns the title of the rendered document
+	Title() string
+	// FileName returns the file name, without extension, the document is
+	// offered for download under
+	FileName() string
+}
+
+//go:embed templates
+var templates embed.FS
+
+// funcs are the functions available to document templates
+var funcs = map[string]interface{}{
+	"date":    formatDate,
+	"percent": formatPercent,
+	"flat":    flatten,
+	"cells":   cells,
+}
+
+// Renderer renders documents from Go templates. HTML is rendered with
+// html/template; PDF is rendered with text/template into a layout script that
+// is laid out by a built-in PDF writer, so no external tools are needed.
+type Renderer struct {
+	html *htmltemplate.Template
+	pdf  *texttemplate.Template
+}
+
+// NewRenderer creates a Renderer from the built-in templates
+func NewRenderer() *Renderer {
+	return &Renderer{
+		html: htmltemplate.Must(htmltemplate.New("").Funcs(funcs).ParseFS(templates, "templates/*.html.tmpl")),
+		pdf:  texttemplate.Must(texttemplate.New("").Funcs(funcs).ParseFS(templates, "templates/*.pdf.tmpl")),
+	}
+}
+
+// Render writes doc to w in format
+func (r *Renderer) Render(w io.Writer, doc Document, format Format) error {
+	name := doc.Template() + "." + string(format) + ".tmpl"
+	switch format {
+	case FormatHTML:
+		return r.html.ExecuteTemplate(w, name, doc)
+	case FormatPDF:
+		var script bytes.Buffer
+		if err := r.pdf.ExecuteTemplate(&script, name, doc); err != nil {
+			return err
+		}
+		return renderLayout(w, doc.Title(), &script)
+	}
+	return fmt.Errorf("unsupported document format %q", format)
+}
+
+// formatDate formats the date part of t
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// formatPercent formats a percentage without trailing zeros
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64) + "%"
+}
+
+// flatten replaces line breaks and tabs in a value with spaces, so that it
+// stays on its line of a layout script
+func flatten(value interface{}) string {
+	return strings.Join(strings.Fields(fmt.Sprint(value)), " ")
+}
+
+// cells joins values into the cells of a layout script row
+func cells(values ...interface{}) string {
+	flat := make([]string, len(values))
+	for i, value := range values {
+		flat[i] = flatten(value)
+	}
+	return strings.Join(flat, "\t")
+}
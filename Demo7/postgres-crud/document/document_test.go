@@ -0,0 +1,259 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"postgres-crud/money"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testInvoice returns an invoice with the given number of lines
+func testInvoice(lines int) Invoice {
+	invoice := Invoice{
+		Number:    "INV-000042",
+		IssuedAt:  time.Date(2024, 5, 2, 10, 15, 0, 0, time.UTC),
+		OrderID:   17,
+		OrderDate: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		Currency:  "EUR",
+		Issuer:    Party{Name: "Acme Ltd", Address: []string{"1 High Street", "London"}},
+		BillTo:    Party{Name: "Jane Doe", Address: []string{"2 Low Road", "10115 Berlin", "DE"}},
+		ShipTo:    Party{Name: "Jane Doe", Address: []string{"3 Side Lane", "80331 München", "DE"}},
+		TaxLines: []InvoiceTaxLine{
+			{TaxClass: "standard", Rate: 19, TaxableAmount: money.New(1000, "EUR"), Tax: money.New(190, "EUR")},
+		},
+		DiscountTotal: money.New(100, "EUR"),
+	}
+	var subtotal int64
+	for i := 1; i <= lines; i++ {
+		amount := int64(550 * i)
+		invoice.Lines = append(invoice.Lines, InvoiceLine{
+			Description:     fmt.Sprintf("Widget %d", i),
+			SKU:             fmt.Sprintf("W-%03d", i),
+			Quantity:        i,
+			UnitPrice:       money.New(550, "EUR"),
+			DiscountPercent: 10,
+			Amount:          money.New(amount, "EUR"),
+		})
+		subtotal += amount
+	}
+	invoice.Subtotal = money.New(subtotal, "EUR")
+	invoice.TaxTotal = money.New(190, "EUR")
+	invoice.GrandTotal = money.New(subtotal-100+190, "EUR")
+	return invoice
+}
+
+// testPackingSlip returns a packing slip with the given number of lines
+func testPackingSlip(lines int) PackingSlip {
+	slip := PackingSlip{
+		OrderID:   17,
+		OrderDate: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		PrintedAt: time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC),
+		Issuer:    Party{Name: "Acme Ltd"},
+		ShipTo:    Party{Name: "Jane Doe", Address: []string{"3 Side Lane", "80331 München"}},
+	}
+	for i := 1; i <= lines; i++ {
+		slip.Lines = append(slip.Lines, PackingSlipLine{
+			ProductName: fmt.Sprintf("Widget %d", i),
+			SKU:         fmt.Sprintf("W-%03d", i),
+			Quantity:    2,
+		})
+		slip.TotalQuantity += 2
+	}
+	return slip
+}
+
+// render renders doc in format, failing the test on errors
+func render(t *testing.T, doc Document, format Format) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := NewRenderer().Render(&out, doc, format); err != nil {
+		t.Fatalf("Render(%s, %s) error = %v", doc.Template(), format, err)
+	}
+	return out.Bytes()
+}
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  Document
+		want []string
+	}{
+		{
+			name: "invoice",
+			doc:  testInvoice(2),
+			want: []string{
+				"<title>Invoice INV-000042</title>",
+				"Invoice date: 2024-05-02",
+				"Order: 17 of 2024-05-01",
+				"Acme Ltd", "Bill to", "Ship to", "80331 München",
+				"Widget 1", "W-002", "5.50", "10%", "11.00",
+				"16.50", "-1.00", "1.90", "17.40",
+			},
+		},
+		{
+			name: "packing slip",
+			doc:  testPackingSlip(2),
+			want: []string{
+				"<title>Packing slip for order 17</title>",
+				"Printed: 2024-05-03",
+				"Widget 2", "W-001", "80331 München", "Total items",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := string(render(t, tt.doc, FormatHTML))
+			if !strings.HasPrefix(html, "<!DOCTYPE html>") {
+				t.Errorf("HTML does not start with a doctype: %.40q", html)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("HTML does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHTMLEscapesValues(t *testing.T) {
+	invoice := testInvoice(1)
+	invoice.Lines[0].Description = `<script>alert("x")</script>`
+	invoice.BillTo.Name = "Smith & Sons"
+
+	html := string(render(t, invoice, FormatHTML))
+	if strings.Contains(html, "<script>") {
+		t.Error("line description was not escaped")
+	}
+	if !strings.Contains(html, "&lt;script&gt;") || !strings.Contains(html, "Smith &amp; Sons") {
+		t.Error("escaped values are missing")
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  Document
+		want []string
+	}{
+		{
+			name: "invoice",
+			doc:  testInvoice(2),
+			want: []string{
+				"/Title (Invoice INV-000042)",
+				"(Invoice INV-000042) Tj",
+				"(Invoice date: 2024-05-02) Tj",
+				`(80331 M\374nchen) Tj`,
+				"(Amount \\(EUR\\)) Tj",
+				"(Widget 2) Tj", "(W-001) Tj", "(10%) Tj",
+				"(Subtotal) Tj", "(16.50) Tj", "(-1.00) Tj",
+				"(Tax standard 19% on 10.00) Tj",
+				"(Total EUR) Tj", "(17.40) Tj",
+				"(Page 1 of 1) Tj",
+			},
+		},
+		{
+			name: "packing slip",
+			doc:  testPackingSlip(2),
+			want: []string{
+				"/Title (Packing slip for order 17)",
+				"(Packing slip) Tj",
+				"(Widget 1) Tj", "(W-002) Tj", "([ ]) Tj",
+				"(Total items) Tj", "(4) Tj",
+				"(Page 1 of 1) Tj",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := render(t, tt.doc, FormatPDF)
+			if pages := checkPDF(t, data); pages != 1 {
+				t.Errorf("PDF has %d pages, want 1", pages)
+			}
+			for _, want := range tt.want {
+				if !bytes.Contains(data, []byte(want)) {
+					t.Errorf("PDF does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPDFEscapesValues(t *testing.T) {
+	invoice := testInvoice(1)
+	invoice.Lines[0].Description = `Cable (2m) \ spare`
+	invoice.BillTo.Name = "O'Brien (Billing)\ttab\nnewline"
+
+	data := render(t, invoice, FormatPDF)
+	checkPDF(t, data)
+	if !bytes.Contains(data, []byte(`(Cable \(2m\) \\ spare) Tj`)) {
+		t.Error("parentheses and backslashes in a table cell were not escaped")
+	}
+	// Line breaks and tabs are flattened so the value stays on its line of
+	// the layout script
+	if !bytes.Contains(data, []byte(`(O'Brien \(Billing\) tab newline) Tj`)) {
+		t.Error("bill-to name was not flattened and escaped")
+	}
+}
+
+func TestRenderPDFPageBreaks(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    Document
+		header string
+		last   string
+	}{
+		{"invoice", testInvoice(80), "(Description) Tj", "(Widget 80) Tj"},
+		{"packing slip", testPackingSlip(80), "(Product) Tj", "(Widget 80) Tj"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := render(t, tt.doc, FormatPDF)
+			pages := checkPDF(t, data)
+			if pages < 2 {
+				t.Fatalf("80 lines fit on %d page, want them to spill onto a second page", pages)
+			}
+			if got := bytes.Count(data, []byte(tt.header)); got < 2 {
+				t.Errorf("table header appears %d times, want it repeated on the second page", got)
+			}
+			if !bytes.Contains(data, []byte(tt.last)) {
+				t.Errorf("last line %s is missing", tt.last)
+			}
+			if !bytes.Contains(data, []byte(fmt.Sprintf("(Page 2 of %d) Tj", pages))) {
+				t.Error("second page is not numbered")
+			}
+		})
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewRenderer().Render(&out, testInvoice(1), Format("docx")); err == nil {
+		t.Error("Render() accepted an unknown format")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   Format
+		wantOK bool
+	}{
+		{"pdf", FormatPDF, true},
+		{" HTML ", FormatHTML, true},
+		{"Pdf", FormatPDF, true},
+		{"docx", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseFormat(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package document
+
+import (
+	"postgres-crud/money"
+	"time"
+)
+
+// Party is a name and postal address printed on a document
+type Party struct {
+	Name    string
+	Address []string
+}
+
+// Invoice is the data of an invoice document. The amounts are in Currency;
+// Subtotal is the sum of the line amounts before any discount and
+// DiscountTotal includes line, promotion and order discounts.
+type Invoice struct {
+	Number        string
+	IssuedAt      time.Time
+	OrderID       uint
+	OrderDate     time.Time
+	Currency      string
+	Issuer        Party
+	BillTo        Party
+	ShipTo        Party
+	Lines         []InvoiceLine
+	Subtotal      money.Money
+	DiscountTotal money.Money
+	TaxLines      []InvoiceTaxLine
+	TaxTotal      money.Money
+	GrandTotal    money.Money
+	CancelledAt   *time.Time
+}
+
+// InvoiceLine is an order line on an invoice. Amount is the unit price times
+// the quantity, before the line discount.
+type InvoiceLine struct {
+	Description     string
+	SKU             string
+	Quantity        int
+	UnitPrice       money.Money
+	DiscountPercent float64
+	Amount          money.Money
+}
+
+// InvoiceTaxLine is the tax charged at one rate and tax class
+type InvoiceTaxLine struct {
+	TaxClass      string
+	Rate          float64
+	TaxableAmount money.Money
+	Tax           money.Money
+}
+
+// Template implements Document
+func (Invoice) Template() string {
+	return "invoice"
+}
+
+// Title implements Document
+func (i Invoice) Title() string {
+	return "Invoice " + i.Number
+}
+
+// FileName implements Document
+func (i Invoice) FileName() string {
+	return "invoice-" + i.Number
+}
@@ -0,0 +1,246 @@
+package document
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PDF documents are rendered from a text template into a layout script, which
+// lays out one block per line:
+//
+//	title <text>        large bold line
+//	heading <text>      bold line with space above it
+//	text <text>         regular line, wrapped to the page width
+//	small <text>        small regular line
+//	columns <x>[r] ...  column positions of the following rows, in points from
+//	                    the left margin; an r suffix right-aligns the column at x
+//	header <cells>      bold row, repeated at the top of each new page
+//	row <cells>         regular row
+//	rule                line across the page
+//	space <points>      vertical space
+//
+// Cells are separated by tabs. Empty lines are ignored. Pages are broken as
+// needed and numbered at the bottom.
+
+// Font sizes and line heights of the layout blocks, in points
+const (
+	titleSize   = 18.0
+	headingSize = 11.0
+	textSize    = 10.0
+	smallSize   = 8.0
+	lineFactor  = 1.4
+)
+
+// column is a column position of table rows
+type column struct {
+	x     float64
+	right bool
+}
+
+// layout places layout script blocks on the pages of a PDF
+type layout struct {
+	pdf     *pdfWriter
+	y       float64
+	columns []column
+	header  []string
+}
+
+// renderLayout lays out a layout script read from r and writes the PDF to w
+func renderLayout(w io.Writer, title string, r io.Reader) error {
+	l := &layout{pdf: newPDFWriter(title)}
+	l.newPage()
+
+	scanner := bufio.NewScanner(r)
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := l.block(line); err != nil {
+			return fmt.Errorf("layout line %d: %w", number, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	l.numberPages()
+	return l.pdf.writeTo(w)
+}
+
+// block lays out one line of the layout script
+func (l *layout) block(line string) error {
+	directive, arg, _ := strings.Cut(strings.TrimLeft(line, " \t"), " ")
+	switch directive {
+	case "title":
+		l.header = nil
+		l.textLine(arg, fontBold, titleSize)
+	case "heading":
+		l.header = nil
+		l.space(headingSize * 0.8)
+		l.textLine(arg, fontBold, headingSize)
+	case "text":
+		l.header = nil
+		for _, wrapped := range wrapText(arg, fontRegular, textSize, pageWidth-marginLeft-marginRight) {
+			l.textLine(wrapped, fontRegular, textSize)
+		}
+	case "small":
+		l.textLine(arg, fontRegular, smallSize)
+	case "columns":
+		columns, err := parseColumns(arg)
+		if err != nil {
+			return err
+		}
+		l.columns = columns
+		l.header = nil
+	case "header":
+		l.header = strings.Split(arg, "\t")
+		l.row(l.header, fontBold)
+	case "row":
+		l.row(strings.Split(arg, "\t"), fontRegular)
+	case "rule":
+		l.ensure(textSize)
+		l.y -= textSize * 0.4
+		l.pdf.line(marginLeft, l.y, pageWidth-marginRight, l.y)
+		l.y -= textSize * 0.6
+	case "space":
+		points, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return fmt.Errorf("invalid space %q", arg)
+		}
+		l.space(points)
+	default:
+		return fmt.Errorf("unknown directive %q", directive)
+	}
+	return nil
+}
+
+// parseColumns parses the column positions of a columns directive
+func parseColumns(arg string) ([]column, error) {
+	fields := strings.Fields(arg)
+	columns := make([]column, len(fields))
+	for i, field := range fields {
+		right := strings.HasSuffix(field, "r")
+		x, err := strconv.ParseFloat(strings.TrimSuffix(field, "r"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column %q", field)
+		}
+		columns[i] = column{x: marginLeft + x, right: right}
+	}
+	return columns, nil
+}
+
+// newPage starts a new page at its top margin
+func (l *layout) newPage() {
+	l.pdf.addPage()
+	l.y = pageHeight - marginTop
+}
+
+// ensure starts a new page unless height points fit above the bottom margin
+func (l *layout) ensure(height float64) bool {
+	if l.y-height >= marginBottom {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+// space moves down by points, without breaking the page
+func (l *layout) space(points float64) {
+	l.y -= points
+}
+
+// textLine lays out a single line of text at the left margin
+func (l *layout) textLine(s string, font pdfFont, size float64) {
+	height := size * lineFactor
+	l.ensure(height)
+	l.y -= height
+	l.pdf.text(marginLeft, l.y+size*(lineFactor-1), font, size, s)
+}
+
+// row lays out cells at the current column positions. Left-aligned cells that
+// do not fit before the next column are shortened. When the row starts a new
+// page the last header row is repeated above it.
+func (l *layout) row(cells []string, font pdfFont) {
+	height := textSize * lineFactor
+	if l.ensure(height) && l.header != nil && font != fontBold {
+		l.row(l.header, fontBold)
+	}
+	l.y -= height
+	baseline := l.y + textSize*(lineFactor-1)
+
+	for i, cell := range cells {
+		if i >= len(l.columns) {
+			break
+		}
+		col := l.columns[i]
+		if col.right {
+			l.pdf.text(col.x-textWidth(cell, font, textSize), baseline, font, textSize, cell)
+			continue
+		}
+
+		limit := pageWidth - marginRight
+		if i+1 < len(l.columns) {
+			limit = l.columns[i+1].x
+			if l.columns[i+1].right && i+1 < len(cells) {
+				limit -= textWidth(cells[i+1], font, textSize)
+			}
+			limit -= 8
+		}
+		l.pdf.text(col.x, baseline, font, textSize, truncateText(cell, font, textSize, limit-col.x))
+	}
+}
+
+// numberPages writes "Page n of m" at the bottom of every page
+func (l *layout) numberPages() {
+	total := len(l.pdf.pages)
+	for i, page := range l.pdf.pages {
+		label := fmt.Sprintf("Page %d of %d", i+1, total)
+		x := pageWidth - marginRight - textWidth(label, fontRegular, smallSize)
+		l.pdf.textOn(page, x, marginBottom/2, fontRegular, smallSize, label)
+	}
+}
+
+// wrapText breaks s into lines no wider than width, at spaces where possible
+func wrapText(s string, font pdfFont, size, width float64) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && textWidth(candidate, font, size) > width {
+			lines = append(lines, current)
+			candidate = word
+		}
+		current = candidate
+	}
+	return append(lines, current)
+}
+
+// truncateText shortens s with an ellipsis until it is no wider than width
+func truncateText(s string, font pdfFont, size, width float64) string {
+	if textWidth(s, font, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := strings.TrimRight(string(runes), " ") + "..."
+		if textWidth(shortened, font, size) <= width {
+			return shortened
+		}
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	width := 100.0
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 1},
+		{"short", "Fits on one line", 1},
+		{"long", strings.Repeat("word ", 40), 0},
+		{"single long word", strings.Repeat("x", 80), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := wrapText(tt.in, fontRegular, textSize, width)
+			if tt.want != 0 && len(lines) != tt.want {
+				t.Fatalf("wrapText() gave %d lines, want %d: %q", len(lines), tt.want, lines)
+			}
+			if tt.want == 0 && len(lines) < 2 {
+				t.Fatalf("wrapText() did not wrap: %q", lines)
+			}
+			for _, line := range lines {
+				if strings.Contains(line, " ") && textWidth(line, fontRegular, textSize) > width {
+					t.Errorf("line %q is wider than %v", line, width)
+				}
+			}
+			if got, want := strings.Join(lines, " "), strings.Join(strings.Fields(tt.in), " "); got != want {
+				t.Errorf("wrapped lines %q do not hold the words of %q", lines, tt.in)
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width float64
+		want  string
+	}{
+		{"fits", "Widget", 100, "Widget"},
+		{"too wide", "An extremely long product description", 80, ""},
+		{"no room", "Widget", 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateText(tt.in, fontRegular, textSize, tt.width)
+			if tt.want != "" && got != tt.want {
+				t.Fatalf("truncateText() = %q, want %q", got, tt.want)
+			}
+			if textWidth(got, fontRegular, textSize) > tt.width {
+				t.Errorf("truncateText() = %q, wider than %v", got, tt.width)
+			}
+			if tt.want == "" && tt.width > 10 {
+				if !strings.HasSuffix(got, "...") || !strings.HasPrefix(tt.in, strings.TrimSuffix(got, "...")) {
+					t.Errorf("truncateText() = %q, want a prefix of %q with an ellipsis", got, tt.in)
+				}
+			}
+		})
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := parseColumns("0 170 280r")
+	if err != nil {
+		t.Fatalf("parseColumns() error = %v", err)
+	}
+	want := []column{{marginLeft, false}, {marginLeft + 170, false}, {marginLeft + 280, true}}
+	if len(columns) != len(want) {
+		t.Fatalf("parseColumns() = %v, want %v", columns, want)
+	}
+	for i := range want {
+		if columns[i] != want[i] {
+			t.Errorf("column %d = %v, want %v", i, columns[i], want[i])
+		}
+	}
+
+	if _, err := parseColumns("0 wide"); err == nil {
+		t.Error("parseColumns() accepted an invalid column")
+	}
+}
+
+func TestRenderLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"unknown directive", "title Invoice\nbanner Sale\n"},
+		{"invalid space", "space lots\n"},
+		{"invalid columns", "columns 0 x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := renderLayout(&out, "Test", strings.NewReader(tt.script)); err == nil {
+				t.Error("renderLayout() accepted an invalid script")
+			}
+		})
+	}
+}
+
+func TestRenderLayoutPageBreaks(t *testing.T) {
+	var script strings.Builder
+	script.WriteString("title Report\n\ncolumns 0 300r\n")
+	script.WriteString("header Item\tAmount\n")
+	for i := 1; i <= 120; i++ {
+		fmt.Fprintf(&script, "row Item %d\t%d.00\n", i, i)
+	}
+	script.WriteString("text " + strings.Repeat("A long closing paragraph. ", 60) + "\n")
+
+	var out bytes.Buffer
+	if err := renderLayout(&out, "Report", strings.NewReader(script.String())); err != nil {
+		t.Fatalf("renderLayout() error = %v", err)
+	}
+	data := out.Bytes()
+	pages := checkPDF(t, data)
+	if pages < 3 {
+		t.Fatalf("120 rows and a long paragraph fit on %d pages, want at least 3", pages)
+	}
+
+	for i := 1; i <= 120; i++ {
+		if !bytes.Contains(data, []byte(fmt.Sprintf("(Item %d) Tj", i))) {
+			t.Fatalf("row %d is missing", i)
+		}
+	}
+	// The header is repeated above the rows continued on each new page
+	if got := bytes.Count(data, []byte("(Item) Tj")); got < 2 {
+		t.Errorf("header row appears %d times, want it repeated on the next page", got)
+	}
+	for i := 1; i <= pages; i++ {
+		label := fmt.Sprintf("(Page %d of %d) Tj", i, pages)
+		if !bytes.Contains(data, []byte(label)) {
+			t.Errorf("page %d is not numbered", i)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package document
+
+import (
+	"strconv"
+	"time"
+)
+
+// PackingSlip is the data of a packing slip, listing what goes into the
+// parcel of an order
+type PackingSlip struct {
+	OrderID       uint
+	OrderDate     time.Time
+	PrintedAt     time.Time
+	Issuer        Party
+	ShipTo        Party
+	Lines         []PackingSlipLine
+	TotalQuantity int
+}
+
+// PackingSlipLine is an order line on a packing slip
+type PackingSlipLine struct {
+	ProductName string
+	SKU         string
+	Quantity    int
+}
+
+// Template implements Document
+func (PackingSlip) Template() string {
+	return "packing_slip"
+}
+
+// Title implements Document
+func (p PackingSlip) Title() string {
+	return "Packing slip for order " + strconv.FormatUint(uint64(p.OrderID), 10)
+}
+
+// FileName implements Document
+func (p PackingSlip) FileName() string {
+	return "packing-slip-" + strconv.FormatUint(uint64(p.OrderID), 10)
+}
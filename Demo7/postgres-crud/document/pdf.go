@@ -0,0 +1,216 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page size and margins of generated PDFs, in points (A4)
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	marginLeft   = 50.0
+	marginRight  = 50.0
+	marginTop    = 60.0
+	marginBottom = 60.0
+)
+
+// pdfFont is one of the standard PDF fonts, which every PDF reader provides
+// without embedding
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+)
+
+// resource returns the name the font is referred to by in content streams
+func (f pdfFont) resource() string {
+	if f == fontBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// pdfWriter assembles a PDF document of text and lines. Text is encoded in
+// WinAnsiEncoding; characters it cannot represent are written as "?".
+type pdfWriter struct {
+	title string
+	pages []*bytes.Buffer
+}
+
+// newPDFWriter creates a PDF writer for a document with the given title
+func newPDFWriter(title string) *pdfWriter {
+	return &pdfWriter{title: title}
+}
+
+// addPage starts a new page; subsequent drawing goes to it
+func (p *pdfWriter) addPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+}
+
+// page returns the content stream of the current page
+func (p *pdfWriter) page() *bytes.Buffer {
+	if len(p.pages) == 0 {
+		p.addPage()
+	}
+	return p.pages[len(p.pages)-1]
+}
+
+// text draws s on the current page with its baseline starting at x, y,
+// measured from the bottom left corner of the page
+func (p *pdfWriter) text(x, y float64, font pdfFont, size float64, s string) {
+	p.textOn(p.page(), x, y, font, size, s)
+}
+
+// textOn draws s on a page like text
+func (p *pdfWriter) textOn(page *bytes.Buffer, x, y float64, font pdfFont, size float64, s string) {
+	fmt.Fprintf(page, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font.resource(), size, x, y, pdfString(s))
+}
+
+// line draws a thin line from x1, y1 to x2, y2
+func (p *pdfWriter) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(p.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// writeTo writes the complete document
+func (p *pdfWriter) writeTo(w io.Writer) error {
+	if len(p.pages) == 0 {
+		p.addPage()
+	}
+
+	// Objects 1 to 5 are the catalog, page tree, fonts and document info;
+	// each page is followed by its content stream.
+	var objects []string
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (postgres-crud) >>", pdfString(p.title)),
+	)
+	for i, content := range p.pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, 7+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfString encodes s as the contents of a PDF literal string in
+// WinAnsiEncoding
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c := winAnsi(r)
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			if c < 32 || c > 126 {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+// winAnsi returns the WinAnsiEncoding code of r, or '?' when it has none.
+// Latin-1 characters keep their code; of the characters WinAnsiEncoding places
+// at 128 to 159 only the most common ones are mapped.
+func winAnsi(r rune) byte {
+	switch {
+	case r >= 32 && r <= 126:
+		return byte(r)
+	case r >= 0xA0 && r <= 0xFF:
+		return byte(r)
+	}
+	switch r {
+	case '€':
+		return 0x80
+	case '‘':
+		return 0x91
+	case '’':
+		return 0x92
+	case '“':
+		return 0x93
+	case '”':
+		return 0x94
+	case '•':
+		return 0x95
+	case '–':
+		return 0x96
+	case '—':
+		return 0x97
+	}
+	return '?'
+}
+
+// textWidth returns the width of s set in font at size points
+func textWidth(s string, font pdfFont, size float64) float64 {
+	widths := &helveticaWidths
+	if font == fontBold {
+		widths = &helveticaBoldWidths
+	}
+
+	var units int
+	for _, r := range s {
+		c := winAnsi(r)
+		if c >= 32 && c <= 126 {
+			units += widths[c-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// helveticaWidths are the glyph widths of Helvetica for the characters 32 to
+// 126, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths are the glyph widths of Helvetica-Bold for the
+// characters 32 to 126, in thousandths of the font size
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
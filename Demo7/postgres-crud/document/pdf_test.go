@@ -0,0 +1,180 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkPDF checks the structure of a generated PDF: the header, that every
+// cross-reference entry points at the start of its object, that startxref
+// points at the table, and that the page tree counts every page. It returns
+// the number of pages.
+func checkPDF(t *testing.T, data []byte) int {
+	t.Helper()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("PDF does not start with a %%PDF-1.4 header: %q", data[:min(len(data), 20)])
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("PDF does not end with %%%%EOF")
+	}
+
+	start := bytes.LastIndex(data, []byte("startxref\n"))
+	if start < 0 {
+		t.Fatal("PDF has no startxref")
+	}
+	offsetLine, _, _ := strings.Cut(string(data[start+len("startxref\n"):]), "\n")
+	xref, err := strconv.Atoi(offsetLine)
+	if err != nil {
+		t.Fatalf("invalid startxref %q", offsetLine)
+	}
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(data[xref:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil {
+		t.Fatalf("invalid xref subsection %q", lines[1])
+	}
+	if first != 0 || count < 2 {
+		t.Fatalf("xref subsection %q, want 0 and at least 2 entries", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("first xref entry = %q, want the free entry", lines[2])
+	}
+	for number := 1; number < count; number++ {
+		entry := lines[2+number]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("xref entry %d = %q", number, entry)
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatalf("xref entry %d = %q", number, entry)
+		}
+		want := fmt.Sprintf("%d 0 obj\n", number)
+		if offset >= len(data) || !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", number, data[offset:min(len(data), offset+len(want))], want)
+		}
+	}
+	if !strings.HasPrefix(lines[2+count], "trailer") {
+		t.Errorf("xref table is not followed by the trailer: %q", lines[2+count])
+	}
+	if trailer := fmt.Sprintf("/Size %d ", count); !bytes.Contains(data, []byte(trailer)) {
+		t.Errorf("trailer does not declare %s", trailer)
+	}
+
+	pages := bytes.Count(data, []byte("/Type /Page /Parent"))
+	if tree := fmt.Sprintf("/Count %d >>", pages); !bytes.Contains(data, []byte(tree)) {
+		t.Errorf("page tree does not count %d pages", pages)
+	}
+
+	// Stream lengths must match their contents
+	streams := regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)endstream`)
+	for _, match := range streams.FindAllSubmatch(data, -1) {
+		length, _ := strconv.Atoi(string(match[1]))
+		if length != len(match[2]) {
+			t.Errorf("stream declares /Length %d but holds %d bytes", length, len(match[2]))
+		}
+	}
+	return pages
+}
+
+func TestPDFWriterStructure(t *testing.T) {
+	for _, pages := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("%d pages", pages), func(t *testing.T) {
+			p := newPDFWriter("Structure (test)")
+			for i := 0; i < pages; i++ {
+				p.addPage()
+				p.text(marginLeft, 700, fontRegular, textSize, fmt.Sprintf("page %d", i+1))
+				p.line(marginLeft, 690, pageWidth-marginRight, 690)
+			}
+
+			var out bytes.Buffer
+			if err := p.writeTo(&out); err != nil {
+				t.Fatalf("writeTo() error = %v", err)
+			}
+			want := max(pages, 1)
+			if got := checkPDF(t, out.Bytes()); got != want {
+				t.Errorf("PDF has %d pages, want %d", got, want)
+			}
+			if !bytes.Contains(out.Bytes(), []byte(`/Title (Structure \(test\))`)) {
+				t.Error("document title is not escaped in the info dictionary")
+			}
+		})
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Invoice INV-000042", "Invoice INV-000042"},
+		{"parentheses", "Widget (blue)", `Widget \(blue\)`},
+		{"unbalanced parenthesis", "a) b(", `a\) b\(`},
+		{"backslash", `C:\path\`, `C:\\path\\`},
+		{"latin-1", "Café Müller", `Caf\351 M\374ller`},
+		{"non-breaking space", "1\u00a0000", `1\240000`},
+		{"euro and dashes", "€ – —", `\200 \226 \227`},
+		{"curly quotes", "‘a’ “b”", `\221a\222 \223b\224`},
+		{"control characters", "a\tb\nc", `a?b?c`},
+		{"not in WinAnsiEncoding", "東京 ✓", "?? ?"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pdfString(tt.in); got != tt.want {
+				t.Errorf("pdfString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWinAnsi(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want byte
+	}{
+		{' ', 32},
+		{'~', 126},
+		{'\u00a0', 0xA0},
+		{'ÿ', 0xFF},
+		{'€', 0x80},
+		{'•', 0x95},
+		{'\u007f', '?'},
+		{'\u0100', '?'},
+	}
+
+	for _, tt := range tests {
+		if got := winAnsi(tt.r); got != tt.want {
+			t.Errorf("winAnsi(%U) = %#x, want %#x", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if got := textWidth("", fontRegular, textSize); got != 0 {
+		t.Errorf("textWidth of nothing = %v, want 0", got)
+	}
+	// Helvetica's "i" is narrower than its "W"; bold is never narrower
+	if textWidth("iiii", fontRegular, textSize) >= textWidth("WWWW", fontRegular, textSize) {
+		t.Error("i is not narrower than W")
+	}
+	if textWidth("Total", fontBold, textSize) < textWidth("Total", fontRegular, textSize) {
+		t.Error("bold text is narrower than regular text")
+	}
+	// Widths scale with the font size; 10 spaces at 10pt are 27.8pt wide
+	if got := textWidth("          ", fontRegular, 10); got != 27.8 {
+		t.Errorf("textWidth of 10 spaces = %v, want 27.8", got)
+	}
+	if got, want := textWidth("abc", fontRegular, 20), 2*textWidth("abc", fontRegular, 10); got != want {
+		t.Errorf("textWidth at 20pt = %v, want %v", got, want)
+	}
+}
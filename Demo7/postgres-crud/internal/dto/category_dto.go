@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// CreateCategoryRequest represents the request body for creating a category
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+	ParentID    *uint  `json:"parent_id"`
+}
+
+// UpdateCategoryRequest represents the request body for updating a category.
+// A null or omitted parent_id moves the category to the root.
+type UpdateCategoryRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+	ParentID    *uint  `json:"parent_id"`
+}
+
+// CategoryResponse represents a category, with its subcategories, in API
+// responses
+type CategoryResponse struct {
+	ID          uint               `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	ParentID    *uint              `json:"parent_id"`
+	Children    []CategoryResponse `json:"children"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// ListCategoriesResponse represents the category tree. Count is the total
+// number of categories at all levels.
+type ListCategoriesResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+	Count      int                `json:"count"`
+}
+
+// TagRequest represents the request body for creating or renaming a tag
+type TagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+// TagResponse represents a tag in API responses
+type TagResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListTagsResponse represents the response for listing tags
+type ListTagsResponse struct {
+	Tags  []TagResponse `json:"tags"`
+	Count int           `json:"count"`
+}
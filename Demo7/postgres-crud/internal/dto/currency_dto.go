@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"postgres-crud/money"
+	"time"
+)
+
+// SetListPriceRequest represents the request body for setting a product's
+// price in a currency
+type SetListPriceRequest struct {
+	Price money.Money `json:"price" binding:"required,min=0"`
+}
+
+// PriceListEntryResponse represents a product's price in one currency
+type PriceListEntryResponse struct {
+	ProductID uint        `json:"product_id"`
+	Currency  string      `json:"currency"`
+	Price     money.Money `json:"price"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// PriceListResponse represents the per-currency prices of a product
+type PriceListResponse struct {
+	ProductID    uint                     `json:"product_id"`
+	BaseCurrency string                   `json:"base_currency"`
+	BasePrice    money.Money              `json:"base_price"`
+	Prices       []PriceListEntryResponse `json:"prices"`
+	Count        int                      `json:"count"`
+}
+
+// SetExchangeRateRequest represents the request body for setting an exchange rate
+type SetExchangeRateRequest struct {
+	Rate money.Rate `json:"rate" binding:"required,gt=0"`
+}
+
+// ExchangeRateResponse represents an exchange rate in API responses
+type ExchangeRateResponse struct {
+	BaseCurrency  string     `json:"base_currency"`
+	QuoteCurrency string     `json:"quote_currency"`
+	Rate          money.Rate `json:"rate"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ListExchangeRatesResponse represents the response for listing exchange rates
+type ListExchangeRatesResponse struct {
+	Rates []ExchangeRateResponse `json:"rates"`
+	Count int                    `json:"count"`
+}
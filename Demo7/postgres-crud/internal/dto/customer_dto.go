@@ -0,0 +1,81 @@
+package dto
+
+import "time"
+
+// CreateCustomerRequest represents the request body for creating a customer
+type CreateCustomerRequest struct {
+	Name  string `json:"name" binding:"required,min=1,max=255"`
+	Email string `json:"email" binding:"required,email,max=255"`
+	Phone string `json:"phone" binding:"max=50"`
+}
+
+// UpdateCustomerRequest represents the request body for updating a customer
+type UpdateCustomerRequest struct {
+	Name  string `json:"name" binding:"required,min=1,max=255"`
+	Email string `json:"email" binding:"required,email,max=255"`
+	Phone string `json:"phone" binding:"max=50"`
+}
+
+// CustomerResponse represents the customer data in API responses
+type CustomerResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListCustomersResponse represents the response for listing customers
+type ListCustomersResponse struct {
+	Customers []CustomerResponse `json:"customers"`
+	Count     int                `json:"count"`
+}
+
+// AddressRequest represents a postal address in request bodies. The postal
+// code is checked against the format of the country.
+type AddressRequest struct {
+	Label      string `json:"label" binding:"max=50"`
+	Name       string `json:"name" binding:"required,max=255"`
+	Line1      string `json:"line1" binding:"required,max=255"`
+	Line2      string `json:"line2" binding:"max=255"`
+	City       string `json:"city" binding:"required,max=100"`
+	Region     string `json:"region" binding:"max=100"`
+	PostalCode string `json:"postal_code" binding:"max=20,postal_code=Country"`
+	Country    string `json:"country" binding:"required,iso3166_1_alpha2"`
+}
+
+// PostalAddress represents the lines of a postal address in API responses
+type PostalAddress struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+}
+
+// AddressResponse represents a saved customer address in API responses
+type AddressResponse struct {
+	ID         uint      `json:"id"`
+	CustomerID uint      `json:"customer_id"`
+	Label      string    `json:"label"`
+	Name       string    `json:"name"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2,omitempty"`
+	City       string    `json:"city"`
+	Region     string    `json:"region,omitempty"`
+	PostalCode string    `json:"postal_code,omitempty"`
+	Country    string    `json:"country"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ListAddressesResponse represents the response for listing the saved
+// addresses of a customer
+type ListAddressesResponse struct {
+	Addresses []AddressResponse `json:"addresses"`
+	Count     int               `json:"count"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// InvoiceResponse represents the invoice issued for an order in API responses
+type InvoiceResponse struct {
+	ID       uint      `json:"id"`
+	OrderID  uint      `json:"order_id"`
+	Number   string    `json:"number"`
+	IssuedAt time.Time `json:"issued_at"`
+}
@@ -1,24 +1,51 @@
 package dto
 
-import "time"
+import (
+	"postgres-crud/money"
+	"time"
+)
 
-// CreateOrderRequest represents the request body for creating an order
+// CreateOrderRequest represents the request body for creating an order for an
+// existing customer. The shipping and billing addresses are each given either
+// as the ID of one of the customer's saved addresses or inline; without a
+// billing address the shipping address is used. Items are added to the order
+// in the same transaction; if any of them fails, no order is created.
 type CreateOrderRequest struct {
-	Description string `json:"description" binding:"required,min=3,max=255"`
+	CustomerID        uint                  `json:"customer_id" binding:"required"`
+	Description       string                `json:"description" binding:"required,min=3,max=255"`
+	Currency          string                `json:"currency" binding:"omitempty,iso4217"`
+	ShippingAddressID *uint                 `json:"shipping_address_id" binding:"excluded_with=ShippingAddress"`
+	ShippingAddress   *AddressRequest       `json:"shipping_address"`
+	BillingAddressID  *uint                 `json:"billing_address_id" binding:"excluded_with=BillingAddress"`
+	BillingAddress    *AddressRequest       `json:"billing_address"`
+	Items             []AddOrderItemRequest `json:"items" binding:"omitempty,max=100,dive"`
 }
 
 // UpdateOrderRequest represents the request body for updating an order
 type UpdateOrderRequest struct {
-	Description string `json:"description" binding:"required,min=3,max=255"`
+	Description     string   `json:"description" binding:"required,min=3,max=255"`
+	DiscountPercent *float64 `json:"discount_percent" binding:"omitempty,min=0,max=100"`
 }
 
-// OrderResponse represents the order data in API responses
+// OrderResponse represents the order data in API responses. DeletedAt is only
+// set for orders in the trash, and Cancellation only for cancelled orders.
 type OrderResponse struct {
-	ID          uint                `json:"id"`
-	Description string             `json:"description"`
-	Products    []ProductResponse  `json:"products,omitempty"`
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
+	ID           uint                       `json:"id"`
+	CustomerID   *uint                      `json:"customer_id"`
+	Description  string                     `json:"description"`
+	Status       string                     `json:"status"`
+	Currency     string                     `json:"currency"`
+	Shipping     *PostalAddress             `json:"shipping_address,omitempty"`
+	Billing      *PostalAddress             `json:"billing_address,omitempty"`
+	Products     []ProductResponse          `json:"products,omitempty"`
+	Items        []OrderItemResponse        `json:"items,omitempty"`
+	Promotions   []OrderPromotionResponse   `json:"promotions,omitempty"`
+	Totals       *OrderTotalsResponse       `json:"totals,omitempty"`
+	Cancellation *OrderCancellationResponse `json:"cancellation,omitempty"`
+	Version      uint                       `json:"version"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	UpdatedAt    time.Time                  `json:"updated_at"`
+	DeletedAt    *time.Time                 `json:"deleted_at,omitempty"`
 }
 
 // ListOrdersResponse represents the response for listing orders
@@ -27,11 +54,116 @@ type ListOrdersResponse struct {
 	Count  int             `json:"count"`
 }
 
-// ErrorResponse represents an error response
+// OrderTotalsResponse represents the calculated totals of an order. Totals are
+// persisted when the order is placed; for draft orders they are an estimate.
+// DiscountTotal includes PromotionDiscount; TaxRate is the effective rate over
+// all lines, which are taxed individually as listed in TaxLines.
+type OrderTotalsResponse struct {
+	Subtotal          money.Money            `json:"subtotal"`
+	DiscountPercent   float64                `json:"discount_percent"`
+	PromotionDiscount money.Money            `json:"promotion_discount"`
+	DiscountTotal     money.Money            `json:"discount_total"`
+	TaxRate           float64                `json:"tax_rate"`
+	TaxTotal          money.Money            `json:"tax_total"`
+	GrandTotal        money.Money            `json:"grand_total"`
+	TaxLines          []OrderTaxLineResponse `json:"tax_lines,omitempty"`
+	Currency          string                 `json:"currency"`
+	Estimated         bool                   `json:"estimated"`
+	PricedAt          *time.Time             `json:"priced_at,omitempty"`
+}
+
+// OrderItemResponse represents a single order line in API responses. SKU,
+// ProductName and ProductDescription are as they were when the line was added;
+// Product is the current catalog record and only included on request.
+type OrderItemResponse struct {
+	OrderID            uint             `json:"order_id"`
+	ProductID          uint             `json:"product_id"`
+	VariantID          *uint            `json:"variant_id,omitempty"`
+	SKU                string           `json:"sku,omitempty"`
+	ProductName        string           `json:"product_name"`
+	ProductDescription string           `json:"product_description"`
+	Product            *ProductResponse `json:"product,omitempty"`
+	Quantity           int              `json:"quantity"`
+	UnitPrice          money.Money      `json:"unit_price"`
+	Currency           string           `json:"currency"`
+	ExchangeRate       money.Rate       `json:"exchange_rate"`
+	DiscountPercent    float64          `json:"discount_percent"`
+	LineTotal          money.Money      `json:"line_total"`
+	CreatedAt          time.Time        `json:"created_at"`
+}
+
+// ListOrderItemsResponse represents the response for listing order items
+type ListOrderItemsResponse struct {
+	Items []OrderItemResponse `json:"items"`
+	Count int                 `json:"count"`
+}
+
+// AddOrderItemRequest represents the request body for adding a line item to an
+// order. Products with variants are added by variant_id; product_id may then be
+// omitted.
+type AddOrderItemRequest struct {
+	ProductID uint  `json:"product_id" binding:"required_without=VariantID"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int   `json:"quantity" binding:"required,min=1"`
+}
+
+// UpdateOrderItemRequest represents the request body for changing a line item
+type UpdateOrderItemRequest struct {
+	Quantity        int      `json:"quantity" binding:"required,min=1"`
+	DiscountPercent *float64 `json:"discount_percent" binding:"omitempty,min=0,max=100"`
+}
+
+// CancelOrderRequest represents the request body for cancelling an order
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=customer_request out_of_stock payment_failed fraud_suspected duplicate_order other"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+// OrderCancellationResponse represents why and when an order was cancelled
+type OrderCancellationResponse struct {
+	Reason      string     `json:"reason"`
+	Note        string     `json:"note,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+}
+
+// TransitionOrderRequest represents the request body for changing an order's status
+type TransitionOrderRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft placed paid shipped delivered cancelled"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+// OrderTransitionResponse represents a single order status change in API responses
+type OrderTransitionResponse struct {
+	ID         uint      `json:"id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListOrderTransitionsResponse represents the status history of an order
+type ListOrderTransitionsResponse struct {
+	Transitions []OrderTransitionResponse `json:"transitions"`
+	Count       int                       `json:"count"`
+}
+
+// ErrorResponse represents an error response. Fields maps the JSON path of
+// each invalid request field to a message.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Details string `json:"details,omitempty"`
-	Code    int    `json:"code,omitempty"`
+	Error   string              `json:"error"`
+	Details string              `json:"details,omitempty"`
+	Fields  map[string]string   `json:"fields,omitempty"`
+	Lines   []LineErrorResponse `json:"lines,omitempty"`
+	Code    int                 `json:"code,omitempty"`
+}
+
+// LineErrorResponse describes why one line of a request failed. Index is the
+// position of the line in the request, starting at 0.
+type LineErrorResponse struct {
+	Index     int    `json:"index"`
+	ProductID uint   `json:"product_id,omitempty"`
+	VariantID uint   `json:"variant_id,omitempty"`
+	Error     string `json:"error"`
 }
 
 // SuccessResponse represents a success response
@@ -44,4 +176,3 @@ type HealthResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 }
-
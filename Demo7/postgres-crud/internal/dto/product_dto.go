@@ -1,61 +1,109 @@
 package dto
 
-import "time"
+import (
+	"postgres-crud/money"
+	"time"
+)
 
 // CreateProductRequest represents the request body for creating a product
 type CreateProductRequest struct {
-	Name        string  `json:"name" binding:"required,min=3,max=255"`
-	Description string  `json:"description" binding:"max=1000"`
-	Price       float64 `json:"price" binding:"required,min=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
+	Name        string      `json:"name" binding:"required,min=3,max=255"`
+	Description string      `json:"description" binding:"max=1000"`
+	Price       money.Money `json:"price" binding:"required,min=0"`
+	Currency    string      `json:"currency" binding:"omitempty,iso4217"`
+	Stock       int         `json:"stock" binding:"min=0"`
+	TaxClass    string      `json:"tax_class" binding:"omitempty,oneof=standard reduced exempt"`
+	CategoryID  *uint       `json:"category_id"`
+	Tags        []string    `json:"tags" binding:"omitempty,dive,max=50"`
 }
 
-// UpdateProductRequest represents the request body for updating a product
+// UpdateProductRequest represents the request body for updating a product.
+// Omitted tax_class, category_id and tags are left unchanged; a category_id of
+// 0 removes the category and an empty tags list removes all tags.
 type UpdateProductRequest struct {
-	Name        string  `json:"name" binding:"required,min=3,max=255"`
-	Description string  `json:"description" binding:"max=1000"`
-	Price       float64 `json:"price" binding:"required,min=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
+	Name        string      `json:"name" binding:"required,min=3,max=255"`
+	Description string      `json:"description" binding:"max=1000"`
+	Price       money.Money `json:"price" binding:"required,min=0"`
+	Currency    string      `json:"currency" binding:"omitempty,iso4217"`
+	Stock       int         `json:"stock" binding:"min=0"`
+	TaxClass    string      `json:"tax_class" binding:"omitempty,oneof=standard reduced exempt"`
+	CategoryID  *uint       `json:"category_id"`
+	Tags        []string    `json:"tags" binding:"omitempty,dive,max=50"`
 }
 
-// ProductResponse represents the product data in API responses
+// ProductResponse represents the product data in API responses. DeletedAt is
+// only set for products in the trash, and AsOf only when the price was looked
+// up at an earlier time. While a scheduled price is in effect, Price is the
+// scheduled price and RegularPrice the price it replaces.
 type ProductResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           uint         `json:"id"`
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Price        money.Money  `json:"price"`
+	Currency     string       `json:"currency"`
+	ExchangeRate *money.Rate  `json:"exchange_rate,omitempty"`
+	RegularPrice *money.Money `json:"regular_price,omitempty"`
+	PriceEndsAt  *time.Time   `json:"price_ends_at,omitempty"`
+	ScheduleID   *uint        `json:"scheduled_price_id,omitempty"`
+	Stock        int          `json:"stock"`
+	TaxClass     string       `json:"tax_class"`
+	CategoryID   *uint        `json:"category_id"`
+	Tags         []string     `json:"tags"`
+	Version      uint         `json:"version"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	DeletedAt    *time.Time   `json:"deleted_at,omitempty"`
+	AsOf         *time.Time   `json:"as_of,omitempty"`
+}
+
+// ProductPriceResponse represents one entry of a product's price history.
+// EffectiveTo is unset for the current price.
+type ProductPriceResponse struct {
+	Price         money.Money `json:"price"`
+	Currency      string      `json:"currency"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+	EffectiveTo   *time.Time  `json:"effective_to"`
+}
+
+// ProductPriceHistoryResponse represents the price history of a product,
+// oldest first
+type ProductPriceHistoryResponse struct {
+	ProductID uint                   `json:"product_id"`
+	Prices    []ProductPriceResponse `json:"prices"`
+	Count     int                    `json:"count"`
 }
 
 // ListProductsResponse represents the response for listing products
 type ListProductsResponse struct {
 	Products []ProductResponse `json:"products"`
-	Count    int              `json:"count"`
+	Count    int               `json:"count"`
 }
 
-// AddProductToOrderRequest represents the request to add a product to an order
+// AddProductToOrderRequest represents the request to add a product to an
+// order. Products with variants are added by variant_id; product_id may then
+// be omitted.
 type AddProductToOrderRequest struct {
-	ProductID uint `json:"product_id" binding:"required"`
-	Quantity  int  `json:"quantity" binding:"required,min=1"`
+	ProductID uint  `json:"product_id" binding:"required_without=VariantID"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  int   `json:"quantity" binding:"required,min=1"`
 }
 
 // FilterProductsRequest represents the request for filtering products
 type FilterProductsRequest struct {
-	Name        string   `form:"name"`
-	Description string   `form:"description"`
-	MinPrice    *float64 `form:"min_price"`
-	MaxPrice    *float64 `form:"max_price"`
-	MinStock    *int     `form:"min_stock"`
-	MaxStock    *int     `form:"max_stock"`
+	Name        string       `form:"name"`
+	Description string       `form:"description"`
+	MinPrice    *money.Money `form:"min_price"`
+	MaxPrice    *money.Money `form:"max_price"`
+	MinStock    *int         `form:"min_stock"`
+	MaxStock    *int         `form:"max_stock"`
+	CategoryID  *uint        `form:"category"`
+	Tags        []string     `form:"tag"`
 }
 
 // FilterOrdersRequest represents the request for filtering orders
 type FilterOrdersRequest struct {
-	Description string `form:"description"`
-	ProductID   *uint  `form:"product_id"`
-	WithProducts bool  `form:"with_products"`
+	Description  string `form:"description"`
+	ProductID    *uint  `form:"product_id"`
+	CustomerID   *uint  `form:"customer_id"`
+	WithProducts bool   `form:"with_products"`
 }
-
-
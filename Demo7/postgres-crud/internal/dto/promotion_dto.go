@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"postgres-crud/money"
+	"time"
+)
+
+// CreatePromotionRequest represents the request body for creating a promotion.
+// Promotions with a code are coupons; without one they apply automatically.
+// An omitted active defaults to true.
+type CreatePromotionRequest struct {
+	Name                  string      `json:"name" binding:"required,max=255"`
+	Code                  string      `json:"code" binding:"max=50"`
+	Type                  string      `json:"type" binding:"required,oneof=percentage fixed_amount buy_x_get_y"`
+	PercentOff            float64     `json:"percent_off" binding:"min=0,max=100"`
+	AmountOff             money.Money `json:"amount_off" binding:"min=0"`
+	MinOrderValue         money.Money `json:"min_order_value" binding:"min=0"`
+	Currency              string      `json:"currency" binding:"omitempty,iso4217"`
+	ProductID             *uint       `json:"product_id"`
+	BuyQuantity           int         `json:"buy_quantity" binding:"min=0"`
+	GetQuantity           int         `json:"get_quantity" binding:"min=0"`
+	UsageLimitPerCustomer int         `json:"usage_limit_per_customer" binding:"min=0"`
+	StartsAt              *time.Time  `json:"starts_at"`
+	EndsAt                *time.Time  `json:"ends_at"`
+	Stackable             bool        `json:"stackable"`
+	Priority              int         `json:"priority"`
+	Active                *bool       `json:"active"`
+}
+
+// UpdatePromotionRequest represents the request body for updating a promotion.
+// All settings are replaced; an omitted active defaults to true.
+type UpdatePromotionRequest struct {
+	Name                  string      `json:"name" binding:"required,max=255"`
+	Code                  string      `json:"code" binding:"max=50"`
+	Type                  string      `json:"type" binding:"required,oneof=percentage fixed_amount buy_x_get_y"`
+	PercentOff            float64     `json:"percent_off" binding:"min=0,max=100"`
+	AmountOff             money.Money `json:"amount_off" binding:"min=0"`
+	MinOrderValue         money.Money `json:"min_order_value" binding:"min=0"`
+	Currency              string      `json:"currency" binding:"omitempty,iso4217"`
+	ProductID             *uint       `json:"product_id"`
+	BuyQuantity           int         `json:"buy_quantity" binding:"min=0"`
+	GetQuantity           int         `json:"get_quantity" binding:"min=0"`
+	UsageLimitPerCustomer int         `json:"usage_limit_per_customer" binding:"min=0"`
+	StartsAt              *time.Time  `json:"starts_at"`
+	EndsAt                *time.Time  `json:"ends_at"`
+	Stackable             bool        `json:"stackable"`
+	Priority              int         `json:"priority"`
+	Active                *bool       `json:"active"`
+}
+
+// PromotionResponse represents a promotion in API responses
+type PromotionResponse struct {
+	ID                    uint        `json:"id"`
+	Name                  string      `json:"name"`
+	Code                  string      `json:"code,omitempty"`
+	Type                  string      `json:"type"`
+	PercentOff            float64     `json:"percent_off,omitempty"`
+	AmountOff             money.Money `json:"amount_off"`
+	MinOrderValue         money.Money `json:"min_order_value"`
+	Currency              string      `json:"currency"`
+	ProductID             *uint       `json:"product_id,omitempty"`
+	BuyQuantity           int         `json:"buy_quantity,omitempty"`
+	GetQuantity           int         `json:"get_quantity,omitempty"`
+	UsageLimitPerCustomer int         `json:"usage_limit_per_customer"`
+	StartsAt              *time.Time  `json:"starts_at"`
+	EndsAt                *time.Time  `json:"ends_at"`
+	Stackable             bool        `json:"stackable"`
+	Priority              int         `json:"priority"`
+	Active                bool        `json:"active"`
+	Version               uint        `json:"version"`
+	CreatedAt             time.Time   `json:"created_at"`
+	UpdatedAt             time.Time   `json:"updated_at"`
+}
+
+// ListPromotionsResponse represents the response for listing promotions
+type ListPromotionsResponse struct {
+	Promotions []PromotionResponse `json:"promotions"`
+	Count      int                 `json:"count"`
+}
+
+// ApplyCouponRequest represents the request body for applying a coupon to an
+// order
+type ApplyCouponRequest struct {
+	Code string `json:"code" binding:"required,max=50"`
+}
+
+// OrderPromotionResponse represents a promotion on an order in API responses.
+// Coupons that currently give no discount have applied set to false and a
+// reason.
+type OrderPromotionResponse struct {
+	PromotionID uint        `json:"promotion_id"`
+	Code        string      `json:"code,omitempty"`
+	Name        string      `json:"name"`
+	Applied     bool        `json:"applied"`
+	Reason      string      `json:"reason,omitempty"`
+	Discount    money.Money `json:"discount"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"postgres-crud/money"
+	"time"
+)
+
+// CreateReturnRequest represents the request body for returning items of a
+// delivered order. Each item names an order line and the quantity sent back.
+type CreateReturnRequest struct {
+	Reason string                `json:"reason" binding:"required,max=255"`
+	Note   string                `json:"note" binding:"max=1000"`
+	Items  []AddOrderItemRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// ReceiveReturnRequest represents the request body for receiving a return.
+// Restock puts the returned units back into stock.
+type ReceiveReturnRequest struct {
+	Restock bool `json:"restock"`
+}
+
+// ReturnLineResponse represents a returned order line in API responses
+type ReturnLineResponse struct {
+	ProductID uint        `json:"product_id"`
+	VariantID *uint       `json:"variant_id,omitempty"`
+	SKU       string      `json:"sku,omitempty"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice money.Money `json:"unit_price"`
+	Amount    money.Money `json:"amount"`
+}
+
+// ReturnResponse represents a return of an order in API responses
+type ReturnResponse struct {
+	ID           uint                 `json:"id"`
+	OrderID      uint                 `json:"order_id"`
+	Status       string               `json:"status"`
+	Reason       string               `json:"reason"`
+	Note         string               `json:"note,omitempty"`
+	Lines        []ReturnLineResponse `json:"lines"`
+	RefundAmount money.Money          `json:"refund_amount"`
+	Currency     string               `json:"currency"`
+	Restocked    bool                 `json:"restocked"`
+	Version      uint                 `json:"version"`
+	CreatedAt    time.Time            `json:"created_at"`
+	ApprovedAt   *time.Time           `json:"approved_at,omitempty"`
+	ReceivedAt   *time.Time           `json:"received_at,omitempty"`
+	RefundedAt   *time.Time           `json:"refunded_at,omitempty"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+// ListReturnsResponse represents the returns of an order, oldest first
+type ListReturnsResponse struct {
+	OrderID uint             `json:"order_id"`
+	Returns []ReturnResponse `json:"returns"`
+	Count   int              `json:"count"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"postgres-crud/money"
+	"time"
+)
+
+// ScheduledPriceRequest represents the request body for scheduling a product
+// price. The currency defaults to the product's; an omitted ends_at keeps the
+// price indefinitely.
+type ScheduledPriceRequest struct {
+	Price    money.Money `json:"price" binding:"required,min=0"`
+	Currency string      `json:"currency" binding:"omitempty,iso4217"`
+	StartsAt time.Time   `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time  `json:"ends_at"`
+	Note     string      `json:"note" binding:"max=255"`
+}
+
+// ScheduledPriceResponse represents a scheduled product price in API
+// responses. Active reports whether it is in effect at the time of the request.
+type ScheduledPriceResponse struct {
+	ID        uint        `json:"id"`
+	ProductID uint        `json:"product_id"`
+	Price     money.Money `json:"price"`
+	Currency  string      `json:"currency"`
+	StartsAt  time.Time   `json:"starts_at"`
+	EndsAt    *time.Time  `json:"ends_at"`
+	Note      string      `json:"note"`
+	Active    bool        `json:"active"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// ListScheduledPricesResponse represents the scheduled prices of a product,
+// earliest first
+type ListScheduledPricesResponse struct {
+	ProductID uint                     `json:"product_id"`
+	Schedules []ScheduledPriceResponse `json:"schedules"`
+	Count     int                      `json:"count"`
+}
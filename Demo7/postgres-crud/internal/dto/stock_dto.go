@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// StockAdjustmentRequest represents the request body for a manual stock adjustment
+type StockAdjustmentRequest struct {
+	Delta      int    `json:"delta" binding:"required"`
+	ReasonCode string `json:"reason_code" binding:"required,oneof=restock damaged lost found correction"`
+	Note       string `json:"note" binding:"max=1000"`
+}
+
+// StockMovementResponse represents a stock ledger entry in API responses
+type StockMovementResponse struct {
+	ID            uint      `json:"id"`
+	ProductID     uint      `json:"product_id"`
+	VariantID     *uint     `json:"variant_id,omitempty"`
+	Delta         int       `json:"delta"`
+	Reason        string    `json:"reason"`
+	ReferenceType string    `json:"reference_type"`
+	ReferenceID   *uint     `json:"reference_id,omitempty"`
+	Actor         string    `json:"actor"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockHistoryResponse represents the stock ledger of a product or variant
+type StockHistoryResponse struct {
+	ProductID     uint                    `json:"product_id"`
+	VariantID     *uint                   `json:"variant_id,omitempty"`
+	Stock         int                     `json:"stock"`
+	LedgerBalance int                     `json:"ledger_balance"`
+	InSync        bool                    `json:"in_sync"`
+	Movements     []StockMovementResponse `json:"movements"`
+	Count         int                     `json:"count"`
+}
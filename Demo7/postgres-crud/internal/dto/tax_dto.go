@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"postgres-crud/money"
+	"time"
+)
+
+// TaxRateRequest represents the request body for creating or updating a tax
+// rate. An empty region applies to the whole country; rate is a percentage.
+type TaxRateRequest struct {
+	Country       string     `json:"country" binding:"required,iso3166_1_alpha2"`
+	Region        string     `json:"region" binding:"max=100"`
+	TaxClass      string     `json:"tax_class" binding:"required,oneof=standard reduced exempt"`
+	Rate          *float64   `json:"rate" binding:"required,min=0,max=100"`
+	EffectiveFrom time.Time  `json:"effective_from" binding:"required"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+// TaxRateResponse represents a tax rate in API responses
+type TaxRateResponse struct {
+	ID            uint       `json:"id"`
+	Country       string     `json:"country"`
+	Region        string     `json:"region"`
+	TaxClass      string     `json:"tax_class"`
+	Rate          float64    `json:"rate"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ListTaxRatesResponse represents the response for listing tax rates
+type ListTaxRatesResponse struct {
+	Rates []TaxRateResponse `json:"rates"`
+	Count int               `json:"count"`
+}
+
+// OrderTaxLineResponse represents the tax charged on one order line
+type OrderTaxLineResponse struct {
+	ProductID     uint        `json:"product_id"`
+	VariantID     *uint       `json:"variant_id,omitempty"`
+	TaxClass      string      `json:"tax_class"`
+	Country       string      `json:"country,omitempty"`
+	Region        string      `json:"region,omitempty"`
+	TaxableAmount money.Money `json:"taxable_amount"`
+	Rate          float64     `json:"rate"`
+	Tax           money.Money `json:"tax"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"time"
+)
+
+// CreateVariantRequest represents the request body for creating a product
+// variant. Without a price the product's price applies.
+type CreateVariantRequest struct {
+	SKU      string               `json:"sku" binding:"required,max=64"`
+	Options  model.VariantOptions `json:"options" binding:"omitempty,dive,keys,required,max=50,endkeys,required,max=100"`
+	Price    *money.Money         `json:"price" binding:"omitempty,min=0"`
+	Currency string               `json:"currency" binding:"omitempty,iso4217"`
+	Stock    int                  `json:"stock" binding:"min=0"`
+}
+
+// UpdateVariantRequest represents the request body for updating a product
+// variant. A null or omitted price removes the price override.
+type UpdateVariantRequest struct {
+	SKU      string               `json:"sku" binding:"required,max=64"`
+	Options  model.VariantOptions `json:"options" binding:"omitempty,dive,keys,required,max=50,endkeys,required,max=100"`
+	Price    *money.Money         `json:"price" binding:"omitempty,min=0"`
+	Currency string               `json:"currency" binding:"omitempty,iso4217"`
+	Stock    int                  `json:"stock" binding:"min=0"`
+}
+
+// VariantResponse represents a product variant in API responses. Price is null
+// when the variant uses its product's price. Product is only included for SKU
+// lookups.
+type VariantResponse struct {
+	ID        uint                 `json:"id"`
+	ProductID uint                 `json:"product_id"`
+	SKU       string               `json:"sku"`
+	Options   model.VariantOptions `json:"options"`
+	Price     *money.Money         `json:"price"`
+	Currency  string               `json:"currency"`
+	Stock     int                  `json:"stock"`
+	Version   uint                 `json:"version"`
+	Product   *ProductResponse     `json:"product,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// ListVariantsResponse represents the response for listing product variants
+type ListVariantsResponse struct {
+	Variants []VariantResponse `json:"variants"`
+	Count    int               `json:"count"`
+}
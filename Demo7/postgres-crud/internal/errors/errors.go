@@ -2,7 +2,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 )
 
 // APIError represents an API error with HTTP status code
@@ -18,10 +20,12 @@ func (e *APIError) Error() string {
 
 // Predefined API errors
 var (
-	ErrNotFound     = &APIError{Code: http.StatusNotFound, Message: "Resource not found"}
-	ErrBadRequest   = &APIError{Code: http.StatusBadRequest, Message: "Invalid request"}
-	ErrUnauthorized = &APIError{Code: http.StatusUnauthorized, Message: "Unauthorized"}
-	ErrInternal     = &APIError{Code: http.StatusInternalServerError, Message: "Internal server error"}
+	ErrNotFound           = &APIError{Code: http.StatusNotFound, Message: "Resource not found"}
+	ErrBadRequest         = &APIError{Code: http.StatusBadRequest, Message: "Invalid request"}
+	ErrUnauthorized       = &APIError{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	ErrConflict           = &APIError{Code: http.StatusConflict, Message: "Conflict"}
+	ErrInternal           = &APIError{Code: http.StatusInternalServerError, Message: "Internal server error"}
+	ErrPreconditionFailed = &APIError{Code: http.StatusPreconditionFailed, Message: "Precondition failed"}
 )
 
 // NewAPIError creates a new API error
@@ -50,3 +54,53 @@ func IsBadRequest(err error) bool {
 	return false
 }
 
+// IsConflict checks if error is a conflict error
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusConflict
+	}
+	return false
+}
+
+// IsPreconditionFailed checks if error is a precondition failed error
+func IsPreconditionFailed(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+// LineError describes why one line of a request with several lines failed.
+// Index is the position of the line in the request, starting at 0.
+type LineError struct {
+	Index     int
+	ProductID uint
+	VariantID uint
+	Message   string
+}
+
+// LineErrors reports every line of a request that failed. Nothing from the
+// request is written when it is returned.
+type LineErrors struct {
+	Message string
+	Lines   []LineError
+}
+
+func (e *LineErrors) Error() string {
+	reasons := make([]string, len(e.Lines))
+	for i, line := range e.Lines {
+		reasons[i] = fmt.Sprintf("line %d: %s", line.Index, line.Message)
+	}
+	return e.Message + ": " + strings.Join(reasons, "; ")
+}
+
+// AsLineErrors returns the per-line errors carried by err, if any
+func AsLineErrors(err error) (*LineErrors, bool) {
+	var lineErrs *LineErrors
+	if errors.As(err, &lineErrs) {
+		return lineErrs, true
+	}
+	return nil, false
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryHandler handles HTTP requests for categories
+type CategoryHandler struct {
+	categoryService service.CategoryService
+}
+
+// NewCategoryHandler creates a new instance of CategoryHandler
+func NewCategoryHandler(categoryService service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// toCategoryResponse converts a category and its loaded children into its API
+// representation
+func toCategoryResponse(category model.Category) dto.CategoryResponse {
+	children := make([]dto.CategoryResponse, len(category.Children))
+	for i, child := range category.Children {
+		children[i] = toCategoryResponse(child)
+	}
+
+	return dto.CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+		ParentID:    category.ParentID,
+		Children:    children,
+		CreatedAt:   category.CreatedAt,
+		UpdatedAt:   category.UpdatedAt,
+	}
+}
+
+// toCategoryTree arranges a flat list of categories into trees below their
+// root categories
+func toCategoryTree(categories []model.Category) []dto.CategoryResponse {
+	byParent := make(map[uint][]model.Category)
+	var roots []model.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		byParent[*category.ParentID] = append(byParent[*category.ParentID], category)
+	}
+
+	var build func(category model.Category) dto.CategoryResponse
+	build = func(category model.Category) dto.CategoryResponse {
+		response := toCategoryResponse(category)
+		children := byParent[category.ID]
+		response.Children = make([]dto.CategoryResponse, len(children))
+		for i, child := range children {
+			response.Children[i] = build(child)
+		}
+		return response
+	}
+
+	tree := make([]dto.CategoryResponse, len(roots))
+	for i, root := range roots {
+		tree[i] = build(root)
+	}
+	return tree
+}
+
+// CreateCategory handles POST /api/v1/categories
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req dto.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(req.Name, req.Description, req.ParentID)
+	if err != nil {
+		if errors.IsBadRequest(err) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Failed to create category",
+				Details: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to create category",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toCategoryResponse(*category))
+}
+
+// ListCategories handles GET /api/v1/categories
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	categories, err := h.categoryService.GetAllCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch categories",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ListCategoriesResponse{
+		Categories: toCategoryTree(categories),
+		Count:      len(categories),
+	})
+}
+
+// GetCategory handles GET /api/v1/categories/:id
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid category ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	category, err := h.categoryService.GetCategoryByID(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Category not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch category",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toCategoryResponse(*category))
+}
+
+// UpdateCategory handles PUT /api/v1/categories/:id
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid category ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(uint(id), req.Name, req.Description, req.ParentID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Category not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsBadRequest(err) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Failed to update category",
+				Details: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to update category",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toCategoryResponse(*category))
+}
+
+// DeleteCategory handles DELETE /api/v1/categories/:id
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid category ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(uint(id)); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Category not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to delete category",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete category",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Category deleted successfully",
+	})
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/internal/validation"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrencyHandler handles HTTP requests for price lists and exchange rates
+type CurrencyHandler struct {
+	currencyService service.CurrencyService
+}
+
+// NewCurrencyHandler creates a new instance of CurrencyHandler
+func NewCurrencyHandler(currencyService service.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{
+		currencyService: currencyService,
+	}
+}
+
+// requestedCurrency returns the currency asked for with the ?currency= query
+// parameter or, failing that, the Accept-Currency header. An empty string means
+// no currency was requested. Unknown codes are answered with 400 and ok is false.
+func requestedCurrency(c *gin.Context) (string, bool) {
+	currency := c.Query("currency")
+	if currency == "" {
+		currency = c.GetHeader("Accept-Currency")
+	}
+	if currency == "" {
+		return "", true
+	}
+	return currencyParam(c, currency)
+}
+
+// currencyParam normalises a currency code taken from the request. Unknown codes
+// are answered with 400 and ok is false.
+func currencyParam(c *gin.Context, value string) (string, bool) {
+	currency := strings.ToUpper(strings.TrimSpace(value))
+	if !validation.IsCurrency(currency) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid currency",
+			Details: "currency must be an ISO 4217 code, got " + strconv.Quote(value),
+			Code:    http.StatusBadRequest,
+		})
+		return "", false
+	}
+	return currency, true
+}
+
+// toPriceListEntryResponse converts a price list entry into its API representation
+func toPriceListEntryResponse(entry model.PriceListEntry) dto.PriceListEntryResponse {
+	return dto.PriceListEntryResponse{
+		ProductID: entry.ProductID,
+		Currency:  entry.Currency,
+		Price:     entry.Price,
+		UpdatedAt: entry.UpdatedAt,
+	}
+}
+
+// toExchangeRateResponse converts an exchange rate into its API representation
+func toExchangeRateResponse(rate model.ExchangeRate) dto.ExchangeRateResponse {
+	return dto.ExchangeRateResponse{
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		UpdatedAt:     rate.UpdatedAt,
+	}
+}
+
+// GetPriceList handles GET /api/v1/products/:id/price-list
+func (h *CurrencyHandler) GetPriceList(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	product, entries, err := h.currencyService.GetPriceList(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch price list",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	prices := make([]dto.PriceListEntryResponse, len(entries))
+	for i, entry := range entries {
+		prices[i] = toPriceListEntryResponse(entry)
+	}
+
+	c.JSON(http.StatusOK, dto.PriceListResponse{
+		ProductID:    product.ID,
+		BaseCurrency: product.Currency,
+		BasePrice:    product.Price,
+		Prices:       prices,
+		Count:        len(prices),
+	})
+}
+
+// SetListPrice handles PUT /api/v1/products/:id/price-list/:currency
+func (h *CurrencyHandler) SetListPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	currency, ok := currencyParam(c, c.Param("currency"))
+	if !ok {
+		return
+	}
+
+	var req dto.SetListPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	price := req.Price
+	price.Currency = currency
+	entry, err := h.currencyService.SetListPrice(uint(id), price)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to set list price",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toPriceListEntryResponse(*entry))
+}
+
+// DeleteListPrice handles DELETE /api/v1/products/:id/price-list/:currency
+func (h *CurrencyHandler) DeleteListPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	currency, ok := currencyParam(c, c.Param("currency"))
+	if !ok {
+		return
+	}
+
+	if err := h.currencyService.DeleteListPrice(uint(id), currency); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "List price not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete list price",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "List price deleted successfully",
+	})
+}
+
+// ListExchangeRates handles GET /api/v1/exchange-rates
+func (h *CurrencyHandler) ListExchangeRates(c *gin.Context) {
+	rates, err := h.currencyService.GetExchangeRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch exchange rates",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		response[i] = toExchangeRateResponse(rate)
+	}
+
+	c.JSON(http.StatusOK, dto.ListExchangeRatesResponse{
+		Rates: response,
+		Count: len(response),
+	})
+}
+
+// SetExchangeRate handles PUT /api/v1/exchange-rates/:base/:quote
+func (h *CurrencyHandler) SetExchangeRate(c *gin.Context) {
+	base, ok := currencyParam(c, c.Param("base"))
+	if !ok {
+		return
+	}
+	quote, ok := currencyParam(c, c.Param("quote"))
+	if !ok {
+		return
+	}
+
+	var req dto.SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	rate, err := h.currencyService.SetExchangeRate(base, quote, req.Rate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to set exchange rate",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toExchangeRateResponse(*rate))
+}
+
+// DeleteExchangeRate handles DELETE /api/v1/exchange-rates/:base/:quote
+func (h *CurrencyHandler) DeleteExchangeRate(c *gin.Context) {
+	base, ok := currencyParam(c, c.Param("base"))
+	if !ok {
+		return
+	}
+	quote, ok := currencyParam(c, c.Param("quote"))
+	if !ok {
+		return
+	}
+
+	if err := h.currencyService.DeleteExchangeRate(base, quote); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Exchange rate not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete exchange rate",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Exchange rate deleted successfully",
+	})
+}
@@ -0,0 +1,489 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/internal/validation"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CustomerHandler handles HTTP requests for customers
+type CustomerHandler struct {
+	customerService service.CustomerService
+}
+
+// NewCustomerHandler creates a new instance of CustomerHandler
+func NewCustomerHandler(customerService service.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		customerService: customerService,
+	}
+}
+
+// toCustomerResponse converts a customer into its API representation
+func toCustomerResponse(customer model.Customer) dto.CustomerResponse {
+	return dto.CustomerResponse{
+		ID:        customer.ID,
+		Name:      customer.Name,
+		Email:     customer.Email,
+		Phone:     customer.Phone,
+		Version:   customer.Version,
+		CreatedAt: customer.CreatedAt,
+		UpdatedAt: customer.UpdatedAt,
+	}
+}
+
+// toPostalAddress converts an address from a request body into its model
+func toPostalAddress(req dto.AddressRequest) model.PostalAddress {
+	return model.PostalAddress{
+		Name:       req.Name,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		Region:     req.Region,
+		PostalCode: req.PostalCode,
+		Country:    req.Country,
+	}
+}
+
+// toPostalAddressResponse converts an address copied onto an order into its
+// API representation, or nil when none was set
+func toPostalAddressResponse(address model.PostalAddress) *dto.PostalAddress {
+	if address.IsZero() {
+		return nil
+	}
+	return &dto.PostalAddress{
+		Name:       address.Name,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
+
+// toAddressResponse converts a saved address into its API representation
+func toAddressResponse(address model.Address) dto.AddressResponse {
+	return dto.AddressResponse{
+		ID:         address.ID,
+		CustomerID: address.CustomerID,
+		Label:      address.Label,
+		Name:       address.Address.Name,
+		Line1:      address.Address.Line1,
+		Line2:      address.Address.Line2,
+		City:       address.Address.City,
+		Region:     address.Address.Region,
+		PostalCode: address.Address.PostalCode,
+		Country:    address.Address.Country,
+		CreatedAt:  address.CreatedAt,
+		UpdatedAt:  address.UpdatedAt,
+	}
+}
+
+// parseAddressParams parses the customer and address IDs of a saved address
+// route. On failure it writes a 400 response and returns ok=false.
+func parseAddressParams(c *gin.Context) (uint, uint, bool) {
+	customerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid customer ID",
+			Code:  http.StatusBadRequest,
+		})
+		return 0, 0, false
+	}
+
+	addressID, err := strconv.ParseUint(c.Param("addressId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid address ID",
+			Code:  http.StatusBadRequest,
+		})
+		return 0, 0, false
+	}
+
+	return uint(customerID), uint(addressID), true
+}
+
+// CreateCustomer handles POST /api/v1/customers
+func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
+	var req dto.CreateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	customer, err := h.customerService.CreateCustomer(req.Name, req.Email, req.Phone)
+	if err != nil {
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to create customer",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to create customer",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusCreated, toCustomerResponse(*customer))
+}
+
+// ListCustomers handles GET /api/v1/customers
+func (h *CustomerHandler) ListCustomers(c *gin.Context) {
+	customers, err := h.customerService.GetAllCustomers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch customers",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.CustomerResponse, len(customers))
+	for i, customer := range customers {
+		response[i] = toCustomerResponse(customer)
+	}
+
+	c.JSON(http.StatusOK, dto.ListCustomersResponse{
+		Customers: response,
+		Count:     len(response),
+	})
+}
+
+// GetCustomer handles GET /api/v1/customers/:id
+func (h *CustomerHandler) GetCustomer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid customer ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	customer, err := h.customerService.GetCustomerByID(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Customer not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch customer",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusOK, toCustomerResponse(*customer))
+}
+
+// UpdateCustomer handles PUT /api/v1/customers/:id
+func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid customer ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.UpdateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	customer, err := h.customerService.UpdateCustomer(uint(id), req.Name, req.Email, req.Phone, expectedVersion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Customer not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to update customer",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update customer",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to update customer",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusOK, toCustomerResponse(*customer))
+}
+
+// DeleteCustomer handles DELETE /api/v1/customers/:id
+func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid customer ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.customerService.DeleteCustomer(uint(id), expectedVersion); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Customer not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to delete customer",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to delete customer",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete customer",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Customer deleted successfully",
+	})
+}
+
+// ListAddresses handles GET /api/v1/customers/:id/addresses
+func (h *CustomerHandler) ListAddresses(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid customer ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	addresses, err := h.customerService.GetCustomerAddresses(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Customer not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch addresses",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.AddressResponse, len(addresses))
+	for i, address := range addresses {
+		response[i] = toAddressResponse(address)
+	}
+
+	c.JSON(http.StatusOK, dto.ListAddressesResponse{
+		Addresses: response,
+		Count:     len(response),
+	})
+}
+
+// AddAddress handles POST /api/v1/customers/:id/addresses
+func (h *CustomerHandler) AddAddress(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid customer ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	address, err := h.customerService.AddCustomerAddress(uint(id), req.Label, toPostalAddress(req))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Customer not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to add address",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAddressResponse(*address))
+}
+
+// GetAddress handles GET /api/v1/customers/:id/addresses/:addressId
+func (h *CustomerHandler) GetAddress(c *gin.Context) {
+	customerID, addressID, ok := parseAddressParams(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.customerService.GetCustomerAddress(customerID, addressID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Address not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch address",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAddressResponse(*address))
+}
+
+// UpdateAddress handles PUT /api/v1/customers/:id/addresses/:addressId
+func (h *CustomerHandler) UpdateAddress(c *gin.Context) {
+	customerID, addressID, ok := parseAddressParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	address, err := h.customerService.UpdateCustomerAddress(customerID, addressID, req.Label, toPostalAddress(req))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Address not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to update address",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAddressResponse(*address))
+}
+
+// DeleteAddress handles DELETE /api/v1/customers/:id/addresses/:addressId
+func (h *CustomerHandler) DeleteAddress(c *gin.Context) {
+	customerID, addressID, ok := parseAddressParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.customerService.DeleteCustomerAddress(customerID, addressID); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Address not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete address",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Address deleted successfully",
+	})
+}
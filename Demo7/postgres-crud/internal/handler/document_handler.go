@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/document"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocumentHandler handles HTTP requests for order documents
+type DocumentHandler struct {
+	documentService service.DocumentService
+}
+
+// NewDocumentHandler creates a new instance of DocumentHandler
+func NewDocumentHandler(documentService service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		documentService: documentService,
+	}
+}
+
+// documentFormat returns the format asked for with the ?format= query
+// parameter or, failing that, HTML when the Accept header prefers it and PDF
+// otherwise. Unknown formats are answered with 400 and ok is false.
+func documentFormat(c *gin.Context) (document.Format, bool) {
+	value := c.Query("format")
+	if value == "" {
+		if strings.Contains(c.GetHeader("Accept"), "text/html") {
+			return document.FormatHTML, true
+		}
+		return document.FormatPDF, true
+	}
+
+	format, ok := document.ParseFormat(value)
+	if !ok {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid format",
+			Details: "format must be pdf or html, got " + strconv.Quote(value),
+			Code:    http.StatusBadRequest,
+		})
+		return "", false
+	}
+	return format, true
+}
+
+// writeDocument writes a rendered document as the response body
+func writeDocument(c *gin.Context, doc *service.RenderedDocument) {
+	c.Header("Content-Disposition", `inline; filename="`+doc.FileName+`"`)
+	c.Data(http.StatusOK, doc.ContentType, doc.Content)
+}
+
+// toInvoiceResponse converts an invoice into its API representation
+func toInvoiceResponse(invoice model.Invoice) dto.InvoiceResponse {
+	return dto.InvoiceResponse{
+		ID:       invoice.ID,
+		OrderID:  invoice.OrderID,
+		Number:   invoice.Number,
+		IssuedAt: invoice.IssuedAt,
+	}
+}
+
+// IssueInvoice handles POST /api/v1/orders/:id/invoice. A newly issued
+// invoice is answered with 201; an order that was already invoiced with 200
+// and its existing invoice.
+func (h *DocumentHandler) IssueInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	invoice, issued, err := h.documentService.IssueInvoice(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Order cannot be invoiced",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to issue invoice",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if issued {
+		status = http.StatusCreated
+	}
+	c.JSON(status, toInvoiceResponse(*invoice))
+}
+
+// GetInvoice handles GET /api/v1/orders/:id/invoice
+func (h *DocumentHandler) GetInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	format, ok := documentFormat(c)
+	if !ok {
+		return
+	}
+
+	doc, err := h.documentService.GetInvoice(uint(id), format)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Invoice not found",
+				Details: err.Error(),
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to generate invoice",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	writeDocument(c, doc)
+}
+
+// GetPackingSlip handles GET /api/v1/orders/:id/packing-slip
+func (h *DocumentHandler) GetPackingSlip(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	format, ok := documentFormat(c)
+	if !ok {
+		return
+	}
+
+	doc, err := h.documentService.GetPackingSlip(uint(id), format)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Order has no packing slip",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to generate packing slip",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	writeDocument(c, doc)
+}
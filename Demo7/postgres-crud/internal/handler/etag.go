@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"postgres-crud/internal/dto"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag sets the ETag header from a record version
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// ifMatchVersion reads the version a client expects from the If-Match header.
+// It returns nil when the header is absent or "*". A header that does not hold
+// a single version ETag can never match, so it is answered with 412 and ok is
+// false.
+func ifMatchVersion(c *gin.Context) (*uint, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 32)
+	if err != nil || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+			Error:   "Precondition failed",
+			Details: fmt.Sprintf("If-Match %s does not match the current version", header),
+			Code:    http.StatusPreconditionFailed,
+		})
+		return nil, false
+	}
+
+	expected := uint(version)
+	return &expected, true
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 	"postgres-crud/internal/dto"
 	"postgres-crud/internal/errors"
+	"postgres-crud/internal/validation"
+	"postgres-crud/model"
 	"postgres-crud/service"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,9 +24,136 @@ func NewOrderHandler(orderService service.OrderService) *OrderHandler {
 	}
 }
 
+// toOrderResponse converts an order into its API representation. Line items
+// show the product as it was when added; the current catalog records of the
+// products are only included when withProducts is set and they were loaded
+// with the order.
+func toOrderResponse(order model.Order, withProducts bool) dto.OrderResponse {
+	response := dto.OrderResponse{
+		ID:          order.ID,
+		CustomerID:  order.CustomerID,
+		Description: order.Description,
+		Status:      string(order.Status),
+		Currency:    order.Currency,
+		Shipping:    toPostalAddressResponse(order.ShippingAddress),
+		Billing:     toPostalAddressResponse(order.BillingAddress),
+		Version:     order.Version,
+		CreatedAt:   order.CreatedAt,
+		UpdatedAt:   order.UpdatedAt,
+	}
+
+	if withProducts && len(order.Products) > 0 {
+		response.Products = toProductResponses(order.Products)
+	}
+	if len(order.Items) > 0 {
+		response.Items = toOrderItemResponses(order.Items, withProducts)
+	}
+	if len(order.Promotions) > 0 {
+		response.Promotions = toOrderPromotionResponses(order.Promotions)
+	}
+	if order.PricedAt != nil {
+		response.Totals = toOrderTotalsResponse(order, false)
+	}
+	if order.DeletedAt.Valid {
+		response.DeletedAt = &order.DeletedAt.Time
+	}
+	if order.Status == model.OrderStatusCancelled {
+		response.Cancellation = &dto.OrderCancellationResponse{
+			Reason:      string(order.CancelReason),
+			Note:        order.CancelNote,
+			CancelledAt: order.CancelledAt,
+		}
+	}
+
+	return response
+}
+
+// toOrderTotalsResponse converts the totals stored on an order into their API
+// representation
+func toOrderTotalsResponse(order model.Order, estimated bool) *dto.OrderTotalsResponse {
+	return &dto.OrderTotalsResponse{
+		Subtotal:          order.Subtotal,
+		DiscountPercent:   order.DiscountPercent,
+		PromotionDiscount: order.PromotionDiscount,
+		DiscountTotal:     order.DiscountTotal,
+		TaxRate:           order.TaxRate,
+		TaxTotal:          order.TaxTotal,
+		GrandTotal:        order.GrandTotal,
+		TaxLines:          toOrderTaxLineResponses(order.TaxLines),
+		Currency:          order.Currency,
+		Estimated:         estimated,
+		PricedAt:          order.PricedAt,
+	}
+}
+
+// toOrderDetailResponse converts an order loaded with its products, items and
+// promotions into its API representation. Draft orders have no persisted
+// totals yet, so they are given an estimate when one can be made.
+func toOrderDetailResponse(orderService service.OrderService, order *model.Order, withProducts bool) dto.OrderResponse {
+	response := toOrderResponse(*order, withProducts)
+	if order.PricedAt == nil {
+		quote := *order
+		if err := orderService.QuoteOrder(&quote); err == nil {
+			response.Totals = toOrderTotalsResponse(quote, true)
+		}
+	}
+	return response
+}
+
+// toOrderPromotionResponses converts the promotions on an order into API
+// representations
+func toOrderPromotionResponses(promotions []model.OrderPromotion) []dto.OrderPromotionResponse {
+	response := make([]dto.OrderPromotionResponse, len(promotions))
+	for i, promotion := range promotions {
+		response[i] = dto.OrderPromotionResponse{
+			PromotionID: promotion.PromotionID,
+			Code:        promotion.Code,
+			Name:        promotion.Name,
+			Applied:     promotion.Applied,
+			Reason:      promotion.Reason,
+			Discount:    promotion.Discount,
+		}
+	}
+	return response
+}
+
+// toOrderResponses converts a slice of orders into API representations
+func toOrderResponses(orders []model.Order, withProducts bool) []dto.OrderResponse {
+	response := make([]dto.OrderResponse, len(orders))
+	for i, order := range orders {
+		response[i] = toOrderResponse(order, withProducts)
+	}
+	return response
+}
+
+// toLineErrorResponses converts the per-line errors of a request into their API
+// representation
+func toLineErrorResponses(lines []errors.LineError) []dto.LineErrorResponse {
+	response := make([]dto.LineErrorResponse, len(lines))
+	for i, line := range lines {
+		response[i] = dto.LineErrorResponse{
+			Index:     line.Index,
+			ProductID: line.ProductID,
+			VariantID: line.VariantID,
+			Error:     line.Message,
+		}
+	}
+	return response
+}
+
+// toOrderAddress converts the address selection of a create order request
+func toOrderAddress(savedID *uint, address *dto.AddressRequest) service.OrderAddress {
+	selection := service.OrderAddress{SavedID: savedID}
+	if address != nil {
+		postal := toPostalAddress(*address)
+		selection.Address = &postal
+	}
+	return selection
+}
+
 // CreateOrder handles POST /api/v1/orders
 // @Summary Create a new order
-// @Description Create a new order with description
+// @Description Create a new order for a customer with description and, optionally, its line items
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -38,13 +168,50 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
 			Code:    http.StatusBadRequest,
 		})
 		return
 	}
 
-	order, err := h.orderService.CreateOrder(req.Description)
+	currency := req.Currency
+	if currency == "" {
+		var ok bool
+		if currency, ok = requestedCurrency(c); !ok {
+			return
+		}
+	}
+
+	lines := make([]service.OrderLine, len(req.Items))
+	for i, item := range req.Items {
+		lines[i] = service.OrderLine{
+			ProductID: item.ProductID,
+			VariantID: optionalID(item.VariantID),
+			Quantity:  item.Quantity,
+		}
+	}
+
+	order, err := h.orderService.CreateOrder(req.CustomerID, req.Description, currency,
+		toOrderAddress(req.ShippingAddressID, req.ShippingAddress),
+		toOrderAddress(req.BillingAddressID, req.BillingAddress), lines)
 	if err != nil {
+		if lineErrs, ok := errors.AsLineErrors(err); ok {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Failed to create order",
+				Details: lineErrs.Message,
+				Lines:   toLineErrorResponses(lineErrs.Lines),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		if errors.IsBadRequest(err) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Failed to create order",
+				Details: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to create order",
 			Details: err.Error(),
@@ -53,31 +220,7 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	response := dto.OrderResponse{
-		ID:          order.ID,
-		Description: order.Description,
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
-	}
-
-	// Convert products if they exist
-	if len(order.Products) > 0 {
-		products := make([]dto.ProductResponse, len(order.Products))
-		for i, p := range order.Products {
-			products[i] = dto.ProductResponse{
-				ID:          p.ID,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-				Stock:       p.Stock,
-				CreatedAt:   p.CreatedAt,
-				UpdatedAt:   p.UpdatedAt,
-			}
-		}
-		response.Products = products
-	}
-
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusCreated, toOrderDetailResponse(h.orderService, order, false))
 }
 
 // GetOrder handles GET /api/v1/orders/:id
@@ -86,6 +229,7 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 // @Tags orders
 // @Produce json
 // @Param id path int true "Order ID"
+// @Param with_products query bool false "Include the current catalog records of the products"
 // @Success 200 {object} dto.OrderResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
@@ -117,31 +261,8 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 		return
 	}
 
-	response := dto.OrderResponse{
-		ID:          order.ID,
-		Description: order.Description,
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
-	}
-
-	// Convert products if they exist
-	if len(order.Products) > 0 {
-		products := make([]dto.ProductResponse, len(order.Products))
-		for i, p := range order.Products {
-			products[i] = dto.ProductResponse{
-				ID:          p.ID,
-				Name:        p.Name,
-				Description: p.Description,
-				Price:       p.Price,
-				Stock:       p.Stock,
-				CreatedAt:   p.CreatedAt,
-				UpdatedAt:   p.UpdatedAt,
-			}
-		}
-		response.Products = products
-	}
-
-	c.JSON(http.StatusOK, response)
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, toOrderDetailResponse(h.orderService, order, withProductsParam(c)))
 }
 
 // ListOrders handles GET /api/v1/orders
@@ -151,6 +272,7 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 // @Produce json
 // @Param description query string false "Filter by description pattern"
 // @Param product_id query int false "Filter orders containing this product ID"
+// @Param customer_id query int false "Filter orders placed by this customer ID"
 // @Param with_products query bool false "Include products in response"
 // @Success 200 {object} dto.ListOrdersResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -158,6 +280,34 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 func (h *OrderHandler) ListOrders(c *gin.Context) {
 	var filterReq dto.FilterOrdersRequest
 	if err := c.ShouldBindQuery(&filterReq); err == nil {
+		// Filter by customer ID
+		if filterReq.CustomerID != nil && *filterReq.CustomerID > 0 {
+			orders, err := h.orderService.GetOrdersByCustomerID(*filterReq.CustomerID, filterReq.WithProducts)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					c.JSON(http.StatusNotFound, dto.ErrorResponse{
+						Error: "Customer not found",
+						Code:  http.StatusNotFound,
+					})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+					Error:   "Failed to fetch orders",
+					Details: err.Error(),
+					Code:    http.StatusInternalServerError,
+				})
+				return
+			}
+
+			response := toOrderResponses(orders, filterReq.WithProducts)
+
+			c.JSON(http.StatusOK, dto.ListOrdersResponse{
+				Orders: response,
+				Count:  len(response),
+			})
+			return
+		}
+
 		// Filter by product ID
 		if filterReq.ProductID != nil && *filterReq.ProductID > 0 {
 			orders, err := h.orderService.GetOrdersByProductID(*filterReq.ProductID)
@@ -170,30 +320,7 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 				return
 			}
 
-			response := make([]dto.OrderResponse, len(orders))
-			for i, order := range orders {
-				response[i] = dto.OrderResponse{
-					ID:          order.ID,
-					Description: order.Description,
-					CreatedAt:   order.CreatedAt,
-					UpdatedAt:   order.UpdatedAt,
-				}
-				if filterReq.WithProducts && len(order.Products) > 0 {
-					products := make([]dto.ProductResponse, len(order.Products))
-					for j, p := range order.Products {
-						products[j] = dto.ProductResponse{
-							ID:          p.ID,
-							Name:        p.Name,
-							Description: p.Description,
-							Price:       p.Price,
-							Stock:       p.Stock,
-							CreatedAt:   p.CreatedAt,
-							UpdatedAt:   p.UpdatedAt,
-						}
-					}
-					response[i].Products = products
-				}
-			}
+			response := toOrderResponses(orders, filterReq.WithProducts)
 
 			c.JSON(http.StatusOK, dto.ListOrdersResponse{
 				Orders: response,
@@ -214,30 +341,7 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 				return
 			}
 
-			response := make([]dto.OrderResponse, len(orders))
-			for i, order := range orders {
-				response[i] = dto.OrderResponse{
-					ID:          order.ID,
-					Description: order.Description,
-					CreatedAt:   order.CreatedAt,
-					UpdatedAt:   order.UpdatedAt,
-				}
-				if filterReq.WithProducts && len(order.Products) > 0 {
-					products := make([]dto.ProductResponse, len(order.Products))
-					for j, p := range order.Products {
-						products[j] = dto.ProductResponse{
-							ID:          p.ID,
-							Name:        p.Name,
-							Description: p.Description,
-							Price:       p.Price,
-							Stock:       p.Stock,
-							CreatedAt:   p.CreatedAt,
-							UpdatedAt:   p.UpdatedAt,
-						}
-					}
-					response[i].Products = products
-				}
-			}
+			response := toOrderResponses(orders, filterReq.WithProducts)
 
 			c.JSON(http.StatusOK, dto.ListOrdersResponse{
 				Orders: response,
@@ -258,30 +362,7 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 				return
 			}
 
-			response := make([]dto.OrderResponse, len(orders))
-			for i, order := range orders {
-				response[i] = dto.OrderResponse{
-					ID:          order.ID,
-					Description: order.Description,
-					CreatedAt:   order.CreatedAt,
-					UpdatedAt:   order.UpdatedAt,
-				}
-				if len(order.Products) > 0 {
-					products := make([]dto.ProductResponse, len(order.Products))
-					for j, p := range order.Products {
-						products[j] = dto.ProductResponse{
-							ID:          p.ID,
-							Name:        p.Name,
-							Description: p.Description,
-							Price:       p.Price,
-							Stock:       p.Stock,
-							CreatedAt:   p.CreatedAt,
-							UpdatedAt:   p.UpdatedAt,
-						}
-					}
-					response[i].Products = products
-				}
-			}
+			response := toOrderResponses(orders, true)
 
 			c.JSON(http.StatusOK, dto.ListOrdersResponse{
 				Orders: response,
@@ -302,15 +383,7 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 		return
 	}
 
-	response := make([]dto.OrderResponse, len(orders))
-	for i, order := range orders {
-		response[i] = dto.OrderResponse{
-			ID:          order.ID,
-			Description: order.Description,
-			CreatedAt:   order.CreatedAt,
-			UpdatedAt:   order.UpdatedAt,
-		}
-	}
+	response := toOrderResponses(orders, false)
 
 	c.JSON(http.StatusOK, dto.ListOrdersResponse{
 		Orders: response,
@@ -350,7 +423,12 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.UpdateOrder(uint(id), req.Description)
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.UpdateOrder(uint(id), req.Description, req.DiscountPercent, expectedVersion)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, dto.ErrorResponse{
@@ -359,6 +437,22 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 			})
 			return
 		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to update order",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update order",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to update order",
 			Details: err.Error(),
@@ -367,12 +461,8 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.OrderResponse{
-		ID:          order.ID,
-		Description: order.Description,
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
-	})
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, toOrderResponse(*order, false))
 }
 
 // DeleteOrder handles DELETE /api/v1/orders/:id
@@ -395,7 +485,12 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.orderService.DeleteOrder(uint(id)); err != nil {
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.orderService.DeleteOrder(uint(id), expectedVersion); err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, dto.ErrorResponse{
 				Error: "Order not found",
@@ -403,6 +498,22 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 			})
 			return
 		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to delete order",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to delete order",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to delete order",
 			Details: err.Error(),
@@ -446,35 +557,260 @@ func (h *OrderHandler) GetOrdersByProduct(c *gin.Context) {
 		return
 	}
 
-	response := make([]dto.OrderResponse, len(orders))
-	for i, order := range orders {
-		response[i] = dto.OrderResponse{
-			ID:          order.ID,
-			Description: order.Description,
-			CreatedAt:   order.CreatedAt,
-			UpdatedAt:   order.UpdatedAt,
-		}
-		// Include products in response
-		if len(order.Products) > 0 {
-			products := make([]dto.ProductResponse, len(order.Products))
-			for j, p := range order.Products {
-				products[j] = dto.ProductResponse{
-					ID:          p.ID,
-					Name:        p.Name,
-					Description: p.Description,
-					Price:       p.Price,
-					Stock:       p.Stock,
-					CreatedAt:   p.CreatedAt,
-					UpdatedAt:   p.UpdatedAt,
-				}
-			}
-			response[i].Products = products
+	response := toOrderResponses(orders, true)
+
+	c.JSON(http.StatusOK, dto.ListOrdersResponse{
+		Orders: response,
+		Count:  len(response),
+	})
+}
+
+// GetOrdersByCustomer handles GET /api/v1/customers/:id/orders
+// @Summary Get orders placed by a specific customer
+// @Description Get all orders placed by a customer, newest first
+// @Tags orders
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param with_products query bool false "Include products in response"
+// @Success 200 {object} dto.ListOrdersResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /api/v1/customers/{id}/orders [get]
+func (h *OrderHandler) GetOrdersByCustomer(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid customer ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var filterReq dto.FilterOrdersRequest
+	if err := c.ShouldBindQuery(&filterReq); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	orders, err := h.orderService.GetOrdersByCustomerID(uint(customerID), filterReq.WithProducts)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Customer not found",
+				Code:  http.StatusNotFound,
+			})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch orders",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
 	}
 
+	response := toOrderResponses(orders, filterReq.WithProducts)
+
 	c.JSON(http.StatusOK, dto.ListOrdersResponse{
 		Orders: response,
 		Count:  len(response),
 	})
 }
 
+// TransitionOrder handles POST /api/v1/orders/:id/transitions
+// @Summary Change the status of an order
+// @Description Move an order along its lifecycle (draft, placed, paid, shipped, delivered, cancelled)
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param transition body dto.TransitionOrderRequest true "Target status"
+// @Success 200 {object} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /api/v1/orders/{id}/transitions [post]
+func (h *OrderHandler) TransitionOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.TransitionOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	order, err := h.orderService.TransitionOrder(uint(id), model.OrderStatus(req.Status), req.Note)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Invalid status transition",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to change order status",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toOrderResponse(*order, false))
+}
+
+// CancelOrder handles POST /api/v1/orders/:id/cancel
+// @Summary Cancel an order
+// @Description Cancel an order that has not been shipped, with a reason code and note. Its units are returned to stock.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param cancellation body dto.CancelOrderRequest true "Reason and note"
+// @Param If-Match header string false "Expected order version (ETag)"
+// @Success 200 {object} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
+// @Router /api/v1/orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(uint(id), model.CancelReason(req.Reason), req.Note, expectedVersion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Order has been modified",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Order cannot be cancelled",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to cancel order",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, toOrderResponse(*order, false))
+}
+
+// GetOrderTransitions handles GET /api/v1/orders/:id/transitions
+// @Summary Get the status history of an order
+// @Description Get all status changes of an order, oldest first
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} dto.ListOrderTransitionsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /api/v1/orders/{id}/transitions [get]
+func (h *OrderHandler) GetOrderTransitions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	transitions, err := h.orderService.GetOrderTransitions(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch order transitions",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.OrderTransitionResponse, len(transitions))
+	for i, t := range transitions {
+		response[i] = dto.OrderTransitionResponse{
+			ID:         t.ID,
+			FromStatus: string(t.FromStatus),
+			ToStatus:   string(t.ToStatus),
+			Note:       t.Note,
+			CreatedAt:  t.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.ListOrderTransitionsResponse{
+		Transitions: response,
+		Count:       len(response),
+	})
+}
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderItemHandler handles HTTP requests for order line items
+type OrderItemHandler struct {
+	productService service.ProductService
+}
+
+// NewOrderItemHandler creates a new instance of OrderItemHandler
+func NewOrderItemHandler(productService service.ProductService) *OrderItemHandler {
+	return &OrderItemHandler{
+		productService: productService,
+	}
+}
+
+// toOrderItemResponse converts an order line into its API representation. The
+// current catalog record of the product is only included when withProduct is
+// set and it was loaded with the line.
+func toOrderItemResponse(item model.OrderProduct, withProduct bool) dto.OrderItemResponse {
+	var variantID *uint
+	if item.VariantID != 0 {
+		variantID = &item.VariantID
+	}
+
+	response := dto.OrderItemResponse{
+		OrderID:            item.OrderID,
+		ProductID:          item.ProductID,
+		VariantID:          variantID,
+		SKU:                item.SKU,
+		ProductName:        item.ProductName,
+		ProductDescription: item.ProductDescription,
+		Quantity:           item.Quantity,
+		UnitPrice:          item.Price,
+		Currency:           item.Currency,
+		ExchangeRate:       item.ExchangeRate,
+		DiscountPercent:    item.DiscountPercent,
+		LineTotal:          item.LineTotal(),
+		CreatedAt:          item.CreatedAt,
+	}
+	if withProduct && item.Product.ID != 0 {
+		product := toProductResponse(item.Product)
+		response.Product = &product
+	}
+	return response
+}
+
+// toOrderItemResponses converts a slice of order lines into API representations
+func toOrderItemResponses(items []model.OrderProduct, withProducts bool) []dto.OrderItemResponse {
+	response := make([]dto.OrderItemResponse, len(items))
+	for i, item := range items {
+		response[i] = toOrderItemResponse(item, withProducts)
+	}
+	return response
+}
+
+// withProductsParam reports whether the current catalog records of the
+// products were asked for with ?with_products=true
+func withProductsParam(c *gin.Context) bool {
+	withProducts, _ := strconv.ParseBool(c.Query("with_products"))
+	return withProducts
+}
+
+// parseOrderItemParams parses the :id and :productId path parameters and the
+// optional ?variant_id= query parameter, which is 0 when absent
+func parseOrderItemParams(c *gin.Context) (uint, uint, uint, bool) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return 0, 0, 0, false
+	}
+
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return 0, 0, 0, false
+	}
+
+	variantID, ok := variantQuery(c)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	return uint(orderID), uint(productID), variantID, true
+}
+
+// variantQuery parses the optional ?variant_id= query parameter that selects
+// the line for one variant of a product. It returns 0 when absent.
+func variantQuery(c *gin.Context) (uint, bool) {
+	value := c.Query("variant_id")
+	if value == "" {
+		return 0, true
+	}
+
+	variantID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid variant ID",
+			Code:  http.StatusBadRequest,
+		})
+		return 0, false
+	}
+	return uint(variantID), true
+}
+
+// optionalID dereferences an optional ID, returning 0 for nil
+func optionalID(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// ListOrderItems handles GET /api/v1/orders/:id/items
+func (h *OrderItemHandler) ListOrderItems(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	items, err := h.productService.GetOrderItems(uint(orderID))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch order items",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ListOrderItemsResponse{
+		Items: toOrderItemResponses(items, withProductsParam(c)),
+		Count: len(items),
+	})
+}
+
+// GetOrderItem handles GET /api/v1/orders/:id/items/:productId[?variant_id=]
+func (h *OrderItemHandler) GetOrderItem(c *gin.Context) {
+	orderID, productID, variantID, ok := parseOrderItemParams(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.productService.GetOrderItem(orderID, productID, variantID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order item not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch order item",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toOrderItemResponse(*item, withProductsParam(c)))
+}
+
+// AddOrderItem handles POST /api/v1/orders/:id/items
+func (h *OrderItemHandler) AddOrderItem(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.AddOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	item, err := h.productService.AddProductToOrder(uint(orderID), req.ProductID, optionalID(req.VariantID), req.Quantity)
+	if err != nil {
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to add item to order",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to add item to order",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toOrderItemResponse(*item, false))
+}
+
+// UpdateOrderItem handles PUT /api/v1/orders/:id/items/:productId[?variant_id=]
+func (h *OrderItemHandler) UpdateOrderItem(c *gin.Context) {
+	orderID, productID, variantID, ok := parseOrderItemParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	item, err := h.productService.UpdateOrderItem(orderID, productID, variantID, req.Quantity, req.DiscountPercent)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order item not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update order item",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to update order item",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toOrderItemResponse(*item, false))
+}
+
+// DeleteOrderItem handles DELETE /api/v1/orders/:id/items/:productId[?variant_id=]
+func (h *OrderItemHandler) DeleteOrderItem(c *gin.Context) {
+	orderID, productID, variantID, ok := parseOrderItemParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.productService.RemoveProductFromOrder(orderID, productID, variantID); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order item not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to remove item from order",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to remove item from order",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Order item removed successfully",
+	})
+}
@@ -2,25 +2,115 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 	"postgres-crud/internal/dto"
 	"postgres-crud/internal/errors"
+	"postgres-crud/model"
 	"postgres-crud/service"
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
 // ProductHandler handles HTTP requests for products
 type ProductHandler struct {
-	productService service.ProductService
+	productService  service.ProductService
+	currencyService service.CurrencyService
 }
 
 // NewProductHandler creates a new instance of ProductHandler
-func NewProductHandler(productService service.ProductService) *ProductHandler {
+func NewProductHandler(productService service.ProductService, currencyService service.CurrencyService) *ProductHandler {
 	return &ProductHandler{
-		productService: productService,
+		productService:  productService,
+		currencyService: currencyService,
 	}
 }
 
+// toProductResponse converts a product into its API representation
+func toProductResponse(product model.Product) dto.ProductResponse {
+	tags := make([]string, len(product.Tags))
+	for i, tag := range product.Tags {
+		tags[i] = tag.Name
+	}
+
+	response := dto.ProductResponse{
+		ID:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Currency:    product.Currency,
+		Stock:       product.Stock,
+		TaxClass:    string(product.TaxClass),
+		CategoryID:  product.CategoryID,
+		Tags:        tags,
+		Version:     product.Version,
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+	}
+	if product.DeletedAt.Valid {
+		response.DeletedAt = &product.DeletedAt.Time
+	}
+	if schedule := product.ScheduledPrice; schedule != nil {
+		regular := product.RegularPrice
+		response.RegularPrice = &regular
+		response.PriceEndsAt = schedule.EndsAt
+		response.ScheduleID = &schedule.ID
+	}
+	return response
+}
+
+// toProductResponses converts a slice of products into API representations
+func toProductResponses(products []model.Product) []dto.ProductResponse {
+	response := make([]dto.ProductResponse, len(products))
+	for i, product := range products {
+		response[i] = toProductResponse(product)
+	}
+	return response
+}
+
+// toProductResponsesIn converts products into API representations priced in
+// currency. An empty currency keeps each product's own price. On failure an
+// error response is written and ok is false.
+func (h *ProductHandler) toProductResponsesIn(c *gin.Context, products []model.Product, currency string) ([]dto.ProductResponse, bool) {
+	response := toProductResponses(products)
+	if currency == "" {
+		return response, true
+	}
+
+	for i := range products {
+		price, err := h.currencyService.PriceIn(&products[i], currency)
+		if err != nil {
+			if errors.IsBadRequest(err) {
+				c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+					Error:   "Price not available in currency",
+					Details: err.Error(),
+					Code:    http.StatusBadRequest,
+				})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to price products",
+				Details: err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return nil, false
+		}
+
+		response[i].Price = price.Price
+		response[i].Currency = price.Price.Currency
+		if price.Source == service.PriceSourceConverted {
+			rate := price.Rate
+			response[i].ExchangeRate = &rate
+			if regular := response[i].RegularPrice; regular != nil {
+				converted := regular.Convert(rate, currency)
+				response[i].RegularPrice = &converted
+			}
+		}
+	}
+
+	return response, true
+}
+
 // CreateProduct handles POST /api/v1/products
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req dto.CreateProductRequest
@@ -33,8 +123,18 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.CreateProduct(req.Name, req.Description, req.Price, req.Stock)
+	price := req.Price
+	price.Currency = req.Currency
+	product, err := h.productService.CreateProduct(req.Name, req.Description, price, req.Stock, model.TaxClass(req.TaxClass), req.CategoryID, req.Tags)
 	if err != nil {
+		if errors.IsBadRequest(err) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Failed to create product",
+				Details: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to create product",
 			Details: err.Error(),
@@ -43,15 +143,7 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
-	})
+	c.JSON(http.StatusCreated, toProductResponse(*product))
 }
 
 // GetProduct handles GET /api/v1/products/:id
@@ -65,7 +157,90 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.GetProductByID(uint(id))
+	currency, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+
+	asOf, ok := asOfParam(c)
+	if !ok {
+		return
+	}
+	if asOf != nil && currency != "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid query",
+			Details: "as_of cannot be combined with a currency",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var product *model.Product
+	if asOf != nil {
+		product, err = h.productService.GetProductAt(uint(id), *asOf)
+	} else {
+		product, err = h.productService.GetProductByID(uint(id))
+	}
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Product not found",
+				Details: err.Error(),
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response, ok := h.toProductResponsesIn(c, []model.Product{*product}, currency)
+	if !ok {
+		return
+	}
+	response[0].AsOf = asOf
+
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, response[0])
+}
+
+// asOfParam parses the ?as_of= query parameter as an RFC 3339 timestamp or a
+// plain date (midnight UTC). A nil time means the parameter was not given.
+// Malformed values are answered with 400 and ok is false.
+func asOfParam(c *gin.Context) (*time.Time, bool) {
+	value := c.Query("as_of")
+	if value == "" {
+		return nil, true
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if at, err := time.Parse(layout, value); err == nil {
+			return &at, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		Error:   "Invalid as_of",
+		Details: "as_of must be an RFC 3339 timestamp or a date, got " + strconv.Quote(value),
+		Code:    http.StatusBadRequest,
+	})
+	return nil, false
+}
+
+// GetPriceHistory handles GET /api/v1/products/:id/prices
+func (h *ProductHandler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	product, entries, err := h.productService.GetPriceHistory(uint(id))
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, dto.ErrorResponse{
@@ -75,41 +250,57 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 			return
 		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to fetch product",
+			Error:   "Failed to fetch price history",
 			Details: err.Error(),
 			Code:    http.StatusInternalServerError,
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+	prices := make([]dto.ProductPriceResponse, len(entries))
+	for i, entry := range entries {
+		prices[i] = dto.ProductPriceResponse{
+			Price:         entry.Price,
+			Currency:      entry.Currency,
+			EffectiveFrom: entry.EffectiveFrom,
+		}
+		if i+1 < len(entries) {
+			prices[i].EffectiveTo = &entries[i+1].EffectiveFrom
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.ProductPriceHistoryResponse{
+		ProductID: product.ID,
+		Prices:    prices,
+		Count:     len(prices),
 	})
 }
 
 // ListProducts handles GET /api/v1/products
 func (h *ProductHandler) ListProducts(c *gin.Context) {
+	currency, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+
 	// Check if filtering is requested
 	var filterReq dto.FilterProductsRequest
 	if err := c.ShouldBindQuery(&filterReq); err == nil {
 		// If any filter parameter is provided, use filtering
-		if filterReq.Name != "" || filterReq.Description != "" || 
-		   filterReq.MinPrice != nil || filterReq.MaxPrice != nil ||
-		   filterReq.MinStock != nil || filterReq.MaxStock != nil {
-			products, err := h.productService.FilterProducts(
-				filterReq.Name,
-				filterReq.Description,
-				filterReq.MinPrice,
-				filterReq.MaxPrice,
-				filterReq.MinStock,
-				filterReq.MaxStock,
-			)
+		if filterReq.Name != "" || filterReq.Description != "" ||
+			filterReq.MinPrice != nil || filterReq.MaxPrice != nil ||
+			filterReq.MinStock != nil || filterReq.MaxStock != nil ||
+			filterReq.CategoryID != nil || len(filterReq.Tags) > 0 {
+			products, err := h.productService.FilterProducts(service.ProductFilter{
+				Name:        filterReq.Name,
+				Description: filterReq.Description,
+				MinPrice:    filterReq.MinPrice,
+				MaxPrice:    filterReq.MaxPrice,
+				MinStock:    filterReq.MinStock,
+				MaxStock:    filterReq.MaxStock,
+				CategoryID:  filterReq.CategoryID,
+				Tags:        filterReq.Tags,
+			})
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 					Error:   "Failed to filter products",
@@ -119,17 +310,9 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 				return
 			}
 
-			response := make([]dto.ProductResponse, len(products))
-			for i, product := range products {
-				response[i] = dto.ProductResponse{
-					ID:          product.ID,
-					Name:        product.Name,
-					Description: product.Description,
-					Price:       product.Price,
-					Stock:       product.Stock,
-					CreatedAt:   product.CreatedAt,
-					UpdatedAt:   product.UpdatedAt,
-				}
+			response, ok := h.toProductResponsesIn(c, products, currency)
+			if !ok {
+				return
 			}
 
 			c.JSON(http.StatusOK, dto.ListProductsResponse{
@@ -151,17 +334,9 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		return
 	}
 
-	response := make([]dto.ProductResponse, len(products))
-	for i, product := range products {
-		response[i] = dto.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
-			CreatedAt:   product.CreatedAt,
-			UpdatedAt:   product.UpdatedAt,
-		}
+	response, ok := h.toProductResponsesIn(c, products, currency)
+	if !ok {
+		return
 	}
 
 	c.JSON(http.StatusOK, dto.ListProductsResponse{
@@ -191,7 +366,14 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.UpdateProduct(uint(id), req.Name, req.Description, req.Price, req.Stock)
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	price := req.Price
+	price.Currency = req.Currency
+	product, err := h.productService.UpdateProduct(uint(id), req.Name, req.Description, price, req.Stock, model.TaxClass(req.TaxClass), req.CategoryID, req.Tags, expectedVersion)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, dto.ErrorResponse{
@@ -200,6 +382,30 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 			})
 			return
 		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to update product",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update product",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		if errors.IsBadRequest(err) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Failed to update product",
+				Details: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to update product",
 			Details: err.Error(),
@@ -208,15 +414,8 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
-	})
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, toProductResponse(*product))
 }
 
 // DeleteProduct handles DELETE /api/v1/products/:id
@@ -230,7 +429,12 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	if err := h.productService.DeleteProduct(uint(id)); err != nil {
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.productService.DeleteProduct(uint(id), expectedVersion); err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, dto.ErrorResponse{
 				Error: "Product not found",
@@ -238,6 +442,22 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 			})
 			return
 		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to delete product",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to delete product",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to delete product",
 			Details: err.Error(),
@@ -272,7 +492,15 @@ func (h *ProductHandler) AddProductToOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.productService.AddProductToOrder(uint(orderID), req.ProductID, req.Quantity); err != nil {
+	if _, err := h.productService.AddProductToOrder(uint(orderID), req.ProductID, optionalID(req.VariantID), req.Quantity); err != nil {
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to add product to order",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Failed to add product to order",
 			Details: err.Error(),
@@ -286,7 +514,7 @@ func (h *ProductHandler) AddProductToOrder(c *gin.Context) {
 	})
 }
 
-// RemoveProductFromOrder handles DELETE /api/v1/orders/:id/products/:productId
+// RemoveProductFromOrder handles DELETE /api/v1/orders/:id/products/:productId[?variant_id=]
 func (h *ProductHandler) RemoveProductFromOrder(c *gin.Context) {
 	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -306,7 +534,27 @@ func (h *ProductHandler) RemoveProductFromOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.productService.RemoveProductFromOrder(uint(orderID), uint(productID)); err != nil {
+	variantID, ok := variantQuery(c)
+	if !ok {
+		return
+	}
+
+	if err := h.productService.RemoveProductFromOrder(uint(orderID), uint(productID), variantID); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found in order",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to remove product from order",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Failed to remove product from order",
 			Details: err.Error(),
@@ -341,23 +589,10 @@ func (h *ProductHandler) GetOrderProducts(c *gin.Context) {
 		return
 	}
 
-	response := make([]dto.ProductResponse, len(products))
-	for i, product := range products {
-		response[i] = dto.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
-			CreatedAt:   product.CreatedAt,
-			UpdatedAt:   product.UpdatedAt,
-		}
-	}
+	response := toProductResponses(products)
 
 	c.JSON(http.StatusOK, dto.ListProductsResponse{
 		Products: response,
 		Count:    len(response),
 	})
 }
-
-
@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/internal/validation"
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler handles HTTP requests for promotions and order coupons
+type PromotionHandler struct {
+	promotionService service.PromotionService
+	orderService     service.OrderService
+}
+
+// NewPromotionHandler creates a new instance of PromotionHandler
+func NewPromotionHandler(promotionService service.PromotionService, orderService service.OrderService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+		orderService:     orderService,
+	}
+}
+
+// toPromotionResponse converts a promotion into its API representation
+func toPromotionResponse(promotion model.Promotion) dto.PromotionResponse {
+	return dto.PromotionResponse{
+		ID:                    promotion.ID,
+		Name:                  promotion.Name,
+		Code:                  promotion.Code,
+		Type:                  string(promotion.Type),
+		PercentOff:            promotion.PercentOff,
+		AmountOff:             promotion.AmountOff,
+		MinOrderValue:         promotion.MinOrderValue,
+		Currency:              promotion.Currency,
+		ProductID:             promotion.ProductID,
+		BuyQuantity:           promotion.BuyQuantity,
+		GetQuantity:           promotion.GetQuantity,
+		UsageLimitPerCustomer: promotion.UsageLimitPerCustomer,
+		StartsAt:              promotion.StartsAt,
+		EndsAt:                promotion.EndsAt,
+		Stackable:             promotion.Stackable,
+		Priority:              promotion.Priority,
+		Active:                promotion.Active,
+		Version:               promotion.Version,
+		CreatedAt:             promotion.CreatedAt,
+		UpdatedAt:             promotion.UpdatedAt,
+	}
+}
+
+// toPromotion converts a create or update promotion request into a model.
+// Amounts are in the request currency, or the default currency.
+func toPromotion(req dto.UpdatePromotionRequest) model.Promotion {
+	currency := req.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	return model.Promotion{
+		Name:                  req.Name,
+		Code:                  req.Code,
+		Type:                  model.PromotionType(req.Type),
+		PercentOff:            req.PercentOff,
+		AmountOff:             money.New(req.AmountOff.Amount, currency),
+		MinOrderValue:         money.New(req.MinOrderValue.Amount, currency),
+		Currency:              currency,
+		ProductID:             req.ProductID,
+		BuyQuantity:           req.BuyQuantity,
+		GetQuantity:           req.GetQuantity,
+		UsageLimitPerCustomer: req.UsageLimitPerCustomer,
+		StartsAt:              req.StartsAt,
+		EndsAt:                req.EndsAt,
+		Stackable:             req.Stackable,
+		Priority:              req.Priority,
+		Active:                active,
+	}
+}
+
+// CreatePromotion handles POST /api/v1/promotions
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req dto.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(toPromotion(dto.UpdatePromotionRequest(req)))
+	if err != nil {
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to create promotion",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to create promotion",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, promotion.Version)
+	c.JSON(http.StatusCreated, toPromotionResponse(*promotion))
+}
+
+// ListPromotions handles GET /api/v1/promotions
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	promotions, err := h.promotionService.GetAllPromotions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch promotions",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.PromotionResponse, len(promotions))
+	for i, promotion := range promotions {
+		response[i] = toPromotionResponse(promotion)
+	}
+
+	c.JSON(http.StatusOK, dto.ListPromotionsResponse{
+		Promotions: response,
+		Count:      len(response),
+	})
+}
+
+// GetPromotion handles GET /api/v1/promotions/:id
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid promotion ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotionByID(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Promotion not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch promotion",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setETag(c, promotion.Version)
+	c.JSON(http.StatusOK, toPromotionResponse(*promotion))
+}
+
+// UpdatePromotion handles PUT /api/v1/promotions/:id
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid promotion ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(uint(id), toPromotion(req), expectedVersion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Promotion not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to update promotion",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update promotion",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to update promotion",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, promotion.Version)
+	c.JSON(http.StatusOK, toPromotionResponse(*promotion))
+}
+
+// DeletePromotion handles DELETE /api/v1/promotions/:id
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid promotion ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.promotionService.DeletePromotion(uint(id), expectedVersion); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Promotion not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to delete promotion",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to delete promotion",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete promotion",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Promotion deleted successfully",
+	})
+}
+
+// ApplyCoupon handles POST /api/v1/orders/:id/coupons
+func (h *PromotionHandler) ApplyCoupon(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.ApplyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	order, err := h.promotionService.ApplyCoupon(uint(id), req.Code)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to apply coupon",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to apply coupon",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, toOrderDetailResponse(h.orderService, order, false))
+}
+
+// RemoveCoupon handles DELETE /api/v1/orders/:id/coupons/:code
+func (h *PromotionHandler) RemoveCoupon(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	order, err := h.promotionService.RemoveCoupon(uint(id), c.Param("code"))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Failed to remove coupon",
+				Details: err.Error(),
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to remove coupon",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to remove coupon",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setETag(c, order.Version)
+	c.JSON(http.StatusOK, toOrderDetailResponse(h.orderService, order, false))
+}
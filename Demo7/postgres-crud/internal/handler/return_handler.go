@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/internal/validation"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReturnHandler handles HTTP requests for returns of delivered orders
+type ReturnHandler struct {
+	returnService service.ReturnService
+}
+
+// NewReturnHandler creates a new instance of ReturnHandler
+func NewReturnHandler(returnService service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+	}
+}
+
+// toReturnResponse converts a return into its API representation
+func toReturnResponse(ret model.Return) dto.ReturnResponse {
+	lines := make([]dto.ReturnLineResponse, len(ret.Lines))
+	for i, line := range ret.Lines {
+		var variantID *uint
+		if line.VariantID != 0 {
+			variantID = &ret.Lines[i].VariantID
+		}
+		lines[i] = dto.ReturnLineResponse{
+			ProductID: line.ProductID,
+			VariantID: variantID,
+			SKU:       line.SKU,
+			Quantity:  line.Quantity,
+			UnitPrice: line.UnitPrice,
+			Amount:    line.Amount,
+		}
+	}
+
+	return dto.ReturnResponse{
+		ID:           ret.ID,
+		OrderID:      ret.OrderID,
+		Status:       string(ret.Status),
+		Reason:       ret.Reason,
+		Note:         ret.Note,
+		Lines:        lines,
+		RefundAmount: ret.RefundAmount,
+		Currency:     ret.Currency,
+		Restocked:    ret.Restocked,
+		Version:      ret.Version,
+		CreatedAt:    ret.CreatedAt,
+		ApprovedAt:   ret.ApprovedAt,
+		ReceivedAt:   ret.ReceivedAt,
+		RefundedAt:   ret.RefundedAt,
+		UpdatedAt:    ret.UpdatedAt,
+	}
+}
+
+// returnIDParams parses the order and return IDs from the path. Invalid IDs
+// are answered with 400 and ok is false.
+func returnIDParams(c *gin.Context) (uint, uint, bool) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return 0, 0, false
+	}
+	returnID, err := strconv.ParseUint(c.Param("returnId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid return ID",
+			Code:  http.StatusBadRequest,
+		})
+		return 0, 0, false
+	}
+	return uint(orderID), uint(returnID), true
+}
+
+// ListReturns handles GET /api/v1/orders/:id/returns
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	returns, err := h.returnService.GetReturns(uint(orderID))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch returns",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.ReturnResponse, len(returns))
+	for i, ret := range returns {
+		response[i] = toReturnResponse(ret)
+	}
+
+	c.JSON(http.StatusOK, dto.ListReturnsResponse{
+		OrderID: uint(orderID),
+		Returns: response,
+		Count:   len(response),
+	})
+}
+
+// CreateReturn handles POST /api/v1/orders/:id/returns
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid order ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	lines := make([]service.OrderLine, len(req.Items))
+	for i, item := range req.Items {
+		lines[i] = service.OrderLine{
+			ProductID: item.ProductID,
+			VariantID: optionalID(item.VariantID),
+			Quantity:  item.Quantity,
+		}
+	}
+
+	ret, err := h.returnService.CreateReturn(uint(orderID), req.Reason, req.Note, lines)
+	if err != nil {
+		if lineErrs, ok := errors.AsLineErrors(err); ok {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Failed to create return",
+				Details: lineErrs.Message,
+				Lines:   toLineErrorResponses(lineErrs.Lines),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Order not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Order cannot be returned",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to create return",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, ret.Version)
+	c.JSON(http.StatusCreated, toReturnResponse(*ret))
+}
+
+// GetReturn handles GET /api/v1/orders/:id/returns/:returnId
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	orderID, returnID, ok := returnIDParams(c)
+	if !ok {
+		return
+	}
+
+	ret, err := h.returnService.GetReturn(orderID, returnID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Return not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch return",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setETag(c, ret.Version)
+	c.JSON(http.StatusOK, toReturnResponse(*ret))
+}
+
+// ApproveReturn handles POST /api/v1/orders/:id/returns/:returnId/approve
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	h.advanceReturn(c, "Failed to approve return", func(orderID, returnID uint, expectedVersion *uint) (*model.Return, error) {
+		return h.returnService.ApproveReturn(orderID, returnID, expectedVersion)
+	})
+}
+
+// ReceiveReturn handles POST /api/v1/orders/:id/returns/:returnId/receive
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	var req dto.ReceiveReturnRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid request body",
+				Details: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	h.advanceReturn(c, "Failed to receive return", func(orderID, returnID uint, expectedVersion *uint) (*model.Return, error) {
+		return h.returnService.ReceiveReturn(orderID, returnID, req.Restock, expectedVersion)
+	})
+}
+
+// RefundReturn handles POST /api/v1/orders/:id/returns/:returnId/refund
+func (h *ReturnHandler) RefundReturn(c *gin.Context) {
+	h.advanceReturn(c, "Failed to refund return", func(orderID, returnID uint, expectedVersion *uint) (*model.Return, error) {
+		return h.returnService.RefundReturn(orderID, returnID, expectedVersion)
+	})
+}
+
+// advanceReturn moves the return named in the path on to its next status with
+// advance, honouring If-Match, and writes the response. failure is the error
+// message for unexpected errors.
+func (h *ReturnHandler) advanceReturn(c *gin.Context, failure string, advance func(orderID, returnID uint, expectedVersion *uint) (*model.Return, error)) {
+	orderID, returnID, ok := returnIDParams(c)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	ret, err := advance(orderID, returnID, expectedVersion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Return not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Return has been modified",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Invalid return status change",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   failure,
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setETag(c, ret.Version)
+	c.JSON(http.StatusOK, toReturnResponse(*ret))
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/internal/validation"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduledPriceHandler handles HTTP requests for scheduled product prices
+type ScheduledPriceHandler struct {
+	scheduleService service.ScheduledPriceService
+}
+
+// NewScheduledPriceHandler creates a new instance of ScheduledPriceHandler
+func NewScheduledPriceHandler(scheduleService service.ScheduledPriceService) *ScheduledPriceHandler {
+	return &ScheduledPriceHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// toScheduledPriceResponse converts a scheduled price into its API
+// representation as of now
+func toScheduledPriceResponse(schedule model.ScheduledPrice, now time.Time) dto.ScheduledPriceResponse {
+	return dto.ScheduledPriceResponse{
+		ID:        schedule.ID,
+		ProductID: schedule.ProductID,
+		Price:     schedule.Price,
+		Currency:  schedule.Currency,
+		StartsAt:  schedule.StartsAt,
+		EndsAt:    schedule.EndsAt,
+		Note:      schedule.Note,
+		Active:    schedule.ActiveAt(now),
+		CreatedAt: schedule.CreatedAt,
+		UpdatedAt: schedule.UpdatedAt,
+	}
+}
+
+// toScheduledPrice converts a scheduled price request into a model
+func toScheduledPrice(req dto.ScheduledPriceRequest) model.ScheduledPrice {
+	price := req.Price
+	price.Currency = req.Currency
+	return model.ScheduledPrice{
+		Price:    price,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Note:     req.Note,
+	}
+}
+
+// ListSchedules handles GET /api/v1/products/:id/scheduled-prices
+func (h *ScheduledPriceHandler) ListSchedules(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	product, schedules, err := h.scheduleService.GetSchedules(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch scheduled prices",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	now := time.Now()
+	response := make([]dto.ScheduledPriceResponse, len(schedules))
+	for i, schedule := range schedules {
+		response[i] = toScheduledPriceResponse(schedule, now)
+	}
+
+	c.JSON(http.StatusOK, dto.ListScheduledPricesResponse{
+		ProductID: product.ID,
+		Schedules: response,
+		Count:     len(response),
+	})
+}
+
+// CreateSchedule handles POST /api/v1/products/:id/scheduled-prices
+func (h *ScheduledPriceHandler) CreateSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.ScheduledPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(uint(id), toScheduledPrice(req))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to schedule price",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to schedule price",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toScheduledPriceResponse(*schedule, time.Now()))
+}
+
+// GetSchedule handles GET /api/v1/scheduled-prices/:id
+func (h *ScheduledPriceHandler) GetSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid scheduled price ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	schedule, err := h.scheduleService.GetScheduleByID(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Scheduled price not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch scheduled price",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toScheduledPriceResponse(*schedule, time.Now()))
+}
+
+// UpdateSchedule handles PUT /api/v1/scheduled-prices/:id
+func (h *ScheduledPriceHandler) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid scheduled price ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.ScheduledPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	schedule, err := h.scheduleService.UpdateSchedule(uint(id), toScheduledPrice(req))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Scheduled price not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update scheduled price",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to update scheduled price",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toScheduledPriceResponse(*schedule, time.Now()))
+}
+
+// DeleteSchedule handles DELETE /api/v1/scheduled-prices/:id
+func (h *ScheduledPriceHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid scheduled price ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.scheduleService.DeleteSchedule(uint(id)); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Scheduled price not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete scheduled price",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Scheduled price deleted successfully",
+	})
+}
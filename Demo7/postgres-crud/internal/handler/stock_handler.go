@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultActor is recorded when a request does not identify its actor
+const defaultActor = "api"
+
+// StockHandler handles HTTP requests for the stock ledger
+type StockHandler struct {
+	stockService service.StockService
+}
+
+// NewStockHandler creates a new instance of StockHandler
+func NewStockHandler(stockService service.StockService) *StockHandler {
+	return &StockHandler{
+		stockService: stockService,
+	}
+}
+
+// actorFromRequest returns the caller identified by the X-Actor header
+func actorFromRequest(c *gin.Context) string {
+	if actor := c.GetHeader("X-Actor"); actor != "" {
+		return actor
+	}
+	return defaultActor
+}
+
+// toStockMovementResponse converts a stock movement into its API representation
+func toStockMovementResponse(movement model.StockMovement) dto.StockMovementResponse {
+	return dto.StockMovementResponse{
+		ID:            movement.ID,
+		ProductID:     movement.ProductID,
+		VariantID:     movement.VariantID,
+		Delta:         movement.Delta,
+		Reason:        string(movement.Reason),
+		ReferenceType: movement.ReferenceType,
+		ReferenceID:   movement.ReferenceID,
+		Actor:         movement.Actor,
+		Note:          movement.Note,
+		CreatedAt:     movement.CreatedAt,
+	}
+}
+
+// toStockHistoryResponse converts a stock history into its API representation
+func toStockHistoryResponse(history *service.StockHistory) dto.StockHistoryResponse {
+	movements := make([]dto.StockMovementResponse, len(history.Movements))
+	for i, movement := range history.Movements {
+		movements[i] = toStockMovementResponse(movement)
+	}
+
+	return dto.StockHistoryResponse{
+		ProductID:     history.ProductID,
+		VariantID:     history.VariantID,
+		Stock:         history.Stock,
+		LedgerBalance: history.LedgerBalance,
+		InSync:        history.InSync(),
+		Movements:     movements,
+		Count:         len(movements),
+	}
+}
+
+// GetStockHistory handles GET /api/v1/products/:id/stock-history
+func (h *StockHandler) GetStockHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	history, err := h.stockService.GetStockHistory(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch stock history",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockHistoryResponse(history))
+}
+
+// AdjustStock handles POST /api/v1/products/:id/stock-adjustments
+func (h *StockHandler) AdjustStock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	movement, err := h.stockService.AdjustStock(uint(id), req.Delta, model.StockMovementReason(req.ReasonCode), actorFromRequest(c), req.Note)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to adjust stock",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to adjust stock",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toStockMovementResponse(*movement))
+}
+
+// GetVariantStockHistory handles GET /api/v1/variants/:id/stock-history
+func (h *StockHandler) GetVariantStockHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid variant ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	history, err := h.stockService.GetVariantStockHistory(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Variant not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch stock history",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockHistoryResponse(history))
+}
+
+// AdjustVariantStock handles POST /api/v1/variants/:id/stock-adjustments
+func (h *StockHandler) AdjustVariantStock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid variant ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	movement, err := h.stockService.AdjustVariantStock(uint(id), req.Delta, model.StockMovementReason(req.ReasonCode), actorFromRequest(c), req.Note)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Variant not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to adjust stock",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toStockMovementResponse(*movement))
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/internal/validation"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TaxHandler handles HTTP requests for the tax rate tables
+type TaxHandler struct {
+	taxService service.TaxService
+}
+
+// NewTaxHandler creates a new instance of TaxHandler
+func NewTaxHandler(taxService service.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+// toTaxRateResponse converts a tax rate into its API representation
+func toTaxRateResponse(rate model.TaxRate) dto.TaxRateResponse {
+	return dto.TaxRateResponse{
+		ID:            rate.ID,
+		Country:       rate.Country,
+		Region:        rate.Region,
+		TaxClass:      string(rate.TaxClass),
+		Rate:          rate.Rate,
+		EffectiveFrom: rate.EffectiveFrom,
+		EffectiveTo:   rate.EffectiveTo,
+		CreatedAt:     rate.CreatedAt,
+		UpdatedAt:     rate.UpdatedAt,
+	}
+}
+
+// toTaxRate converts a tax rate request into a model
+func toTaxRate(req dto.TaxRateRequest) model.TaxRate {
+	return model.TaxRate{
+		Country:       req.Country,
+		Region:        req.Region,
+		TaxClass:      model.TaxClass(req.TaxClass),
+		Rate:          *req.Rate,
+		EffectiveFrom: req.EffectiveFrom,
+		EffectiveTo:   req.EffectiveTo,
+	}
+}
+
+// toOrderTaxLineResponses converts the tax lines of an order into their API
+// representations
+func toOrderTaxLineResponses(lines []model.OrderTaxLine) []dto.OrderTaxLineResponse {
+	if len(lines) == 0 {
+		return nil
+	}
+	response := make([]dto.OrderTaxLineResponse, len(lines))
+	for i, line := range lines {
+		var variantID *uint
+		if line.VariantID != 0 {
+			variantID = &lines[i].VariantID
+		}
+		response[i] = dto.OrderTaxLineResponse{
+			ProductID:     line.ProductID,
+			VariantID:     variantID,
+			TaxClass:      string(line.TaxClass),
+			Country:       line.Country,
+			Region:        line.Region,
+			TaxableAmount: line.TaxableAmount,
+			Rate:          line.Rate,
+			Tax:           line.Tax,
+		}
+	}
+	return response
+}
+
+// ListTaxRates handles GET /api/v1/tax-rates
+func (h *TaxHandler) ListTaxRates(c *gin.Context) {
+	rates, err := h.taxService.GetTaxRates(c.Query("country"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch tax rates",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.TaxRateResponse, len(rates))
+	for i, rate := range rates {
+		response[i] = toTaxRateResponse(rate)
+	}
+
+	c.JSON(http.StatusOK, dto.ListTaxRatesResponse{
+		Rates: response,
+		Count: len(response),
+	})
+}
+
+// CreateTaxRate handles POST /api/v1/tax-rates
+func (h *TaxHandler) CreateTaxRate(c *gin.Context) {
+	var req dto.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	rate, err := h.taxService.CreateTaxRate(toTaxRate(req))
+	if err != nil {
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to create tax rate",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to create tax rate",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTaxRateResponse(*rate))
+}
+
+// GetTaxRate handles GET /api/v1/tax-rates/:id
+func (h *TaxHandler) GetTaxRate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tax rate ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	rate, err := h.taxService.GetTaxRateByID(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Tax rate not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch tax rate",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaxRateResponse(*rate))
+}
+
+// UpdateTaxRate handles PUT /api/v1/tax-rates/:id
+func (h *TaxHandler) UpdateTaxRate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tax rate ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Fields:  validation.FieldErrors(err),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	rate, err := h.taxService.UpdateTaxRate(uint(id), toTaxRate(req))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Tax rate not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update tax rate",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to update tax rate",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaxRateResponse(*rate))
+}
+
+// DeleteTaxRate handles DELETE /api/v1/tax-rates/:id
+func (h *TaxHandler) DeleteTaxRate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tax rate ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.taxService.DeleteTaxRate(uint(id)); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Tax rate not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete tax rate",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Tax rate deleted successfully",
+	})
+}
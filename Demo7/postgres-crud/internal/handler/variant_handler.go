@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"net/http"
+	"postgres-crud/internal/dto"
+	"postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VariantHandler handles HTTP requests for product variants
+type VariantHandler struct {
+	variantService service.VariantService
+}
+
+// NewVariantHandler creates a new instance of VariantHandler
+func NewVariantHandler(variantService service.VariantService) *VariantHandler {
+	return &VariantHandler{
+		variantService: variantService,
+	}
+}
+
+// toVariantResponse converts a variant into its API representation
+func toVariantResponse(variant model.ProductVariant) dto.VariantResponse {
+	return dto.VariantResponse{
+		ID:        variant.ID,
+		ProductID: variant.ProductID,
+		SKU:       variant.SKU,
+		Options:   variant.Options,
+		Price:     variant.Price,
+		Currency:  variant.Currency,
+		Stock:     variant.Stock,
+		Version:   variant.Version,
+		CreatedAt: variant.CreatedAt,
+		UpdatedAt: variant.UpdatedAt,
+	}
+}
+
+// ListProductVariants handles GET /api/v1/products/:id/variants
+func (h *VariantHandler) ListProductVariants(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	variants, err := h.variantService.GetProductVariants(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch variants",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := make([]dto.VariantResponse, len(variants))
+	for i, variant := range variants {
+		response[i] = toVariantResponse(variant)
+	}
+
+	c.JSON(http.StatusOK, dto.ListVariantsResponse{
+		Variants: response,
+		Count:    len(response),
+	})
+}
+
+// CreateVariant handles POST /api/v1/products/:id/variants
+func (h *VariantHandler) CreateVariant(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid product ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if req.Price != nil {
+		req.Price.Currency = req.Currency
+	}
+	variant, err := h.variantService.CreateVariant(uint(id), req.SKU, req.Options, req.Price, req.Stock)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Product not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to create variant",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to create variant",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, variant.Version)
+	c.JSON(http.StatusCreated, toVariantResponse(*variant))
+}
+
+// GetVariant handles GET /api/v1/variants/:id
+func (h *VariantHandler) GetVariant(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid variant ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	variant, err := h.variantService.GetVariantByID(uint(id))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Variant not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch variant",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setETag(c, variant.Version)
+	c.JSON(http.StatusOK, toVariantResponse(*variant))
+}
+
+// GetVariantBySKU handles GET /api/v1/skus/:sku
+func (h *VariantHandler) GetVariantBySKU(c *gin.Context) {
+	variant, err := h.variantService.GetVariantBySKU(c.Param("sku"))
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "SKU not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to fetch SKU",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	response := toVariantResponse(*variant)
+	product := toProductResponse(variant.Product)
+	response.Product = &product
+
+	setETag(c, variant.Version)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateVariant handles PUT /api/v1/variants/:id
+func (h *VariantHandler) UpdateVariant(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid variant ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req dto.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if req.Price != nil {
+		req.Price.Currency = req.Currency
+	}
+	variant, err := h.variantService.UpdateVariant(uint(id), req.SKU, req.Options, req.Price, req.Stock, expectedVersion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Variant not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to update variant",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to update variant",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Failed to update variant",
+			Details: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	setETag(c, variant.Version)
+	c.JSON(http.StatusOK, toVariantResponse(*variant))
+}
+
+// DeleteVariant handles DELETE /api/v1/variants/:id
+func (h *VariantHandler) DeleteVariant(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid variant ID",
+			Code:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.variantService.DeleteVariant(uint(id), expectedVersion); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "Variant not found",
+				Code:  http.StatusNotFound,
+			})
+			return
+		}
+		if errors.IsPreconditionFailed(err) {
+			c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+				Error:   "Failed to delete variant",
+				Details: err.Error(),
+				Code:    http.StatusPreconditionFailed,
+			})
+			return
+		}
+		if errors.IsConflict(err) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Failed to delete variant",
+				Details: err.Error(),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to delete variant",
+			Details: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Variant deleted successfully",
+	})
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"postgres-crud/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminKeyHeader is the request header carrying the admin API key
+const AdminKeyHeader = "X-Admin-Key"
+
+// AdminOnly returns a gin middleware that only lets requests through when they
+// carry the configured admin API key. Without a configured key the admin
+// endpoints are disabled.
+func AdminOnly(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Admin API is disabled",
+				Details: "set ADMIN_API_KEY to enable it",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+
+		key := c.GetHeader(AdminKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Error: "Invalid or missing admin key",
+				Code:  http.StatusUnauthorized,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
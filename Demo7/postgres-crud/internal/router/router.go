@@ -1,23 +1,85 @@
 package router
 
 import (
+	"postgres-crud/config"
+	"postgres-crud/document"
 	"postgres-crud/internal/handler"
 	"postgres-crud/internal/middleware"
+	"postgres-crud/internal/validation"
 	"postgres-crud/repository"
 	"postgres-crud/service"
+
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRouter configures and returns the Gin router
-func SetupRouter() *gin.Engine {
+func SetupRouter(cfg *config.Config) *gin.Engine {
+	validation.Register()
+
 	// Initialize dependencies
+	uow := repository.NewUnitOfWork()
+
+	taxRepo := repository.NewTaxRepository()
+	taxService := service.NewTaxService(taxRepo, uow)
+	taxHandler := handler.NewTaxHandler(taxService)
+	pricingEngine := service.NewPricingEngine(service.NewTableTaxCalculator(taxRepo, cfg.Pricing.TaxRate))
+
+	customerRepo := repository.NewCustomerRepository()
+	addressRepo := repository.NewAddressRepository()
+	customerService := service.NewCustomerService(customerRepo, addressRepo, uow)
+
 	orderRepo := repository.NewOrderRepository()
-	orderService := service.NewOrderService(orderRepo)
+	refundHook := service.NoopRefundHook{}
+	orderService := service.NewOrderService(orderRepo, customerRepo, addressRepo, uow, pricingEngine, refundHook)
 	orderHandler := handler.NewOrderHandler(orderService)
+	returnRepo := repository.NewReturnRepository()
+	returnService := service.NewReturnService(returnRepo, orderRepo, uow, refundHook)
+	returnHandler := handler.NewReturnHandler(returnService)
+	invoiceRepo := repository.NewInvoiceRepository()
+	documentService := service.NewDocumentService(orderRepo, customerRepo, invoiceRepo, taxRepo, uow, document.NewRenderer(), service.DocumentSettings{
+		CompanyName:    cfg.Documents.CompanyName,
+		CompanyAddress: cfg.Documents.CompanyAddressLines(),
+		InvoicePrefix:  cfg.Documents.InvoicePrefix,
+	})
+	documentHandler := handler.NewDocumentHandler(documentService)
+	customerHandler := handler.NewCustomerHandler(customerService)
 
 	productRepo := repository.NewProductRepository()
-	productService := service.NewProductService(productRepo, orderRepo)
-	productHandler := handler.NewProductHandler(productService)
+	stockRepo := repository.NewStockMovementRepository()
+	priceHistoryRepo := repository.NewPriceHistoryRepository()
+	scheduleRepo := repository.NewScheduledPriceRepository()
+	productService := service.NewProductService(productRepo, orderRepo, priceHistoryRepo, scheduleRepo, uow)
+	scheduleService := service.NewScheduledPriceService(scheduleRepo, productRepo, uow)
+	scheduleHandler := handler.NewScheduledPriceHandler(scheduleService)
+	priceListRepo := repository.NewPriceListRepository()
+	exchangeRateRepo := repository.NewExchangeRateRepository()
+	currencyService := service.NewCurrencyService(priceListRepo, exchangeRateRepo, productRepo)
+	currencyHandler := handler.NewCurrencyHandler(currencyService)
+
+	productHandler := handler.NewProductHandler(productService, currencyService)
+	orderItemHandler := handler.NewOrderItemHandler(productService)
+
+	variantRepo := repository.NewVariantRepository()
+	variantService := service.NewVariantService(variantRepo, productRepo, uow)
+	variantHandler := handler.NewVariantHandler(variantService)
+
+	stockService := service.NewStockService(stockRepo, productRepo, variantRepo, uow)
+	stockHandler := handler.NewStockHandler(stockService)
+
+	categoryRepo := repository.NewCategoryRepository()
+	categoryService := service.NewCategoryService(categoryRepo, uow)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+
+	tagRepo := repository.NewTagRepository()
+	tagService := service.NewTagService(tagRepo, uow)
+	tagHandler := handler.NewTagHandler(tagService)
+
+	promotionRepo := repository.NewPromotionRepository()
+	promotionService := service.NewPromotionService(promotionRepo, uow)
+	promotionHandler := handler.NewPromotionHandler(promotionService, orderService)
+
+	trashService := service.NewTrashService(orderRepo, productRepo, uow)
+	trashHandler := handler.NewTrashHandler(trashService)
 
 	// Create router
 	r := gin.Default()
@@ -35,6 +97,8 @@ func SetupRouter() *gin.Engine {
 		})
 	})
 
+	adminOnly := middleware.AdminOnly(cfg.Admin.APIKey)
+
 	// API routes
 	api := r.Group("/api/v1")
 	{
@@ -46,11 +110,40 @@ func SetupRouter() *gin.Engine {
 			orders.GET("/:id", orderHandler.GetOrder)
 			orders.PUT("/:id", orderHandler.UpdateOrder)
 			orders.DELETE("/:id", orderHandler.DeleteOrder)
-			
+
+			// Order lifecycle routes
+			orders.POST("/:id/transitions", orderHandler.TransitionOrder)
+			orders.POST("/:id/cancel", orderHandler.CancelOrder)
+			orders.GET("/:id/transitions", orderHandler.GetOrderTransitions)
+
+			// Order document routes
+			orders.POST("/:id/invoice", adminOnly, documentHandler.IssueInvoice)
+			orders.GET("/:id/invoice", documentHandler.GetInvoice)
+			orders.GET("/:id/packing-slip", documentHandler.GetPackingSlip)
+
 			// Order-Product relationship routes
 			orders.POST("/:id/products", productHandler.AddProductToOrder)
 			orders.GET("/:id/products", productHandler.GetOrderProducts)
 			orders.DELETE("/:id/products/:productId", productHandler.RemoveProductFromOrder)
+
+			// Order line item routes
+			orders.GET("/:id/items", orderItemHandler.ListOrderItems)
+			orders.POST("/:id/items", orderItemHandler.AddOrderItem)
+			orders.GET("/:id/items/:productId", orderItemHandler.GetOrderItem)
+			orders.PUT("/:id/items/:productId", orderItemHandler.UpdateOrderItem)
+			orders.DELETE("/:id/items/:productId", orderItemHandler.DeleteOrderItem)
+
+			// Coupon routes
+			orders.POST("/:id/coupons", promotionHandler.ApplyCoupon)
+			orders.DELETE("/:id/coupons/:code", promotionHandler.RemoveCoupon)
+
+			// Return routes
+			orders.GET("/:id/returns", returnHandler.ListReturns)
+			orders.POST("/:id/returns", returnHandler.CreateReturn)
+			orders.GET("/:id/returns/:returnId", returnHandler.GetReturn)
+			orders.POST("/:id/returns/:returnId/approve", adminOnly, returnHandler.ApproveReturn)
+			orders.POST("/:id/returns/:returnId/receive", adminOnly, returnHandler.ReceiveReturn)
+			orders.POST("/:id/returns/:returnId/refund", adminOnly, returnHandler.RefundReturn)
 		}
 
 		// Product routes
@@ -61,12 +154,131 @@ func SetupRouter() *gin.Engine {
 			products.GET("/:id", productHandler.GetProduct)
 			products.PUT("/:id", productHandler.UpdateProduct)
 			products.DELETE("/:id", productHandler.DeleteProduct)
-			
+
 			// Get orders containing a specific product
 			products.GET("/:id/orders", orderHandler.GetOrdersByProduct)
+
+			// Stock ledger routes
+			products.GET("/:id/stock-history", stockHandler.GetStockHistory)
+			products.POST("/:id/stock-adjustments", stockHandler.AdjustStock)
+
+			// Variant routes
+			products.GET("/:id/variants", variantHandler.ListProductVariants)
+			products.POST("/:id/variants", variantHandler.CreateVariant)
+
+			// Price history routes
+			products.GET("/:id/prices", productHandler.GetPriceHistory)
+
+			// Scheduled price routes
+			products.GET("/:id/scheduled-prices", scheduleHandler.ListSchedules)
+			products.POST("/:id/scheduled-prices", adminOnly, scheduleHandler.CreateSchedule)
+
+			// Price list routes
+			products.GET("/:id/price-list", currencyHandler.GetPriceList)
+			products.PUT("/:id/price-list/:currency", adminOnly, currencyHandler.SetListPrice)
+			products.DELETE("/:id/price-list/:currency", adminOnly, currencyHandler.DeleteListPrice)
+		}
+
+		// Variant routes
+		variants := api.Group("/variants")
+		{
+			variants.GET("/:id", variantHandler.GetVariant)
+			variants.PUT("/:id", variantHandler.UpdateVariant)
+			variants.DELETE("/:id", variantHandler.DeleteVariant)
+			variants.GET("/:id/stock-history", stockHandler.GetVariantStockHistory)
+			variants.POST("/:id/stock-adjustments", stockHandler.AdjustVariantStock)
+		}
+
+		// Scheduled price routes
+		scheduledPrices := api.Group("/scheduled-prices")
+		{
+			scheduledPrices.GET("/:id", scheduleHandler.GetSchedule)
+			scheduledPrices.PUT("/:id", adminOnly, scheduleHandler.UpdateSchedule)
+			scheduledPrices.DELETE("/:id", adminOnly, scheduleHandler.DeleteSchedule)
+		}
+
+		// Customer routes
+		customers := api.Group("/customers")
+		{
+			customers.POST("", customerHandler.CreateCustomer)
+			customers.GET("", customerHandler.ListCustomers)
+			customers.GET("/:id", customerHandler.GetCustomer)
+			customers.PUT("/:id", customerHandler.UpdateCustomer)
+			customers.DELETE("/:id", customerHandler.DeleteCustomer)
+
+			// Saved address routes
+			customers.GET("/:id/addresses", customerHandler.ListAddresses)
+			customers.POST("/:id/addresses", customerHandler.AddAddress)
+			customers.GET("/:id/addresses/:addressId", customerHandler.GetAddress)
+			customers.PUT("/:id/addresses/:addressId", customerHandler.UpdateAddress)
+			customers.DELETE("/:id/addresses/:addressId", customerHandler.DeleteAddress)
+
+			// Get orders placed by a specific customer
+			customers.GET("/:id/orders", orderHandler.GetOrdersByCustomer)
+		}
+
+		// SKU lookup
+		api.GET("/skus/:sku", variantHandler.GetVariantBySKU)
+
+		// Category routes
+		categories := api.Group("/categories")
+		{
+			categories.POST("", categoryHandler.CreateCategory)
+			categories.GET("", categoryHandler.ListCategories)
+			categories.GET("/:id", categoryHandler.GetCategory)
+			categories.PUT("/:id", categoryHandler.UpdateCategory)
+			categories.DELETE("/:id", categoryHandler.DeleteCategory)
+		}
+
+		// Tag routes
+		tags := api.Group("/tags")
+		{
+			tags.POST("", tagHandler.CreateTag)
+			tags.GET("", tagHandler.ListTags)
+			tags.GET("/:id", tagHandler.GetTag)
+			tags.PUT("/:id", tagHandler.UpdateTag)
+			tags.DELETE("/:id", tagHandler.DeleteTag)
+		}
+
+		// Promotion routes
+		promotions := api.Group("/promotions", adminOnly)
+		{
+			promotions.POST("", promotionHandler.CreatePromotion)
+			promotions.GET("", promotionHandler.ListPromotions)
+			promotions.GET("/:id", promotionHandler.GetPromotion)
+			promotions.PUT("/:id", promotionHandler.UpdatePromotion)
+			promotions.DELETE("/:id", promotionHandler.DeletePromotion)
+		}
+
+		// Tax rate routes
+		taxRates := api.Group("/tax-rates")
+		{
+			taxRates.GET("", taxHandler.ListTaxRates)
+			taxRates.GET("/:id", taxHandler.GetTaxRate)
+			taxRates.POST("", adminOnly, taxHandler.CreateTaxRate)
+			taxRates.PUT("/:id", adminOnly, taxHandler.UpdateTaxRate)
+			taxRates.DELETE("/:id", adminOnly, taxHandler.DeleteTaxRate)
+		}
+
+		// Trash routes for soft-deleted orders and products
+		trash := api.Group("/trash")
+		{
+			trash.GET("/orders", trashHandler.ListDeletedOrders)
+			trash.POST("/orders/:id/restore", trashHandler.RestoreOrder)
+			trash.DELETE("/orders/:id", adminOnly, trashHandler.PurgeOrder)
+			trash.GET("/products", trashHandler.ListDeletedProducts)
+			trash.POST("/products/:id/restore", trashHandler.RestoreProduct)
+			trash.DELETE("/products/:id", adminOnly, trashHandler.PurgeProduct)
+		}
+
+		// Exchange rate routes
+		exchangeRates := api.Group("/exchange-rates")
+		{
+			exchangeRates.GET("", currencyHandler.ListExchangeRates)
+			exchangeRates.PUT("/:base/:quote", adminOnly, currencyHandler.SetExchangeRate)
+			exchangeRates.DELETE("/:base/:quote", adminOnly, currencyHandler.DeleteExchangeRate)
 		}
 	}
 
 	return r
 }
-
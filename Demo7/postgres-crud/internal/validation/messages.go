@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldErrors converts the validation errors of a request binding into
+// messages keyed by the JSON path of the offending field, such as
+// "shipping_address.postal_code". It returns nil for other errors.
+func FieldErrors(err error) map[string]string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make(map[string]string, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields[fieldPath(fe)] = fieldMessage(fe)
+	}
+	return fields
+}
+
+// fieldPath returns the path of a field below the request struct
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// fieldMessage describes a failed validation rule
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is not given", fe.Param())
+	case "excluded_with":
+		return fmt.Sprintf("cannot be given together with %s", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "email":
+		return "must be a valid email address"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code, such as US or DE"
+	case "postal_code":
+		return "is not a valid postal code for the country"
+	case "oneof":
+		return "must be one of " + fe.Param()
+	}
+	return fmt.Sprintf("failed the %s rule", fe.Tag())
+}
@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// postalCodePatterns holds the postal code format of countries whose format is
+// checked. Codes are matched after trimming and upper-casing.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^(GIR ?0AA|[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2})$`),
+	"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// genericPostalCode is the format accepted for countries without a pattern
+var genericPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{0,8}[A-Z0-9]$`)
+
+// IsPostalCode reports whether code is a valid postal code for country. A code
+// is required for countries with a known format and optional elsewhere.
+func IsPostalCode(code, country string) bool {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	country = strings.ToUpper(strings.TrimSpace(country))
+
+	if pattern, ok := postalCodePatterns[country]; ok {
+		return pattern.MatchString(code)
+	}
+	return code == "" || genericPostalCode.MatchString(code)
+}
+
+// validatePostalCode implements the postal_code tag. Its parameter names the
+// sibling field holding the country, as in `binding:"postal_code=Country"`.
+func validatePostalCode(fl validator.FieldLevel) bool {
+	parent := fl.Parent()
+	if parent.Kind() == reflect.Ptr {
+		parent = parent.Elem()
+	}
+	country := parent.FieldByName(fl.Param())
+	if !country.IsValid() || country.Kind() != reflect.String {
+		return false
+	}
+	return IsPostalCode(fl.Field().String(), country.String())
+}
@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"postgres-crud/money"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Register adds the custom types and rules used by the binding tags in
+// internal/dto to Gin's validator. Call it once before serving requests.
+func Register() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Money fields are validated on their amount in minor units, so tags such
+	// as `binding:"required,min=0"` work as they did for float prices
+	v.RegisterCustomTypeFunc(moneyAmount, money.Money{})
+	v.RegisterCustomTypeFunc(rateValue, money.Rate(0))
+
+	// Report fields by their JSON names so errors match the request body
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	_ = v.RegisterValidation("postal_code", validatePostalCode)
+}
+
+// IsCurrency reports whether code is an ISO 4217 currency code
+func IsCurrency(code string) bool {
+	return currencyValidator.Var(code, "required,iso4217") == nil
+}
+
+// currencyValidator checks values outside of request binding
+var currencyValidator = validator.New()
+
+// moneyAmount returns the value validated for a money.Money field
+func moneyAmount(field reflect.Value) interface{} {
+	if m, ok := field.Interface().(money.Money); ok {
+		return m.Amount
+	}
+	return nil
+}
+
+// rateValue returns the value validated for a money.Rate field
+func rateValue(field reflect.Value) interface{} {
+	if r, ok := field.Interface().(money.Rate); ok {
+		return int64(r)
+	}
+	return nil
+}
+
+// jsonFieldName returns the name of a struct field in JSON request bodies
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// PostalAddress holds the lines of a postal address. Country is an ISO 3166-1
+// alpha-2 code.
+type PostalAddress struct {
+	Name       string `json:"name" gorm:"type:varchar(255)"`
+	Line1      string `json:"line1" gorm:"type:varchar(255)"`
+	Line2      string `json:"line2" gorm:"type:varchar(255)"`
+	City       string `json:"city" gorm:"type:varchar(100)"`
+	Region     string `json:"region" gorm:"type:varchar(100)"`
+	PostalCode string `json:"postal_code" gorm:"type:varchar(20)"`
+	Country    string `json:"country" gorm:"type:char(2)"`
+}
+
+// IsZero reports whether no address has been set
+func (a PostalAddress) IsZero() bool {
+	return a == PostalAddress{}
+}
+
+// Address is an address saved on a customer account. Orders copy the address
+// they are shipped or billed to, so later edits do not change past orders.
+type Address struct {
+	ID         uint          `json:"id" gorm:"primaryKey;autoIncrement"`
+	CustomerID uint          `json:"customer_id" gorm:"not null;index"`
+	Label      string        `json:"label" gorm:"type:varchar(50)"`
+	Address    PostalAddress `json:"address" gorm:"embedded"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// TableName specifies the table name for Address model
+func (Address) TableName() string {
+	return "addresses"
+}
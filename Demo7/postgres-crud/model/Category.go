@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Category groups products. Categories form a tree of arbitrary depth through
+// ParentID; root categories have no parent.
+type Category struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string     `json:"name" gorm:"type:varchar(255);not null"`
+	Description string     `json:"description" gorm:"type:text"`
+	ParentID    *uint      `json:"parent_id" gorm:"index"`
+	Children    []Category `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Category model
+func (Category) TableName() string {
+	return "categories"
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Customer represents a customer account that places orders. Email addresses
+// are stored lower-cased and are unique among customers that are not deleted.
+type Customer struct {
+	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string         `json:"name" gorm:"type:varchar(255);not null"`
+	Email     string         `json:"email" gorm:"type:varchar(255);not null;index:idx_customers_email,unique,where:deleted_at IS NULL"`
+	Phone     string         `json:"phone" gorm:"type:varchar(50)"`
+	Orders    []Order        `json:"orders,omitempty" gorm:"foreignKey:CustomerID"`
+	Addresses []Address      `json:"addresses,omitempty" gorm:"foreignKey:CustomerID"`
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName specifies the table name for Customer model
+func (Customer) TableName() string {
+	return "customers"
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Invoice records the invoice issued for an order. Number is built from
+// Sequence, which is taken from the InvoiceSequence of the same name without
+// gaps. Invoices are kept when their order is purged.
+type Invoice struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID   uint      `json:"order_id" gorm:"not null;uniqueIndex"`
+	Number    string    `json:"number" gorm:"type:varchar(40);not null;uniqueIndex"`
+	Sequence  uint64    `json:"sequence" gorm:"not null"`
+	IssuedAt  time.Time `json:"issued_at" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Invoice model
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// InvoiceSequence is a counter that invoice numbers are taken from. LastValue
+// is the last number handed out; it only advances in the transaction that
+// records the invoice, so numbers are used without gaps.
+type InvoiceSequence struct {
+	Name      string `gorm:"type:varchar(40);primaryKey"`
+	LastValue uint64 `gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for InvoiceSequence model
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
@@ -1,22 +1,88 @@
 package model
 
 import (
+	"postgres-crud/money"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// Order represents an order entity in the database
+// OrderStatus represents a stage in the order lifecycle
+type OrderStatus string
+
+// Order lifecycle statuses
+const (
+	OrderStatusDraft     OrderStatus = "draft"
+	OrderStatusPlaced    OrderStatus = "placed"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// CancelReason records why an order was cancelled
+type CancelReason string
+
+// Order cancellation reasons
+const (
+	CancelReasonCustomerRequest CancelReason = "customer_request"
+	CancelReasonOutOfStock      CancelReason = "out_of_stock"
+	CancelReasonPaymentFailed   CancelReason = "payment_failed"
+	CancelReasonFraudSuspected  CancelReason = "fraud_suspected"
+	CancelReasonDuplicateOrder  CancelReason = "duplicate_order"
+	CancelReasonOther           CancelReason = "other"
+)
+
+// Order represents an order entity in the database.
+// Subtotal, DiscountTotal, PromotionDiscount, TaxRate, TaxTotal and GrandTotal
+// are calculated by the pricing engine and persisted when the order is placed,
+// in Currency, together with the TaxLines they were taxed with. DiscountTotal
+// includes PromotionDiscount; TaxRate is the effective rate over all lines.
+// CustomerID is the customer who placed the order; orders created before
+// customer accounts existed have none. ShippingAddress and BillingAddress are
+// copies taken when the order is created. Cancelled orders are kept with the
+// CancelReason, CancelNote and CancelledAt of their cancellation.
+// Version is incremented on every write and guards against lost updates.
 type Order struct {
-	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	Description string         `json:"description" gorm:"type:varchar(255);not null"`
-	Products    []Product      `json:"products,omitempty" gorm:"many2many:order_products;"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID                uint             `json:"id" gorm:"primaryKey;autoIncrement"`
+	CustomerID        *uint            `json:"customer_id" gorm:"index"`
+	Description       string           `json:"description" gorm:"type:varchar(255);not null"`
+	Status            OrderStatus      `json:"status" gorm:"type:varchar(20);not null;default:'draft';index"`
+	DiscountPercent   float64          `json:"discount_percent" gorm:"type:decimal(5,2);not null;default:0"`
+	Subtotal          money.Money      `json:"subtotal" gorm:"type:decimal(10,2);not null;default:0"`
+	DiscountTotal     money.Money      `json:"discount_total" gorm:"type:decimal(10,2);not null;default:0"`
+	PromotionDiscount money.Money      `json:"promotion_discount" gorm:"type:decimal(10,2);not null;default:0"`
+	TaxRate           float64          `json:"tax_rate" gorm:"type:decimal(5,2);not null;default:0"`
+	TaxTotal          money.Money      `json:"tax_total" gorm:"type:decimal(10,2);not null;default:0"`
+	GrandTotal        money.Money      `json:"grand_total" gorm:"type:decimal(10,2);not null;default:0"`
+	Currency          string           `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	ShippingAddress   PostalAddress    `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress    PostalAddress    `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+	PricedAt          *time.Time       `json:"priced_at,omitempty"`
+	CancelReason      CancelReason     `json:"cancel_reason,omitempty" gorm:"type:varchar(32)"`
+	CancelNote        string           `json:"cancel_note,omitempty" gorm:"type:text"`
+	CancelledAt       *time.Time       `json:"cancelled_at,omitempty"`
+	Products          []Product        `json:"products,omitempty" gorm:"many2many:order_products;"`
+	Items             []OrderProduct   `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+	Promotions        []OrderPromotion `json:"promotions,omitempty" gorm:"foreignKey:OrderID"`
+	TaxLines          []OrderTaxLine   `json:"tax_lines,omitempty" gorm:"foreignKey:OrderID"`
+	Version           uint             `json:"version" gorm:"not null;default:1"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt   `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // TableName specifies the table name for Order model
 func (Order) TableName() string {
 	return "orders"
 }
+
+// AfterFind restores the currency of the order totals from the order currency
+func (o *Order) AfterFind(tx *gorm.DB) error {
+	o.Subtotal.Currency = o.Currency
+	o.DiscountTotal.Currency = o.Currency
+	o.PromotionDiscount.Currency = o.Currency
+	o.TaxTotal.Currency = o.Currency
+	o.GrandTotal.Currency = o.Currency
+	return nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// OrderStatusTransition records a single status change of an order
+type OrderStatusTransition struct {
+	ID         uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID    uint        `json:"order_id" gorm:"not null;index"`
+	FromStatus OrderStatus `json:"from_status" gorm:"type:varchar(20);not null"`
+	ToStatus   OrderStatus `json:"to_status" gorm:"type:varchar(20);not null"`
+	Note       string      `json:"note,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderStatusTransition model
+func (OrderStatusTransition) TableName() string {
+	return "order_status_transitions"
+}
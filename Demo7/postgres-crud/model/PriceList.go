@@ -0,0 +1,54 @@
+package model
+
+import (
+	"postgres-crud/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceListEntry is the price of a product in a specific currency. It takes
+// precedence over converting the product's own price with an exchange rate.
+type PriceListEntry struct {
+	ID        uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID uint        `json:"product_id" gorm:"not null;uniqueIndex:idx_price_list_product_currency"`
+	Currency  string      `json:"currency" gorm:"type:char(3);not null;uniqueIndex:idx_price_list_product_currency"`
+	Price     money.Money `json:"price" gorm:"type:decimal(10,2);not null"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// TableName specifies the table name for PriceListEntry model
+func (PriceListEntry) TableName() string {
+	return "price_list_entries"
+}
+
+// BeforeSave stores the price currency in its own column
+func (e *PriceListEntry) BeforeSave(tx *gorm.DB) error {
+	if e.Price.Currency != "" {
+		e.Currency = e.Price.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the price currency from its column
+func (e *PriceListEntry) AfterFind(tx *gorm.DB) error {
+	e.Price.Currency = e.Currency
+	return nil
+}
+
+// ExchangeRate is the admin-maintained rate for converting amounts from
+// BaseCurrency to QuoteCurrency: 1 BaseCurrency = Rate QuoteCurrency
+type ExchangeRate struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	BaseCurrency  string     `json:"base_currency" gorm:"type:char(3);not null;uniqueIndex:idx_exchange_rate_pair"`
+	QuoteCurrency string     `json:"quote_currency" gorm:"type:char(3);not null;uniqueIndex:idx_exchange_rate_pair"`
+	Rate          money.Rate `json:"rate" gorm:"type:decimal(18,8);not null"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for ExchangeRate model
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
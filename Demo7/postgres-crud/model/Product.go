@@ -1,22 +1,48 @@
 package model
 
 import (
+	"postgres-crud/money"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// Product represents a product entity in the database
+// Product represents a product entity in the database. Version is incremented
+// on every write, including stock changes, and guards against lost updates.
+// Price is stored as a decimal; its currency is kept in Currency. A product
+// belongs to at most one category and carries any number of tags. TaxClass
+// selects the tax rate charged on it. ScheduledPrice is not stored: it is set
+// when a scheduled price has replaced Price for the time of the read.
 type Product struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name        string         `json:"name" gorm:"type:varchar(255);not null"`
 	Description string         `json:"description" gorm:"type:text"`
-	Price       float64        `json:"price" gorm:"type:decimal(10,2);not null"`
+	Price       money.Money    `json:"price" gorm:"type:decimal(10,2);not null"`
+	Currency    string         `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
 	Stock       int            `json:"stock" gorm:"type:int;default:0"`
+	TaxClass    TaxClass       `json:"tax_class" gorm:"type:varchar(20);not null;default:'standard'"`
+	CategoryID  *uint          `json:"category_id" gorm:"index"`
+	Tags        []Tag          `json:"tags,omitempty" gorm:"many2many:product_tags;"`
 	Orders      []Order        `json:"orders,omitempty" gorm:"many2many:order_products;"`
+	Version     uint           `json:"version" gorm:"not null;default:1"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+
+	ScheduledPrice *ScheduledPrice `json:"-" gorm:"-"`
+	RegularPrice   money.Money     `json:"-" gorm:"-"`
+}
+
+// ApplyScheduledPrice replaces the price of the product with a scheduled
+// price, keeping the regular price in RegularPrice. Schedules in another
+// currency than the product's are ignored, as is a second schedule.
+func (p *Product) ApplyScheduledPrice(schedule *ScheduledPrice) {
+	if schedule == nil || p.ScheduledPrice != nil || schedule.Currency != p.Currency {
+		return
+	}
+	p.RegularPrice = p.Price
+	p.Price = schedule.Price
+	p.ScheduledPrice = schedule
 }
 
 // TableName specifies the table name for Product model
@@ -24,13 +50,40 @@ func (Product) TableName() string {
 	return "products"
 }
 
-// OrderProduct represents the join table for Order-Product many-to-many relationship
+// BeforeSave stores the price currency in its own column
+func (p *Product) BeforeSave(tx *gorm.DB) error {
+	if p.Price.Currency != "" {
+		p.Currency = p.Price.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the price currency from its column
+func (p *Product) AfterFind(tx *gorm.DB) error {
+	p.Price.Currency = p.Currency
+	return nil
+}
+
+// OrderProduct represents the join table for Order-Product many-to-many
+// relationship. Each line is for one variant of a product; VariantID is 0 for
+// products sold without variants. The line keeps a snapshot of the product's
+// name, description, SKU and tax class so that later catalog changes do not
+// alter it.
 type OrderProduct struct {
-	OrderID   uint      `gorm:"primaryKey"`
-	ProductID uint      `gorm:"primaryKey"`
-	Quantity  int       `gorm:"type:int;not null;default:1"`
-	Price     float64   `gorm:"type:decimal(10,2);not null"` // Price at time of order
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	OrderID            uint        `gorm:"primaryKey"`
+	ProductID          uint        `gorm:"primaryKey"`
+	VariantID          uint        `gorm:"primaryKey;default:0"`
+	SKU                string      `gorm:"type:varchar(64)"`                      // Variant SKU when added
+	ProductName        string      `gorm:"type:varchar(255);not null;default:''"` // Product name when added
+	ProductDescription string      `gorm:"type:text"`                             // Product description when added
+	TaxClass           TaxClass    `gorm:"type:varchar(20);not null;default:''"`  // Product tax class when added
+	Quantity           int         `gorm:"type:int;not null;default:1"`
+	Price              money.Money `gorm:"type:decimal(10,2);not null"`           // Price at time of order
+	Currency           string      `gorm:"type:char(3);not null;default:'USD'"`   // Currency of Price
+	ExchangeRate       money.Rate  `gorm:"type:decimal(18,8);not null;default:1"` // Product currency to Currency when added
+	DiscountPercent    float64     `gorm:"type:decimal(5,2);not null;default:0"`  // Line discount in percent
+	Product            Product     `gorm:"foreignKey:ProductID"`
+	CreatedAt          time.Time   `gorm:"autoCreateTime"`
 }
 
 // TableName specifies the table name for OrderProduct model
@@ -38,4 +91,21 @@ func (OrderProduct) TableName() string {
 	return "order_products"
 }
 
+// BeforeSave stores the price currency in its own column
+func (op *OrderProduct) BeforeSave(tx *gorm.DB) error {
+	if op.Price.Currency != "" {
+		op.Currency = op.Price.Currency
+	}
+	return nil
+}
 
+// AfterFind restores the price currency from its column
+func (op *OrderProduct) AfterFind(tx *gorm.DB) error {
+	op.Price.Currency = op.Currency
+	return nil
+}
+
+// LineTotal returns the captured price multiplied by the ordered quantity
+func (op OrderProduct) LineTotal() money.Money {
+	return op.Price.Multiply(op.Quantity)
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"postgres-crud/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductPrice is an entry in the price history of a product. A new entry is
+// recorded whenever the product's price or currency changes; the price holds
+// from EffectiveFrom until the EffectiveFrom of the next entry.
+type ProductPrice struct {
+	ID            uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID     uint        `json:"product_id" gorm:"not null;index:idx_product_prices_product_from"`
+	Price         money.Money `json:"price" gorm:"type:decimal(10,2);not null"`
+	Currency      string      `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	EffectiveFrom time.Time   `json:"effective_from" gorm:"not null;index:idx_product_prices_product_from"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for ProductPrice model
+func (ProductPrice) TableName() string {
+	return "product_prices"
+}
+
+// BeforeSave stores the price currency in its own column
+func (p *ProductPrice) BeforeSave(tx *gorm.DB) error {
+	if p.Price.Currency != "" {
+		p.Currency = p.Price.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the price currency from its column
+func (p *ProductPrice) AfterFind(tx *gorm.DB) error {
+	p.Price.Currency = p.Currency
+	return nil
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"postgres-crud/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VariantOptions holds the option values that tell the variants of a product
+// apart, e.g. {"size": "M", "colour": "red"}. It is stored as a JSON object.
+type VariantOptions map[string]string
+
+// Value implements driver.Valuer, storing the options as a JSON object
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, reading a JSON object
+func (o *VariantOptions) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case nil:
+		*o = VariantOptions{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into VariantOptions", src)
+	}
+	return json.Unmarshal(data, o)
+}
+
+// ProductVariant is a sellable version of a product, such as one size or
+// colour, identified by a unique SKU. It has its own stock and may override
+// the product's price; a nil Price means the product price applies. Version is
+// incremented on every write, including stock changes.
+type ProductVariant struct {
+	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID uint           `json:"product_id" gorm:"not null;index"`
+	Product   Product        `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	SKU       string         `json:"sku" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Options   VariantOptions `json:"options" gorm:"type:jsonb;not null;default:'{}'"`
+	Price     *money.Money   `json:"price" gorm:"type:decimal(10,2)"`
+	Currency  string         `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	Stock     int            `json:"stock" gorm:"type:int;not null;default:0"`
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName specifies the table name for ProductVariant model
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// BeforeSave stores the price override currency in its own column
+func (v *ProductVariant) BeforeSave(tx *gorm.DB) error {
+	if v.Price != nil && v.Price.Currency != "" {
+		v.Currency = v.Price.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the price override currency from its column
+func (v *ProductVariant) AfterFind(tx *gorm.DB) error {
+	if v.Price != nil {
+		v.Price.Currency = v.Currency
+	}
+	return nil
+}
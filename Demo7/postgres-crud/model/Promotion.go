@@ -0,0 +1,114 @@
+package model
+
+import (
+	"postgres-crud/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PromotionType selects how a promotion calculates its discount
+type PromotionType string
+
+// Promotion types
+const (
+	PromotionTypePercentage  PromotionType = "percentage"
+	PromotionTypeFixedAmount PromotionType = "fixed_amount"
+	PromotionTypeBuyXGetY    PromotionType = "buy_x_get_y"
+)
+
+// Promotion is a discount rule. Promotions with a Code are coupons that are
+// applied to orders explicitly; promotions without one apply automatically to
+// every order they are eligible for.
+//
+// A percentage promotion takes PercentOff off the order, a fixed amount
+// promotion takes AmountOff off it, and a buy X get Y promotion makes
+// GetQuantity of every BuyQuantity + GetQuantity units of ProductID free.
+// AmountOff and MinOrderValue are in Currency. A promotion that is not
+// Stackable is never combined with other promotions.
+type Promotion struct {
+	ID                    uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name                  string         `json:"name" gorm:"type:varchar(255);not null"`
+	Code                  string         `json:"code" gorm:"type:varchar(50);index:idx_promotions_code,unique,where:code <> '' AND deleted_at IS NULL"`
+	Type                  PromotionType  `json:"type" gorm:"type:varchar(20);not null"`
+	PercentOff            float64        `json:"percent_off" gorm:"type:decimal(5,2);not null;default:0"`
+	AmountOff             money.Money    `json:"amount_off" gorm:"type:decimal(10,2);not null;default:0"`
+	MinOrderValue         money.Money    `json:"min_order_value" gorm:"type:decimal(10,2);not null;default:0"`
+	Currency              string         `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	ProductID             *uint          `json:"product_id" gorm:"index"`
+	BuyQuantity           int            `json:"buy_quantity" gorm:"not null;default:0"`
+	GetQuantity           int            `json:"get_quantity" gorm:"not null;default:0"`
+	UsageLimitPerCustomer int            `json:"usage_limit_per_customer" gorm:"not null;default:0"`
+	StartsAt              *time.Time     `json:"starts_at"`
+	EndsAt                *time.Time     `json:"ends_at"`
+	Stackable             bool           `json:"stackable" gorm:"not null"`
+	Priority              int            `json:"priority" gorm:"not null;default:0"`
+	Active                bool           `json:"active" gorm:"not null"`
+	Version               uint           `json:"version" gorm:"not null;default:1"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName specifies the table name for Promotion model
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// IsCoupon reports whether the promotion has to be applied with its code
+func (p Promotion) IsCoupon() bool {
+	return p.Code != ""
+}
+
+// BeforeSave stores the currency of the promotion amounts in its own column
+func (p *Promotion) BeforeSave(tx *gorm.DB) error {
+	if p.AmountOff.Currency != "" {
+		p.Currency = p.AmountOff.Currency
+	} else if p.MinOrderValue.Currency != "" {
+		p.Currency = p.MinOrderValue.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the currency of the promotion amounts from its column
+func (p *Promotion) AfterFind(tx *gorm.DB) error {
+	p.AmountOff.Currency = p.Currency
+	p.MinOrderValue.Currency = p.Currency
+	return nil
+}
+
+// OrderPromotion records a promotion on an order together with the discount
+// it gave when the order was last evaluated. Coupons stay on the order while
+// they do not apply, with Applied false and the Reason; automatic promotions
+// are only recorded while they apply.
+type OrderPromotion struct {
+	OrderID     uint        `json:"order_id" gorm:"primaryKey"`
+	PromotionID uint        `json:"promotion_id" gorm:"primaryKey"`
+	Promotion   Promotion   `json:"-" gorm:"foreignKey:PromotionID"`
+	Code        string      `json:"code" gorm:"type:varchar(50)"`
+	Name        string      `json:"name" gorm:"type:varchar(255);not null"`
+	Applied     bool        `json:"applied" gorm:"not null"`
+	Reason      string      `json:"reason" gorm:"type:varchar(255)"`
+	Discount    money.Money `json:"discount" gorm:"type:decimal(10,2);not null;default:0"`
+	Currency    string      `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderPromotion model
+func (OrderPromotion) TableName() string {
+	return "order_promotions"
+}
+
+// BeforeSave stores the currency of the discount in its own column
+func (p *OrderPromotion) BeforeSave(tx *gorm.DB) error {
+	if p.Discount.Currency != "" {
+		p.Currency = p.Discount.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the currency of the discount from its column
+func (p *OrderPromotion) AfterFind(tx *gorm.DB) error {
+	p.Discount.Currency = p.Currency
+	return nil
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"postgres-crud/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReturnStatus represents a stage in the return workflow
+type ReturnStatus string
+
+// Return workflow statuses
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusReceived  ReturnStatus = "received"
+	ReturnStatusRefunded  ReturnStatus = "refunded"
+)
+
+// Return records items of a delivered order that the customer sends back.
+// RefundAmount is the sum of the line amounts, in Currency, the order's
+// currency. Restocked reports whether the returned units were put back into
+// stock when the return was received. ApprovedAt, ReceivedAt and RefundedAt are
+// set as the return reaches each status. Version is incremented on every write.
+type Return struct {
+	ID           uint         `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID      uint         `json:"order_id" gorm:"not null;index"`
+	Status       ReturnStatus `json:"status" gorm:"type:varchar(20);not null;default:'requested';index"`
+	Reason       string       `json:"reason" gorm:"type:varchar(255);not null"`
+	Note         string       `json:"note" gorm:"type:text"`
+	RefundAmount money.Money  `json:"refund_amount" gorm:"type:decimal(10,2);not null;default:0"`
+	Currency     string       `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	Restocked    bool         `json:"restocked" gorm:"not null;default:false"`
+	Lines        []ReturnLine `json:"lines" gorm:"foreignKey:ReturnID"`
+	ApprovedAt   *time.Time   `json:"approved_at,omitempty"`
+	ReceivedAt   *time.Time   `json:"received_at,omitempty"`
+	RefundedAt   *time.Time   `json:"refunded_at,omitempty"`
+	Version      uint         `json:"version" gorm:"not null;default:1"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for Return model
+func (Return) TableName() string {
+	return "returns"
+}
+
+// BeforeSave stores the refund currency in its own column
+func (r *Return) BeforeSave(tx *gorm.DB) error {
+	if r.RefundAmount.Currency != "" {
+		r.Currency = r.RefundAmount.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the refund currency from its column
+func (r *Return) AfterFind(tx *gorm.DB) error {
+	r.RefundAmount.Currency = r.Currency
+	return nil
+}
+
+// ReturnLine is a returned quantity of one order line, identified like the
+// line itself by OrderID, ProductID and VariantID. UnitPrice is the price
+// captured on the order line and Amount the refund for the returned quantity:
+// its share of the line's taxable amount and tax.
+type ReturnLine struct {
+	ID        uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReturnID  uint        `json:"return_id" gorm:"not null;index"`
+	OrderID   uint        `json:"order_id" gorm:"not null;index:idx_return_lines_order_line"`
+	ProductID uint        `json:"product_id" gorm:"not null;index:idx_return_lines_order_line"`
+	VariantID uint        `json:"variant_id" gorm:"not null;default:0;index:idx_return_lines_order_line"`
+	SKU       string      `json:"sku,omitempty" gorm:"type:varchar(64)"`
+	Quantity  int         `json:"quantity" gorm:"type:int;not null"`
+	UnitPrice money.Money `json:"unit_price" gorm:"type:decimal(10,2);not null"`
+	Amount    money.Money `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Currency  string      `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for ReturnLine model
+func (ReturnLine) TableName() string {
+	return "return_lines"
+}
+
+// BeforeSave stores the price currency in its own column
+func (l *ReturnLine) BeforeSave(tx *gorm.DB) error {
+	if l.UnitPrice.Currency != "" {
+		l.Currency = l.UnitPrice.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the price currency from its column
+func (l *ReturnLine) AfterFind(tx *gorm.DB) error {
+	l.UnitPrice.Currency = l.Currency
+	l.Amount.Currency = l.Currency
+	return nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"postgres-crud/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledPrice is a price that replaces a product's regular price from
+// StartsAt until EndsAt, or indefinitely when EndsAt is nil. Schedules of the
+// same product never overlap, so at most one is in effect at any time. A
+// schedule only applies while its currency matches the product's.
+type ScheduledPrice struct {
+	ID        uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID uint        `json:"product_id" gorm:"not null;index:idx_scheduled_prices_product_starts"`
+	Price     money.Money `json:"price" gorm:"type:decimal(10,2);not null"`
+	Currency  string      `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	StartsAt  time.Time   `json:"starts_at" gorm:"not null;index:idx_scheduled_prices_product_starts"`
+	EndsAt    *time.Time  `json:"ends_at"`
+	Note      string      `json:"note" gorm:"type:varchar(255)"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// TableName specifies the table name for ScheduledPrice model
+func (ScheduledPrice) TableName() string {
+	return "scheduled_prices"
+}
+
+// BeforeSave stores the price currency in its own column
+func (s *ScheduledPrice) BeforeSave(tx *gorm.DB) error {
+	if s.Price.Currency != "" {
+		s.Currency = s.Price.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the price currency from its column
+func (s *ScheduledPrice) AfterFind(tx *gorm.DB) error {
+	s.Price.Currency = s.Currency
+	return nil
+}
+
+// ActiveAt reports whether the schedule is in effect at the given time
+func (s ScheduledPrice) ActiveAt(at time.Time) bool {
+	return !at.Before(s.StartsAt) && (s.EndsAt == nil || at.Before(*s.EndsAt))
+}
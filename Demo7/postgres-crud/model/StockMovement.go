@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// StockMovementReason explains why a product's stock changed
+type StockMovementReason string
+
+// Stock movement reasons recorded by the system
+const (
+	StockReasonInitial          StockMovementReason = "initial_stock"
+	StockReasonProductUpdate    StockMovementReason = "product_update"
+	StockReasonOrderLineAdded   StockMovementReason = "order_line_added"
+	StockReasonOrderLineChanged StockMovementReason = "order_line_changed"
+	StockReasonOrderLineRemoved StockMovementReason = "order_line_removed"
+	StockReasonOrderCancelled   StockMovementReason = "order_cancelled"
+	StockReasonOrderDeleted     StockMovementReason = "order_deleted"
+	StockReasonOrderRestored    StockMovementReason = "order_restored"
+	StockReasonOrderReturned    StockMovementReason = "order_returned"
+)
+
+// Stock movement reason codes accepted for manual adjustments
+const (
+	StockReasonRestock    StockMovementReason = "restock"
+	StockReasonDamaged    StockMovementReason = "damaged"
+	StockReasonLost       StockMovementReason = "lost"
+	StockReasonFound      StockMovementReason = "found"
+	StockReasonCorrection StockMovementReason = "correction"
+)
+
+// Stock movement reference types
+const (
+	StockReferenceOrder      = "order"
+	StockReferenceProduct    = "product"
+	StockReferenceAdjustment = "adjustment"
+)
+
+// StockActorSystem is the actor recorded for movements caused by order processing
+const StockActorSystem = "system"
+
+// StockMovement is an append-only ledger entry recording a change to the stock
+// of a product or, when VariantID is set, of one of its variants.
+// Product.Stock always equals the sum of the product's movement deltas without
+// a variant, and ProductVariant.Stock the sum of the variant's.
+type StockMovement struct {
+	ID            uint                `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID     uint                `json:"product_id" gorm:"not null;index"`
+	VariantID     *uint               `json:"variant_id,omitempty" gorm:"index"`
+	Delta         int                 `json:"delta" gorm:"type:int;not null"`
+	Reason        StockMovementReason `json:"reason" gorm:"type:varchar(40);not null"`
+	ReferenceType string              `json:"reference_type" gorm:"type:varchar(20);not null"`
+	ReferenceID   *uint               `json:"reference_id,omitempty"`
+	Actor         string              `json:"actor" gorm:"type:varchar(100);not null"`
+	Note          string              `json:"note,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time           `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for StockMovement model
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
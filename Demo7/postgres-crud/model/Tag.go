@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Tag is a free-form label attached to any number of products. Names are
+// stored lower-cased and are unique.
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Tag model
+func (Tag) TableName() string {
+	return "tags"
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"postgres-crud/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaxClass determines which tax rate applies to a product
+type TaxClass string
+
+// Product tax classes
+const (
+	TaxClassStandard TaxClass = "standard"
+	TaxClassReduced  TaxClass = "reduced"
+	TaxClassExempt   TaxClass = "exempt"
+)
+
+// TaxRate is an admin-maintained tax rate for a tax class in a destination.
+// Country is an ISO 3166-1 alpha-2 code; an empty Region applies to the whole
+// country and is overridden by a rate for the order's region. The rate applies
+// from EffectiveFrom until EffectiveTo, or indefinitely when EffectiveTo is
+// nil. Rate is a percentage (8.25 means 8.25%).
+type TaxRate struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Country       string     `json:"country" gorm:"type:char(2);not null;uniqueIndex:idx_tax_rates_destination_class_from"`
+	Region        string     `json:"region" gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_tax_rates_destination_class_from"`
+	TaxClass      TaxClass   `json:"tax_class" gorm:"type:varchar(20);not null;uniqueIndex:idx_tax_rates_destination_class_from"`
+	Rate          float64    `json:"rate" gorm:"type:decimal(5,2);not null"`
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"not null;uniqueIndex:idx_tax_rates_destination_class_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for TaxRate model
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+// OrderTaxLine is the tax charged on one order line, persisted when the order
+// is placed. The rate and destination are copied so that the tax on an order
+// never changes when the rate tables do. Rate is a percentage.
+type OrderTaxLine struct {
+	ID            uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID       uint        `json:"order_id" gorm:"not null;index"`
+	ProductID     uint        `json:"product_id" gorm:"not null"`
+	VariantID     uint        `json:"variant_id" gorm:"not null;default:0"`
+	TaxClass      TaxClass    `json:"tax_class" gorm:"type:varchar(20);not null"`
+	Country       string      `json:"country" gorm:"type:char(2)"`
+	Region        string      `json:"region" gorm:"type:varchar(100)"`
+	TaxableAmount money.Money `json:"taxable_amount" gorm:"type:decimal(10,2);not null"`
+	Rate          float64     `json:"rate" gorm:"type:decimal(5,2);not null"`
+	Tax           money.Money `json:"tax" gorm:"type:decimal(10,2);not null"`
+	Currency      string      `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderTaxLine model
+func (OrderTaxLine) TableName() string {
+	return "order_tax_lines"
+}
+
+// BeforeSave stores the currency of the amounts in its own column
+func (l *OrderTaxLine) BeforeSave(tx *gorm.DB) error {
+	if l.Tax.Currency != "" {
+		l.Currency = l.Tax.Currency
+	}
+	return nil
+}
+
+// AfterFind restores the currency of the amounts from its column
+func (l *OrderTaxLine) AfterFind(tx *gorm.DB) error {
+	l.TaxableAmount.Currency = l.Currency
+	l.Tax.Currency = l.Currency
+	return nil
+}
@@ -0,0 +1,240 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when no currency is given
+const DefaultCurrency = "USD"
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are
+// combined
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// scale is the number of minor units in a major unit. All supported currencies
+// have two decimal places, matching the decimal(10,2) price columns.
+const scale = 100
+
+// Money is an exact monetary amount held in minor units (cents) together with
+// its ISO 4217 currency code.
+//
+// In the database only the amount is stored, as a decimal; the currency lives
+// in a separate column of the owning table. In JSON and query parameters the
+// amount is written as a decimal string such as "12.50".
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New creates an amount from minor units
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal string such as "12.5" or "-0.99" into an amount. More
+// than two decimal places is an error rather than being rounded.
+func Parse(value, currency string) (Money, error) {
+	amount, err := parseDecimal(value, 2)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// parseDecimal reads a decimal string into an integer scaled by 10^places
+func parseDecimal(value string, places int) (int64, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(fraction) > places {
+		return 0, fmt.Errorf("amount %q has more than %d decimal places", value, places)
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", places-len(fraction))
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", value, err)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// isDigits reports whether s only holds ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// formatDecimal formats an integer scaled by 10^places as a decimal string
+func formatDecimal(n int64, places int) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	digits := fmt.Sprintf("%0*d", places+1, n)
+	return sign + digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+}
+
+// String formats the amount as a decimal string with two decimal places
+func (m Money) String() string {
+	return formatDecimal(m.Amount, 2)
+}
+
+// Add returns the sum of two amounts. Amounts in different currencies cannot
+// be added and return ErrCurrencyMismatch.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of two amounts. Amounts in different currencies
+// cannot be subtracted and return ErrCurrencyMismatch.
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// sameCurrency returns ErrCurrencyMismatch unless other is in the currency of m
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+// Multiply returns the amount multiplied by a quantity
+func (m Money) Multiply(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Value implements driver.Valuer, storing the amount as a decimal string
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan implements sql.Scanner, reading a decimal column. The currency is left
+// untouched; it is filled in from the owning record.
+func (m *Money) Scan(src interface{}) error {
+	var parsed Money
+	var err error
+	switch v := src.(type) {
+	case string:
+		parsed, err = Parse(trimZeros(v, 2), m.Currency)
+	case []byte:
+		parsed, err = Parse(trimZeros(string(v), 2), m.Currency)
+	case int64:
+		parsed = Money{Amount: v * scale, Currency: m.Currency}
+	case float64:
+		parsed, err = Parse(strconv.FormatFloat(v, 'f', 2, 64), m.Currency)
+	case nil:
+		parsed = Money{Currency: m.Currency}
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// trimZeros drops trailing zeros past the given number of decimal places,
+// which numeric columns of a larger scale (or aggregates) may return
+func trimZeros(s string, places int) string {
+	whole, fraction, found := strings.Cut(s, ".")
+	if !found || len(fraction) <= places {
+		return s
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) < places {
+		fraction += strings.Repeat("0", places-len(fraction))
+	}
+	return whole + "." + fraction
+}
+
+// MarshalJSON writes the amount as a decimal string
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON reads a decimal string. Plain JSON numbers are accepted too,
+// using their literal digits, so no floating point rounding takes place.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid amount %s", data)
+		}
+		s = n.String()
+	}
+
+	parsed, err := Parse(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalParam reads a decimal string from a query or form parameter
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := Parse(param, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,210 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"12.50", 1250, false},
+		{"12.5", 1250, false},
+		{"12", 1200, false},
+		{"0.99", 99, false},
+		{".5", 50, false},
+		{"7.", 700, false},
+		{"-0.99", -99, false},
+		{"+3.01", 301, false},
+		{" 4.20 ", 420, false},
+		{"", 0, true},
+		{".", 0, true},
+		{"-", 0, true},
+		{"1.234", 0, true},
+		{"1,50", 0, true},
+		{"1e3", 0, true},
+		{"--1", 0, true},
+		{"99999999999999999999", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value, "EUR")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.value, err)
+			}
+			if got != New(tt.want, "EUR") {
+				t.Errorf("Parse(%q) = %+v, want %d EUR", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{99, "0.99"},
+		{1250, "12.50"},
+		{-5, "-0.05"},
+		{-1250, "-12.50"},
+	}
+
+	for _, tt := range tests {
+		if got := New(tt.amount, "USD").String(); got != tt.want {
+			t.Errorf("New(%d).String() = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    int64
+		wantErr bool
+	}{
+		{"string", "12.50", 1250, false},
+		{"bytes", []byte("0.99"), 99, false},
+		{"larger scale", "3.1000", 310, false},
+		{"aggregate", []byte("-7.250000000000000000"), -725, false},
+		{"integer", int64(42), 4200, false},
+		{"float", 19.99, 1999, false},
+		{"null", nil, 0, false},
+		{"significant extra digits", "1.005", 0, true},
+		{"garbage", "abc", 0, true},
+		{"unsupported type", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Money{Amount: 1, Currency: "GBP"}
+			err := m.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Scan(%v) = %+v, want an error", tt.src, m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v) error = %v", tt.src, err)
+			}
+			if m != New(tt.want, "GBP") {
+				t.Errorf("Scan(%v) = %+v, want %d GBP", tt.src, m, tt.want)
+			}
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	value, err := New(-1205, "USD").Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	if value != "-12.05" {
+		t.Errorf("Value() = %v, want -12.05", value)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, amount := range []int64{0, 1, 99, 1250, -1, -1250, 123456789} {
+		data, err := json.Marshal(New(amount, "USD"))
+		if err != nil {
+			t.Fatalf("Marshal(%d) error = %v", amount, err)
+		}
+
+		got := Money{Currency: "USD"}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", data, err)
+		}
+		if got != New(amount, "USD") {
+			t.Errorf("round trip of %d through %s = %+v", amount, data, got)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    int64
+		wantErr bool
+	}{
+		{`"12.50"`, 1250, false},
+		{`12.5`, 1250, false},
+		{`0.1`, 10, false},
+		{`-3`, -300, false},
+		{`null`, 7, false},
+		{`"12.345"`, 0, true},
+		{`1.005`, 0, true},
+		{`1e2`, 0, true},
+		{`true`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			m := Money{Amount: 7, Currency: "USD"}
+			err := json.Unmarshal([]byte(tt.data), &m)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %+v, want an error", tt.data, m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.data, err)
+			}
+			if m != New(tt.want, "USD") {
+				t.Errorf("Unmarshal(%s) = %+v, want %d USD", tt.data, m, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	a := New(1250, "USD")
+	b := New(-300, "USD")
+
+	sum, err := a.Add(b)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if sum != New(950, "USD") {
+		t.Errorf("Add() = %+v, want 9.50 USD", sum)
+	}
+
+	difference, err := a.Sub(b)
+	if err != nil {
+		t.Fatalf("Sub() error = %v", err)
+	}
+	if difference != New(1550, "USD") {
+		t.Errorf("Sub() = %+v, want 15.50 USD", difference)
+	}
+}
+
+func TestAddSubCurrencyMismatch(t *testing.T) {
+	usd := New(1000, "USD")
+	eur := New(1000, "EUR")
+
+	if got, err := usd.Add(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() = %+v, %v; want ErrCurrencyMismatch", got, err)
+	}
+	if got, err := usd.Sub(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub() = %+v, %v; want ErrCurrencyMismatch", got, err)
+	}
+	if got, err := usd.Add(New(1, "")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() without a currency = %+v, %v; want ErrCurrencyMismatch", got, err)
+	}
+}
@@ -0,0 +1,134 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// rateDecimals is the number of decimal places kept for exchange rates,
+// matching the decimal(18,8) rate columns
+const rateDecimals = 8
+
+// rateScale is the integer value of a rate of exactly 1
+const rateScale = 100000000
+
+// Rate is an exchange rate with eight decimal places, held as an integer so
+// conversions are exact up to the final rounding
+type Rate int64
+
+// OneRate is the rate of a currency to itself
+const OneRate Rate = rateScale
+
+// ParseRate reads a decimal string such as "1.0825" into a rate
+func ParseRate(value string) (Rate, error) {
+	n, err := parseDecimal(value, rateDecimals)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 {
+		return 0, fmt.Errorf("exchange rate %q must be greater than zero", value)
+	}
+	return Rate(n), nil
+}
+
+// String formats the rate as a decimal string with eight decimal places
+func (r Rate) String() string {
+	return formatDecimal(int64(r), rateDecimals)
+}
+
+// Inverse returns the rate for converting in the opposite direction, rounded
+// half away from zero to eight decimal places
+func (r Rate) Inverse() Rate {
+	if r <= 0 {
+		return 0
+	}
+	return Rate(divRound(big.NewInt(rateScale*rateScale), big.NewInt(int64(r))))
+}
+
+// Convert converts an amount into another currency at the given rate. The
+// result is rounded half away from zero to the minor unit.
+func (m Money) Convert(rate Rate, currency string) Money {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(int64(rate)))
+	return Money{Amount: divRound(product, big.NewInt(rateScale)), Currency: currency}
+}
+
+// divRound divides a by a positive b, rounding half away from zero
+func divRound(a, b *big.Int) int64 {
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(b) >= 0 {
+		if a.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
+
+// Value implements driver.Valuer, storing the rate as a decimal string
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// Scan implements sql.Scanner, reading a decimal column
+func (r *Rate) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		*r = Rate(v * rateScale)
+		return nil
+	case float64:
+		s = strconv.FormatFloat(v, 'f', rateDecimals, 64)
+	case nil:
+		*r = 0
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Rate", src)
+	}
+
+	n, err := parseDecimal(trimZeros(s, rateDecimals), rateDecimals)
+	if err != nil {
+		return err
+	}
+	*r = Rate(n)
+	return nil
+}
+
+// MarshalJSON writes the rate as a decimal string
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON reads a rate from a decimal string or a JSON number
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid exchange rate %s", data)
+		}
+		s = n.String()
+	}
+
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+)
+
+// AddressRepository defines the interface for saved customer address data
+// operations
+type AddressRepository interface {
+	Create(address *model.Address) error
+	GetByID(id uint) (*model.Address, error)
+	GetByCustomerID(customerID uint) ([]model.Address, error)
+	Update(address *model.Address) error
+	Delete(address *model.Address) error
+}
+
+// addressRepository implements AddressRepository interface
+type addressRepository struct {
+	db *gorm.DB
+}
+
+// NewAddressRepository creates a new instance of AddressRepository
+func NewAddressRepository() AddressRepository {
+	return &addressRepository{
+		db: database.DB,
+	}
+}
+
+// Create inserts a new address into the database
+func (r *addressRepository) Create(address *model.Address) error {
+	if err := r.db.Create(address).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves an address by its ID
+func (r *addressRepository) GetByID(id uint) (*model.Address, error) {
+	var address model.Address
+	if err := r.db.First(&address, id).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// GetByCustomerID retrieves the saved addresses of a customer, oldest first
+func (r *addressRepository) GetByCustomerID(customerID uint) ([]model.Address, error) {
+	var addresses []model.Address
+	if err := r.db.Where("customer_id = ?", customerID).
+		Order("id").
+		Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// Update writes all fields of an existing address
+func (r *addressRepository) Update(address *model.Address) error {
+	if err := r.db.Save(address).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes an address
+func (r *addressRepository) Delete(address *model.Address) error {
+	result := r.db.Delete(address)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
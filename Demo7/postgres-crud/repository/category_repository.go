@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+)
+
+// descendantCategoriesSQL selects the ID of a category and of every category
+// below it in the tree
+const descendantCategoriesSQL = `WITH RECURSIVE tree AS (
+	SELECT id FROM categories WHERE id = ?
+	UNION ALL
+	SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+) SELECT id FROM tree`
+
+// CategoryRepository defines the interface for category data operations
+type CategoryRepository interface {
+	Create(category *model.Category) error
+	GetByID(id uint) (*model.Category, error)
+	LockTree() error
+	GetAll() ([]model.Category, error)
+	Update(category *model.Category) error
+	Delete(id uint) error
+	HasChildren(id uint) (bool, error)
+	GetDescendantIDs(id uint) ([]uint, error)
+	DetachProducts(id uint) error
+}
+
+// categoryRepository implements CategoryRepository interface
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository creates a new instance of CategoryRepository
+func NewCategoryRepository() CategoryRepository {
+	return &categoryRepository{
+		db: database.DB,
+	}
+}
+
+// Create inserts a new category into the database
+func (r *categoryRepository) Create(category *model.Category) error {
+	if err := r.db.Create(category).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a category by its ID together with its direct children
+func (r *categoryRepository) GetByID(id uint) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.Preload("Children", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// LockTree takes a transaction-scoped advisory lock on the category tree,
+// keyed on the categories table. Transactions that move categories take it
+// first, so one move's cycle check cannot be overtaken by another move.
+func (r *categoryRepository) LockTree() error {
+	if err := r.db.Exec("SELECT pg_advisory_xact_lock('categories'::regclass::oid::bigint)").Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves all categories, ordered by name
+func (r *categoryRepository) GetAll() ([]model.Category, error) {
+	var categories []model.Category
+	if err := r.db.Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// Update writes the name, description and parent of an existing category
+func (r *categoryRepository) Update(category *model.Category) error {
+	result := r.db.Model(category).
+		Select("name", "description", "parent_id").
+		Updates(category)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete removes a category by ID
+func (r *categoryRepository) Delete(id uint) error {
+	result := r.db.Delete(&model.Category{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// HasChildren reports whether a category has subcategories
+func (r *categoryRepository) HasChildren(id uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Category{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetDescendantIDs retrieves the ID of a category and of all categories below
+// it, at any depth
+func (r *categoryRepository) GetDescendantIDs(id uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Raw(descendantCategoriesSQL, id).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// DetachProducts removes the category from all products in it, including
+// soft-deleted ones, and increments their versions
+func (r *categoryRepository) DetachProducts(id uint) error {
+	if err := r.db.Unscoped().Model(&model.Product{}).
+		Where("category_id = ?", id).
+		Updates(map[string]interface{}{
+			"category_id": nil,
+			"version":     gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerRepository defines the interface for customer data operations
+type CustomerRepository interface {
+	Create(customer *model.Customer) error
+	GetByID(id uint) (*model.Customer, error)
+	GetByIDForUpdate(id uint) (*model.Customer, error)
+	GetAll() ([]model.Customer, error)
+	EmailExists(email string, excludeID uint) (bool, error)
+	HasOrders(id uint) (bool, error)
+	Update(customer *model.Customer) error
+	DeleteByModel(customer *model.Customer) error
+}
+
+// customerRepository implements CustomerRepository interface
+type customerRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomerRepository creates a new instance of CustomerRepository
+func NewCustomerRepository() CustomerRepository {
+	return &customerRepository{
+		db: database.DB,
+	}
+}
+
+// Create inserts a new customer into the database
+func (r *customerRepository) Create(customer *model.Customer) error {
+	if err := r.db.Omit(clause.Associations).Create(customer).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a customer by its ID
+func (r *customerRepository) GetByID(id uint) (*model.Customer, error) {
+	var customer model.Customer
+	if err := r.db.First(&customer, id).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetByIDForUpdate retrieves a customer by ID and locks its row until the end
+// of the transaction, serialising checks that span the customer's orders
+func (r *customerRepository) GetByIDForUpdate(id uint) (*model.Customer, error) {
+	var customer model.Customer
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, id).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetAll retrieves all customers, ordered by name
+func (r *customerRepository) GetAll() ([]model.Customer, error) {
+	var customers []model.Customer
+	if err := r.db.Order("name, id").Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+// EmailExists reports whether a customer other than excludeID uses email
+func (r *customerRepository) EmailExists(email string, excludeID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Customer{}).
+		Where("email = ? AND id <> ?", email, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// HasOrders reports whether a customer has placed any orders that are not
+// deleted
+func (r *customerRepository) HasOrders(id uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Order{}).
+		Where("customer_id = ?", id).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Update writes an existing customer if its version still matches the one it
+// was loaded with, and increments the version. Returns
+// ErrConcurrentModification if the customer was changed in the meantime.
+func (r *customerRepository) Update(customer *model.Customer) error {
+	version := customer.Version
+	customer.Version++
+	result := r.db.Model(customer).
+		Where("version = ?", version).
+		Select("*").
+		Omit("created_at", "deleted_at", clause.Associations).
+		Updates(customer)
+	if result.Error != nil {
+		customer.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		customer.Version = version
+		return ErrConcurrentModification
+	}
+	return nil
+}
+
+// DeleteByModel removes a customer using the model instance, provided its
+// version still matches. Returns ErrConcurrentModification if it does not.
+func (r *customerRepository) DeleteByModel(customer *model.Customer) error {
+	result := r.db.Where("version = ?", customer.Version).Delete(customer)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
+	}
+	return nil
+}
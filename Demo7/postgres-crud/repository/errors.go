@@ -0,0 +1,11 @@
+package repository
+
+import "errors"
+
+// ErrConcurrentModification is returned when a conditional update affects no
+// rows because the record was changed by another request in the meantime
+var ErrConcurrentModification = errors.New("record was modified concurrently")
+
+// ErrInsufficientStock is returned when a stock change would take a product's
+// stock below zero
+var ErrInsufficientStock = errors.New("insufficient stock")
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepository defines the interface for exchange rate data operations
+type ExchangeRateRepository interface {
+	GetAll() ([]model.ExchangeRate, error)
+	GetRate(base, quote string) (*model.ExchangeRate, error)
+	Upsert(rate *model.ExchangeRate) error
+	Delete(base, quote string) error
+}
+
+// exchangeRateRepository implements ExchangeRateRepository interface
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository creates a new instance of ExchangeRateRepository
+func NewExchangeRateRepository() ExchangeRateRepository {
+	return &exchangeRateRepository{
+		db: database.DB,
+	}
+}
+
+// GetAll retrieves all exchange rates, ordered by currency pair
+func (r *exchangeRateRepository) GetAll() ([]model.ExchangeRate, error) {
+	var rates []model.ExchangeRate
+	if err := r.db.Order("base_currency, quote_currency").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// GetRate retrieves the rate for converting from base to quote
+func (r *exchangeRateRepository) GetRate(base, quote string) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	if err := r.db.Where("base_currency = ? AND quote_currency = ?", base, quote).
+		First(&rate).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// Upsert creates the rate of a currency pair, or replaces the existing one
+func (r *exchangeRateRepository) Upsert(rate *model.ExchangeRate) error {
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes the rate of a currency pair
+func (r *exchangeRateRepository) Delete(base, quote string) error {
+	result := r.db.Where("base_currency = ? AND quote_currency = ?", base, quote).
+		Delete(&model.ExchangeRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+)
+
+// InvoiceRepository defines the interface for invoice data operations
+type InvoiceRepository interface {
+	Create(invoice *model.Invoice) error
+	GetByOrderID(orderID uint) (*model.Invoice, error)
+	NextSequence(name string) (uint64, error)
+}
+
+// invoiceRepository implements InvoiceRepository interface
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+// NewInvoiceRepository creates a new instance of InvoiceRepository
+func NewInvoiceRepository() InvoiceRepository {
+	return &invoiceRepository{
+		db: database.DB,
+	}
+}
+
+// Create records an invoice
+func (r *invoiceRepository) Create(invoice *model.Invoice) error {
+	if err := r.db.Create(invoice).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByOrderID retrieves the invoice issued for an order
+func (r *invoiceRepository) GetByOrderID(orderID uint) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := r.db.Where("order_id = ?", orderID).First(&invoice).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// NextSequence advances the named invoice sequence, creating it at 1, and
+// returns the new value. The sequence row stays locked until the end of the
+// transaction, and the increment is undone with it, so it must run in the
+// unit of work that records the invoice.
+func (r *invoiceRepository) NextSequence(name string) (uint64, error) {
+	var value uint64
+	err := r.db.Raw(`
+		INSERT INTO invoice_sequences (name, last_value) VALUES (?, 1)
+		ON CONFLICT (name) DO UPDATE SET last_value = invoice_sequences.last_value + 1
+		RETURNING last_value`, name).Scan(&value).Error
+	if err != nil {
+		return 0, err
+	}
+	return value, nil
+}
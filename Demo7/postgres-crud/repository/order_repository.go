@@ -3,23 +3,41 @@ package repository
 import (
 	"postgres-crud/database"
 	"postgres-crud/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // OrderRepository defines the interface for order data operations
 type OrderRepository interface {
 	Create(order *model.Order) error
 	GetByID(id uint) (*model.Order, error)
+	GetByIDForUpdate(id uint) (*model.Order, error)
 	GetAll() ([]model.Order, error)
 	GetByCondition(condition string, args ...interface{}) ([]model.Order, error)
 	Update(order *model.Order) error
 	UpdateField(id uint, field string, value interface{}) error
+	IncrementVersion(order *model.Order) error
 	Delete(id uint) error
 	DeleteByModel(order *model.Order) error
 	GetOrdersByProductID(productID uint) ([]model.Order, error)
+	GetOrdersByCustomerID(customerID uint, withProducts bool) ([]model.Order, error)
 	GetOrdersWithProducts() ([]model.Order, error)
 	GetByIDWithProducts(id uint) (*model.Order, error)
+	GetItems(orderID uint) ([]model.OrderProduct, error)
+	GetItem(orderID uint, productID uint, variantID uint) (*model.OrderProduct, error)
+	UpdateStatus(order *model.Order, from model.OrderStatus, columns ...string) error
+	CreateTransition(transition *model.OrderStatusTransition) error
+	GetTransitions(orderID uint) ([]model.OrderStatusTransition, error)
+	GetDeleted() ([]model.Order, error)
+	GetDeletedByID(id uint) (*model.Order, error)
+	Restore(order *model.Order) error
+	Purge(id uint) error
+	GetDeletedIDsBefore(cutoff time.Time, limit int) ([]uint, error)
+	CountDeletedBefore(cutoff time.Time) (orders int64, lines int64, err error)
+	PurgeBatch(ids []uint) (lines int64, err error)
+	BackfillLineSnapshots() (int64, error)
 }
 
 // orderRepository implements OrderRepository interface
@@ -51,6 +69,16 @@ func (r *orderRepository) GetByID(id uint) (*model.Order, error) {
 	return &order, nil
 }
 
+// GetByIDForUpdate retrieves an order by ID and locks its row until the end of
+// the transaction, serialising changes that depend on the order
+func (r *orderRepository) GetByIDForUpdate(id uint) (*model.Order, error) {
+	var order model.Order
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 // GetAll retrieves all orders from the database
 func (r *orderRepository) GetAll() ([]model.Order, error) {
 	var orders []model.Order
@@ -69,22 +97,56 @@ func (r *orderRepository) GetByCondition(condition string, args ...interface{})
 	return orders, nil
 }
 
-// Update updates an existing order
+// Update writes an existing order if its version still matches the one it was
+// loaded with, and increments the version. Returns ErrConcurrentModification if
+// the order was changed in the meantime.
 func (r *orderRepository) Update(order *model.Order) error {
-	if err := r.db.Save(order).Error; err != nil {
-		return err
+	version := order.Version
+	order.Version++
+	result := r.db.Model(order).
+		Where("version = ?", version).
+		Select("*").
+		Omit("created_at", "deleted_at", clause.Associations).
+		Updates(order)
+	if result.Error != nil {
+		order.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		order.Version = version
+		return ErrConcurrentModification
 	}
 	return nil
 }
 
-// UpdateField updates a specific field of an order
+// UpdateField updates a specific field of an order and increments its version
 func (r *orderRepository) UpdateField(id uint, field string, value interface{}) error {
-	if err := r.db.Model(&model.Order{}).Where("id = ?", id).Update(field, value).Error; err != nil {
+	if err := r.db.Model(&model.Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+		field:     value,
+		"version": gorm.Expr("version + 1"),
+	}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// IncrementVersion increments the version of an order whose line items or
+// promotions changed, so that its ETag changes with them. Returns
+// ErrConcurrentModification if the order was changed in the meantime.
+func (r *orderRepository) IncrementVersion(order *model.Order) error {
+	result := r.db.Model(&model.Order{}).
+		Where("id = ? AND version = ?", order.ID, order.Version).
+		Update("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
+	}
+	order.Version++
+	return nil
+}
+
 // Delete removes an order by ID
 func (r *orderRepository) Delete(id uint) error {
 	if err := r.db.Delete(&model.Order{}, id).Error; err != nil {
@@ -93,10 +155,15 @@ func (r *orderRepository) Delete(id uint) error {
 	return nil
 }
 
-// DeleteByModel removes an order using the model instance
+// DeleteByModel removes an order using the model instance, provided its version
+// still matches. Returns ErrConcurrentModification if it does not.
 func (r *orderRepository) DeleteByModel(order *model.Order) error {
-	if err := r.db.Delete(order).Error; err != nil {
-		return err
+	result := r.db.Where("version = ?", order.Version).Delete(order)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
 	}
 	return nil
 }
@@ -114,6 +181,20 @@ func (r *orderRepository) GetOrdersByProductID(productID uint) ([]model.Order, e
 	return orders, nil
 }
 
+// GetOrdersByCustomerID retrieves the orders placed by a customer, newest
+// first, optionally with their associated products
+func (r *orderRepository) GetOrdersByCustomerID(customerID uint, withProducts bool) ([]model.Order, error) {
+	var orders []model.Order
+	query := r.db.Where("customer_id = ?", customerID)
+	if withProducts {
+		query = query.Preload("Products")
+	}
+	if err := query.Order("created_at DESC, id DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
 // GetOrdersWithProducts retrieves all orders with their associated products
 func (r *orderRepository) GetOrdersWithProducts() ([]model.Order, error) {
 	var orders []model.Order
@@ -126,9 +207,216 @@ func (r *orderRepository) GetOrdersWithProducts() ([]model.Order, error) {
 // GetByIDWithProducts retrieves an order by ID with its associated products
 func (r *orderRepository) GetByIDWithProducts(id uint) (*model.Order, error) {
 	var order model.Order
-	if err := r.db.Preload("Products").First(&order, id).Error; err != nil {
+	if err := r.db.Preload("Products").Preload("Items.Product").Preload("Promotions").Preload("TaxLines").First(&order, id).Error; err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
+// GetItems retrieves the line items (order_products rows) of an order
+func (r *orderRepository) GetItems(orderID uint) ([]model.OrderProduct, error) {
+	var items []model.OrderProduct
+	if err := r.db.Preload("Product").
+		Where("order_id = ?", orderID).
+		Order("created_at").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetItem retrieves a single line item of an order by product and variant ID
+func (r *orderRepository) GetItem(orderID uint, productID uint, variantID uint) (*model.OrderProduct, error) {
+	var item model.OrderProduct
+	if err := r.db.Preload("Product").
+		Where("order_id = ? AND product_id = ? AND variant_id = ?", orderID, productID, variantID).
+		First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateStatus writes the status of an order, and any additional columns,
+// only while the order still has the expected status and version. The version
+// is incremented.
+func (r *orderRepository) UpdateStatus(order *model.Order, from model.OrderStatus, columns ...string) error {
+	version := order.Version
+	order.Version++
+	result := r.db.Model(order).
+		Where("status = ? AND version = ?", from, version).
+		Select(append([]string{"status", "version"}, columns...)).
+		Updates(order)
+	if result.Error != nil {
+		order.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		order.Version = version
+		return ErrConcurrentModification
+	}
+	return nil
+}
+
+// CreateTransition records a status change of an order
+func (r *orderRepository) CreateTransition(transition *model.OrderStatusTransition) error {
+	if err := r.db.Create(transition).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetTransitions retrieves the status history of an order, oldest first
+func (r *orderRepository) GetTransitions(orderID uint) ([]model.OrderStatusTransition, error) {
+	var transitions []model.OrderStatusTransition
+	if err := r.db.Where("order_id = ?", orderID).
+		Order("created_at, id").
+		Find(&transitions).Error; err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// GetDeleted retrieves the soft-deleted orders, most recently deleted first
+func (r *orderRepository) GetDeleted() ([]model.Order, error) {
+	var orders []model.Order
+	if err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetDeletedByID retrieves a soft-deleted order by its ID
+func (r *orderRepository) GetDeletedByID(id uint) (*model.Order, error) {
+	var order model.Order
+	if err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// Restore clears the deletion mark of a soft-deleted order and increments its
+// version. Returns ErrConcurrentModification if the order was restored or
+// changed in the meantime.
+func (r *orderRepository) Restore(order *model.Order) error {
+	result := r.db.Unscoped().Model(&model.Order{}).
+		Where("id = ? AND version = ? AND deleted_at IS NOT NULL", order.ID, order.Version).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
+	}
+	order.DeletedAt = gorm.DeletedAt{}
+	order.Version++
+	return nil
+}
+
+// Purge permanently removes a soft-deleted order together with its line items,
+// status history, promotions, tax lines and returns. Stock movements are kept
+// as part of the stock ledger. Returns gorm.ErrRecordNotFound if the order is
+// not soft-deleted.
+func (r *orderRepository) Purge(id uint) error {
+	if _, err := r.GetDeletedByID(id); err != nil {
+		return err
+	}
+	_, err := r.PurgeBatch([]uint{id})
+	return err
+}
+
+// GetDeletedIDsBefore retrieves the IDs of up to limit orders that were
+// soft-deleted before cutoff, oldest deletion first
+func (r *orderRepository) GetDeletedIDsBefore(cutoff time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Unscoped().Model(&model.Order{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Order("deleted_at, id").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CountDeletedBefore counts the orders soft-deleted before cutoff and their
+// line items
+func (r *orderRepository) CountDeletedBefore(cutoff time.Time) (int64, int64, error) {
+	var orders, lines int64
+	if err := r.db.Unscoped().Model(&model.Order{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Count(&orders).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.Model(&model.OrderProduct{}).
+		Where("order_id IN (?)", r.db.Unscoped().Model(&model.Order{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)).
+		Count(&lines).Error; err != nil {
+		return 0, 0, err
+	}
+	return orders, lines, nil
+}
+
+// PurgeBatch permanently removes soft-deleted orders together with their line
+// items, status history, promotions, tax lines and returns, and returns the
+// number of line items removed. Orders in ids that are not soft-deleted are
+// left alone.
+func (r *orderRepository) PurgeBatch(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	deleted := r.db.Unscoped().Model(&model.Order{}).
+		Select("id").
+		Where("id IN ? AND deleted_at IS NOT NULL", ids)
+
+	result := r.db.Where("order_id IN (?)", deleted).Delete(&model.OrderProduct{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	lines := result.RowsAffected
+
+	dependents := []interface{}{
+		&model.OrderStatusTransition{},
+		&model.OrderPromotion{},
+		&model.OrderTaxLine{},
+		&model.ReturnLine{},
+		&model.Return{},
+	}
+	for _, dependent := range dependents {
+		if err := r.db.Where("order_id IN (?)", deleted).Delete(dependent).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	if err := r.db.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Delete(&model.Order{}).Error; err != nil {
+		return 0, err
+	}
+	return lines, nil
+}
+
+// BackfillLineSnapshots copies the current name, description and tax class of
+// the product onto order lines that were added before lines kept a snapshot of
+// them. It returns the number of lines updated.
+func (r *orderRepository) BackfillLineSnapshots() (int64, error) {
+	result := r.db.Exec(`
+		UPDATE order_products SET
+			product_name = CASE WHEN order_products.product_name = '' THEN p.name ELSE order_products.product_name END,
+			product_description = CASE WHEN order_products.product_name = '' THEN p.description ELSE order_products.product_description END,
+			tax_class = CASE WHEN order_products.tax_class = '' THEN p.tax_class ELSE order_products.tax_class END
+		FROM products p
+		WHERE p.id = order_products.product_id
+			AND (order_products.product_name = '' OR order_products.tax_class = '')`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
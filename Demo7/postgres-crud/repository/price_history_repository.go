@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceHistoryRepository defines the interface for product price history data
+// operations
+type PriceHistoryRepository interface {
+	Record(entry *model.ProductPrice) error
+	GetByProductID(productID uint) ([]model.ProductPrice, error)
+	GetAt(productID uint, at time.Time) (*model.ProductPrice, error)
+	BackfillInitialPrices() (int64, error)
+}
+
+// priceHistoryRepository implements PriceHistoryRepository interface
+type priceHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceHistoryRepository creates a new instance of PriceHistoryRepository
+func NewPriceHistoryRepository() PriceHistoryRepository {
+	return &priceHistoryRepository{
+		db: database.DB,
+	}
+}
+
+// Record appends an entry to a product's price history
+func (r *priceHistoryRepository) Record(entry *model.ProductPrice) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByProductID retrieves the price history of a product, oldest first
+func (r *priceHistoryRepository) GetByProductID(productID uint) ([]model.ProductPrice, error) {
+	var entries []model.ProductPrice
+	if err := r.db.Where("product_id = ?", productID).
+		Order("effective_from, id").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetAt retrieves the price history entry of a product in effect at a given
+// time
+func (r *priceHistoryRepository) GetAt(productID uint, at time.Time) (*model.ProductPrice, error) {
+	var entry model.ProductPrice
+	if err := r.db.Where("product_id = ? AND effective_from <= ?", productID, at).
+		Order("effective_from DESC, id DESC").
+		First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// BackfillInitialPrices records the current price of every product that has
+// no price history yet, effective from the product's creation, so that
+// products created before the history existed can be looked up. It returns the
+// number of entries recorded.
+func (r *priceHistoryRepository) BackfillInitialPrices() (int64, error) {
+	result := r.db.Exec(`
+		INSERT INTO product_prices (product_id, price, currency, effective_from, created_at)
+		SELECT p.id, p.price, p.currency, p.created_at, NOW()
+		FROM products p
+		WHERE NOT EXISTS (SELECT 1 FROM product_prices h WHERE h.product_id = p.id)`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriceListRepository defines the interface for per-currency product prices
+type PriceListRepository interface {
+	GetByProductID(productID uint) ([]model.PriceListEntry, error)
+	GetPrice(productID uint, currency string) (*model.PriceListEntry, error)
+	Upsert(entry *model.PriceListEntry) error
+	Delete(productID uint, currency string) error
+}
+
+// priceListRepository implements PriceListRepository interface
+type priceListRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceListRepository creates a new instance of PriceListRepository
+func NewPriceListRepository() PriceListRepository {
+	return &priceListRepository{
+		db: database.DB,
+	}
+}
+
+// GetByProductID retrieves all list prices of a product, ordered by currency
+func (r *priceListRepository) GetByProductID(productID uint) ([]model.PriceListEntry, error) {
+	var entries []model.PriceListEntry
+	if err := r.db.Where("product_id = ?", productID).
+		Order("currency").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetPrice retrieves the list price of a product in a currency
+func (r *priceListRepository) GetPrice(productID uint, currency string) (*model.PriceListEntry, error) {
+	var entry model.PriceListEntry
+	if err := r.db.Where("product_id = ? AND currency = ?", productID, currency).
+		First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Upsert creates the list price of a product in a currency, or replaces the
+// existing one
+func (r *priceListRepository) Upsert(entry *model.PriceListEntry) error {
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "updated_at"}),
+	}).Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes the list price of a product in a currency
+func (r *priceListRepository) Delete(productID uint, currency string) error {
+	result := r.db.Where("product_id = ? AND currency = ?", productID, currency).
+		Delete(&model.PriceListEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -3,25 +3,61 @@ package repository
 import (
 	"postgres-crud/database"
 	"postgres-crud/model"
+	"postgres-crud/money"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ProductFilter holds the criteria for FilterProducts. Zero values and nil
+// pointers are ignored; all given criteria must match.
+type ProductFilter struct {
+	Name        string
+	Description string
+	MinPrice    *money.Money
+	MaxPrice    *money.Money
+	MinStock    *int
+	MaxStock    *int
+	CategoryID  *uint     // Matches the category and all categories below it
+	Tags        []string  // Matches products carrying every one of these tags
+	PricedAt    time.Time // Price filters use the scheduled price in effect at this time, if any
+}
+
+// effectivePriceSQL is the price of a product at a given time (bound twice):
+// the price of the scheduled price in effect then, or the regular price
+const effectivePriceSQL = `COALESCE((
+	SELECT sp.price FROM scheduled_prices sp
+	WHERE sp.product_id = products.id AND sp.currency = products.currency
+	AND sp.starts_at <= ? AND (sp.ends_at IS NULL OR sp.ends_at > ?)
+	LIMIT 1), products.price)`
+
 // ProductRepository defines the interface for product data operations
 type ProductRepository interface {
 	Create(product *model.Product) error
 	GetByID(id uint) (*model.Product, error)
+	GetByIDForUpdate(id uint) (*model.Product, error)
 	GetAll() ([]model.Product, error)
 	GetByCondition(condition string, args ...interface{}) ([]model.Product, error)
 	Update(product *model.Product) error
 	UpdateField(id uint, field string, value interface{}) error
+	AdjustStock(id uint, delta int) error
 	Delete(id uint) error
 	DeleteByModel(product *model.Product) error
 	GetProductsByOrderID(orderID uint) ([]model.Product, error)
-	AddProductToOrder(orderID uint, productID uint, quantity int, price float64) error
-	RemoveProductFromOrder(orderID uint, productID uint) error
-	FilterProducts(name, description string, minPrice, maxPrice *float64, minStock, maxStock *int) ([]model.Product, error)
+	AddProductToOrder(line *model.OrderProduct) error
+	RemoveProductFromOrder(orderID uint, productID uint, variantID uint) error
+	UpdateOrderItem(item *model.OrderProduct) error
+	FilterProducts(filter ProductFilter) ([]model.Product, error)
 	GetProductsWithOrders() ([]model.Product, error)
+	GetDeleted() ([]model.Product, error)
+	GetDeletedByID(id uint) (*model.Product, error)
+	CountOrderLines(id uint) (int64, error)
+	Restore(product *model.Product) error
+	Purge(id uint) error
+	GetPurgeableIDsBefore(cutoff time.Time, limit int) ([]uint, error)
+	CountDeletedBefore(cutoff time.Time) (purgeable int64, kept int64, err error)
+	PurgeBatch(ids []uint) error
 }
 
 // productRepository implements ProductRepository interface
@@ -47,7 +83,17 @@ func (r *productRepository) Create(product *model.Product) error {
 // GetByID retrieves a product by its ID
 func (r *productRepository) GetByID(id uint) (*model.Product, error) {
 	var product model.Product
-	if err := r.db.First(&product, id).Error; err != nil {
+	if err := r.db.Preload("Tags").First(&product, id).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetByIDForUpdate retrieves a product by ID and locks its row until the end of
+// the transaction, serialising changes that depend on the product
+func (r *productRepository) GetByIDForUpdate(id uint) (*model.Product, error) {
+	var product model.Product
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
@@ -56,7 +102,7 @@ func (r *productRepository) GetByID(id uint) (*model.Product, error) {
 // GetAll retrieves all products from the database
 func (r *productRepository) GetAll() ([]model.Product, error) {
 	var products []model.Product
-	if err := r.db.Find(&products).Error; err != nil {
+	if err := r.db.Preload("Tags").Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
@@ -71,22 +117,64 @@ func (r *productRepository) GetByCondition(condition string, args ...interface{}
 	return products, nil
 }
 
-// Update updates an existing product
+// Update writes an existing product if its version still matches the one it was
+// loaded with, and increments the version. Stock is not written; it only
+// changes through stock movements. Returns ErrConcurrentModification if the
+// product was changed in the meantime.
 func (r *productRepository) Update(product *model.Product) error {
-	if err := r.db.Save(product).Error; err != nil {
-		return err
+	version := product.Version
+	product.Version++
+	result := r.db.Model(product).
+		Where("version = ?", version).
+		Select("*").
+		Omit("stock", "created_at", "deleted_at", clause.Associations).
+		Updates(product)
+	if result.Error != nil {
+		product.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		product.Version = version
+		return ErrConcurrentModification
 	}
 	return nil
 }
 
-// UpdateField updates a specific field of a product
+// UpdateField updates a specific field of a product and increments its version
 func (r *productRepository) UpdateField(id uint, field string, value interface{}) error {
-	if err := r.db.Model(&model.Product{}).Where("id = ?", id).Update(field, value).Error; err != nil {
+	if err := r.db.Model(&model.Product{}).Where("id = ?", id).Updates(map[string]interface{}{
+		field:     value,
+		"version": gorm.Expr("version + 1"),
+	}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// AdjustStock adds delta (which may be negative) to a product's stock in a
+// single conditional UPDATE. The row lock taken by the UPDATE serialises
+// concurrent callers, and the stock check is re-evaluated once the lock is
+// granted, so stock can never go below zero. Returns ErrInsufficientStock if it
+// would. The product version is incremented.
+func (r *productRepository) AdjustStock(id uint, delta int) error {
+	result := r.db.Model(&model.Product{}).
+		Where("id = ? AND stock + ? >= 0", id, delta).
+		Updates(map[string]interface{}{
+			"stock":   gorm.Expr("stock + ?", delta),
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
 // Delete removes a product by ID
 func (r *productRepository) Delete(id uint) error {
 	if err := r.db.Delete(&model.Product{}, id).Error; err != nil {
@@ -95,10 +183,15 @@ func (r *productRepository) Delete(id uint) error {
 	return nil
 }
 
-// DeleteByModel removes a product using the model instance
+// DeleteByModel removes a product using the model instance, provided its
+// version still matches. Returns ErrConcurrentModification if it does not.
 func (r *productRepository) DeleteByModel(product *model.Product) error {
-	if err := r.db.Delete(product).Error; err != nil {
-		return err
+	result := r.db.Where("version = ?", product.Version).Delete(product)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
 	}
 	return nil
 }
@@ -106,7 +199,8 @@ func (r *productRepository) DeleteByModel(product *model.Product) error {
 // GetProductsByOrderID retrieves all products associated with an order
 func (r *productRepository) GetProductsByOrderID(orderID uint) ([]model.Product, error) {
 	var products []model.Product
-	if err := r.db.Joins("JOIN order_products ON order_products.product_id = products.id").
+	if err := r.db.Distinct("products.*").
+		Joins("JOIN order_products ON order_products.product_id = products.id").
 		Where("order_products.order_id = ?", orderID).
 		Find(&products).Error; err != nil {
 		return nil, err
@@ -114,51 +208,87 @@ func (r *productRepository) GetProductsByOrderID(orderID uint) ([]model.Product,
 	return products, nil
 }
 
-// AddProductToOrder adds a product to an order
-func (r *productRepository) AddProductToOrder(orderID uint, productID uint, quantity int, price float64) error {
-	orderProduct := model.OrderProduct{
-		OrderID:   orderID,
-		ProductID: productID,
-		Quantity:  quantity,
-		Price:     price,
-	}
-	if err := r.db.Create(&orderProduct).Error; err != nil {
+// AddProductToOrder adds a line for a product, or one of its variants, to an
+// order at the line's price and exchange rate. If the order already has a line
+// for the same product and variant, the quantity is added to the existing line
+// and the originally captured price and rate are kept.
+func (r *productRepository) AddProductToOrder(line *model.OrderProduct) error {
+	orderProduct := *line
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "order_id"}, {Name: "product_id"}, {Name: "variant_id"}},
+		DoUpdates: clause.Set{{
+			Column: clause.Column{Name: "quantity"},
+			Value:  gorm.Expr("order_products.quantity + EXCLUDED.quantity"),
+		}},
+	}).Create(&orderProduct).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-// RemoveProductFromOrder removes a product from an order
-func (r *productRepository) RemoveProductFromOrder(orderID uint, productID uint) error {
-	if err := r.db.Where("order_id = ? AND product_id = ?", orderID, productID).
-		Delete(&model.OrderProduct{}).Error; err != nil {
-		return err
+// RemoveProductFromOrder removes the line for a product variant from an order
+func (r *productRepository) RemoveProductFromOrder(orderID uint, productID uint, variantID uint) error {
+	result := r.db.Where("order_id = ? AND product_id = ? AND variant_id = ?", orderID, productID, variantID).
+		Delete(&model.OrderProduct{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateOrderItem updates the quantity and discount of an existing order line
+func (r *productRepository) UpdateOrderItem(item *model.OrderProduct) error {
+	result := r.db.Model(&model.OrderProduct{}).
+		Where("order_id = ? AND product_id = ? AND variant_id = ?", item.OrderID, item.ProductID, item.VariantID).
+		Updates(map[string]interface{}{
+			"quantity":         item.Quantity,
+			"discount_percent": item.DiscountPercent,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 
 // FilterProducts retrieves products based on multiple filter criteria
-func (r *productRepository) FilterProducts(name, description string, minPrice, maxPrice *float64, minStock, maxStock *int) ([]model.Product, error) {
+func (r *productRepository) FilterProducts(filter ProductFilter) ([]model.Product, error) {
 	var products []model.Product
-	query := r.db.Model(&model.Product{})
+	query := r.db.Model(&model.Product{}).Preload("Tags")
 
-	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.Description != "" {
+		query = query.Where("description LIKE ?", "%"+filter.Description+"%")
 	}
-	if description != "" {
-		query = query.Where("description LIKE ?", "%"+description+"%")
+	if filter.MinPrice != nil {
+		query = query.Where(effectivePriceSQL+" >= ?", filter.PricedAt, filter.PricedAt, *filter.MinPrice)
 	}
-	if minPrice != nil {
-		query = query.Where("price >= ?", *minPrice)
+	if filter.MaxPrice != nil {
+		query = query.Where(effectivePriceSQL+" <= ?", filter.PricedAt, filter.PricedAt, *filter.MaxPrice)
 	}
-	if maxPrice != nil {
-		query = query.Where("price <= ?", *maxPrice)
+	if filter.MinStock != nil {
+		query = query.Where("stock >= ?", *filter.MinStock)
 	}
-	if minStock != nil {
-		query = query.Where("stock >= ?", *minStock)
+	if filter.MaxStock != nil {
+		query = query.Where("stock <= ?", *filter.MaxStock)
 	}
-	if maxStock != nil {
-		query = query.Where("stock <= ?", *maxStock)
+	if filter.CategoryID != nil {
+		query = query.Where("category_id IN (?)", r.db.Raw(descendantCategoriesSQL, *filter.CategoryID))
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("id IN (?)", r.db.Table("product_tags").
+			Select("product_tags.product_id").
+			Joins("JOIN tags ON tags.id = product_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags).
+			Group("product_tags.product_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags)))
 	}
 
 	if err := query.Find(&products).Error; err != nil {
@@ -176,4 +306,140 @@ func (r *productRepository) GetProductsWithOrders() ([]model.Product, error) {
 	return products, nil
 }
 
+// GetDeleted retrieves the soft-deleted products, most recently deleted first
+func (r *productRepository) GetDeleted() ([]model.Product, error) {
+	var products []model.Product
+	if err := r.db.Unscoped().
+		Preload("Tags").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
 
+// GetDeletedByID retrieves a soft-deleted product by its ID
+func (r *productRepository) GetDeletedByID(id uint) (*model.Product, error) {
+	var product model.Product
+	if err := r.db.Unscoped().
+		Preload("Tags").
+		Where("deleted_at IS NOT NULL").
+		First(&product, id).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CountOrderLines counts the order lines, on any order including deleted ones,
+// that refer to a product
+func (r *productRepository) CountOrderLines(id uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.OrderProduct{}).
+		Where("product_id = ?", id).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Restore clears the deletion mark of a soft-deleted product, sets its
+// category and increments its version. Returns ErrConcurrentModification if
+// the product was restored or changed in the meantime.
+func (r *productRepository) Restore(product *model.Product) error {
+	result := r.db.Unscoped().Model(&model.Product{}).
+		Where("id = ? AND version = ? AND deleted_at IS NOT NULL", product.ID, product.Version).
+		Updates(map[string]interface{}{
+			"deleted_at":  nil,
+			"category_id": product.CategoryID,
+			"version":     gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
+	}
+	product.DeletedAt = gorm.DeletedAt{}
+	product.Version++
+	return nil
+}
+
+// Purge permanently removes a soft-deleted product together with its tags,
+// price list, variants and stock ledger. Returns gorm.ErrRecordNotFound if the
+// product is not soft-deleted.
+func (r *productRepository) Purge(id uint) error {
+	if _, err := r.GetDeletedByID(id); err != nil {
+		return err
+	}
+	return r.PurgeBatch([]uint{id})
+}
+
+// GetPurgeableIDsBefore retrieves the IDs of up to limit products that were
+// soft-deleted before cutoff and are on no order line, oldest deletion first
+func (r *productRepository) GetPurgeableIDsBefore(cutoff time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Unscoped().Model(&model.Product{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM order_products WHERE order_products.product_id = products.id)").
+		Order("deleted_at, id").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CountDeletedBefore counts the products soft-deleted before cutoff that can
+// be purged once the orders deleted before cutoff are, and those that are kept
+// because orders that remain still have lines for them
+func (r *productRepository) CountDeletedBefore(cutoff time.Time) (int64, int64, error) {
+	var total, kept int64
+	if err := r.db.Unscoped().Model(&model.Product{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Count(&total).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.Unscoped().Model(&model.Product{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Where(`EXISTS (SELECT 1 FROM order_products JOIN orders ON orders.id = order_products.order_id
+			WHERE order_products.product_id = products.id
+			AND (orders.deleted_at IS NULL OR orders.deleted_at >= ?))`, cutoff).
+		Count(&kept).Error; err != nil {
+		return 0, 0, err
+	}
+	return total - kept, kept, nil
+}
+
+// PurgeBatch permanently removes soft-deleted products together with their
+// tags, price lists, variants and stock ledgers. Products in ids that are not
+// soft-deleted are left alone.
+func (r *productRepository) PurgeBatch(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	deleted := r.db.Unscoped().Model(&model.Product{}).
+		Select("id").
+		Where("id IN ? AND deleted_at IS NOT NULL", ids)
+
+	if err := r.db.Exec("DELETE FROM product_tags WHERE product_id IN (?)", deleted).Error; err != nil {
+		return err
+	}
+	dependents := []interface{}{
+		&model.PriceListEntry{},
+		&model.ProductPrice{},
+		&model.ScheduledPrice{},
+		&model.StockMovement{},
+	}
+	for _, dependent := range dependents {
+		if err := r.db.Where("product_id IN (?)", deleted).Delete(dependent).Error; err != nil {
+			return err
+		}
+	}
+	if err := r.db.Unscoped().Where("product_id IN (?)", deleted).Delete(&model.ProductVariant{}).Error; err != nil {
+		return err
+	}
+
+	return r.db.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Delete(&model.Product{}).Error
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromotionRepository defines the interface for promotion data operations
+type PromotionRepository interface {
+	Create(promotion *model.Promotion) error
+	GetByID(id uint) (*model.Promotion, error)
+	GetByCode(code string) (*model.Promotion, error)
+	GetAll() ([]model.Promotion, error)
+	GetAutomatic() ([]model.Promotion, error)
+	CodeExists(code string, excludeID uint) (bool, error)
+	Update(promotion *model.Promotion) error
+	DeleteByModel(promotion *model.Promotion) error
+	GetOrderPromotions(orderID uint) ([]model.OrderPromotion, error)
+	ReplaceOrderPromotions(orderID uint, promotions []model.OrderPromotion) error
+	CountCustomerUsage(promotionID, customerID, excludeOrderID uint) (int, error)
+}
+
+// promotionRepository implements PromotionRepository interface
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+// NewPromotionRepository creates a new instance of PromotionRepository
+func NewPromotionRepository() PromotionRepository {
+	return &promotionRepository{
+		db: database.DB,
+	}
+}
+
+// Create inserts a new promotion into the database
+func (r *promotionRepository) Create(promotion *model.Promotion) error {
+	if err := r.db.Create(promotion).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a promotion by its ID
+func (r *promotionRepository) GetByID(id uint) (*model.Promotion, error) {
+	var promotion model.Promotion
+	if err := r.db.First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// GetByCode retrieves a coupon by its code
+func (r *promotionRepository) GetByCode(code string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	if err := r.db.Where("code = ?", code).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// GetAll retrieves all promotions, highest priority first
+func (r *promotionRepository) GetAll() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	if err := r.db.Order("priority DESC, id").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// GetAutomatic retrieves the active promotions that apply without a code
+func (r *promotionRepository) GetAutomatic() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	if err := r.db.Where("code = '' AND active").
+		Order("priority DESC, id").
+		Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// CodeExists reports whether a promotion other than excludeID uses code
+func (r *promotionRepository) CodeExists(code string, excludeID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Promotion{}).
+		Where("code = ? AND id <> ?", code, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Update writes an existing promotion if its version still matches the one it
+// was loaded with, and increments the version. Returns
+// ErrConcurrentModification if the promotion was changed in the meantime.
+func (r *promotionRepository) Update(promotion *model.Promotion) error {
+	version := promotion.Version
+	promotion.Version++
+	result := r.db.Model(promotion).
+		Where("version = ?", version).
+		Select("*").
+		Omit("created_at", "deleted_at").
+		Updates(promotion)
+	if result.Error != nil {
+		promotion.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		promotion.Version = version
+		return ErrConcurrentModification
+	}
+	return nil
+}
+
+// DeleteByModel removes a promotion using the model instance, provided its
+// version still matches. Returns ErrConcurrentModification if it does not.
+func (r *promotionRepository) DeleteByModel(promotion *model.Promotion) error {
+	result := r.db.Where("version = ?", promotion.Version).Delete(promotion)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
+	}
+	return nil
+}
+
+// GetOrderPromotions retrieves the promotions recorded on an order together
+// with the promotions themselves. Promotions deleted since are left empty.
+func (r *promotionRepository) GetOrderPromotions(orderID uint) ([]model.OrderPromotion, error) {
+	var promotions []model.OrderPromotion
+	if err := r.db.Preload("Promotion").
+		Where("order_id = ?", orderID).
+		Order("created_at, promotion_id").
+		Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// ReplaceOrderPromotions replaces the promotions recorded on an order
+func (r *promotionRepository) ReplaceOrderPromotions(orderID uint, promotions []model.OrderPromotion) error {
+	if err := r.db.Where("order_id = ?", orderID).Delete(&model.OrderPromotion{}).Error; err != nil {
+		return err
+	}
+	if len(promotions) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Create(&promotions).Error
+}
+
+// CountCustomerUsage counts the orders of a customer, other than
+// excludeOrderID, that a promotion was applied to. Draft and cancelled orders
+// do not count; deleted orders do, so deleting an order does not give the use
+// back.
+func (r *promotionRepository) CountCustomerUsage(promotionID, customerID, excludeOrderID uint) (int, error) {
+	var count int64
+	if err := r.db.Model(&model.OrderPromotion{}).
+		Joins("JOIN orders ON orders.id = order_promotions.order_id").
+		Where("order_promotions.promotion_id = ? AND order_promotions.applied", promotionID).
+		Where("orders.customer_id = ? AND orders.id <> ?", customerID, excludeOrderID).
+		Where("orders.status NOT IN ?", []model.OrderStatus{model.OrderStatusDraft, model.OrderStatusCancelled}).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
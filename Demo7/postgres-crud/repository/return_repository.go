@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReturnRepository defines the interface for order return data operations
+type ReturnRepository interface {
+	Create(ret *model.Return) error
+	GetByID(id uint) (*model.Return, error)
+	GetByIDForUpdate(id uint) (*model.Return, error)
+	GetByOrderID(orderID uint) ([]model.Return, error)
+	GetLinesByOrderID(orderID uint) ([]model.ReturnLine, error)
+	UpdateStatus(ret *model.Return, from model.ReturnStatus, columns ...string) error
+}
+
+// returnRepository implements ReturnRepository interface
+type returnRepository struct {
+	db *gorm.DB
+}
+
+// NewReturnRepository creates a new instance of ReturnRepository
+func NewReturnRepository() ReturnRepository {
+	return &returnRepository{
+		db: database.DB,
+	}
+}
+
+// orderedLines preloads the lines of a return in the order they were added
+func orderedLines(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// Create creates a new return together with its lines
+func (r *returnRepository) Create(ret *model.Return) error {
+	if err := r.db.Create(ret).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a return by ID with its lines
+func (r *returnRepository) GetByID(id uint) (*model.Return, error) {
+	var ret model.Return
+	if err := r.db.Preload("Lines", orderedLines).First(&ret, id).Error; err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetByIDForUpdate retrieves a return by ID with its lines and locks its row
+// until the end of the transaction, so that its steps are taken one at a time
+func (r *returnRepository) GetByIDForUpdate(id uint) (*model.Return, error) {
+	var ret model.Return
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Lines", orderedLines).
+		First(&ret, id).Error; err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetByOrderID retrieves the returns of an order with their lines, oldest first
+func (r *returnRepository) GetByOrderID(orderID uint) ([]model.Return, error) {
+	var returns []model.Return
+	if err := r.db.Preload("Lines", orderedLines).
+		Where("order_id = ?", orderID).
+		Order("created_at, id").
+		Find(&returns).Error; err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// GetLinesByOrderID retrieves the lines of every return of an order
+func (r *returnRepository) GetLinesByOrderID(orderID uint) ([]model.ReturnLine, error) {
+	var lines []model.ReturnLine
+	if err := r.db.Where("order_id = ?", orderID).Order("id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// UpdateStatus writes the status of a return, and any additional columns,
+// only while the return still has the expected status and version. The version
+// is incremented.
+func (r *returnRepository) UpdateStatus(ret *model.Return, from model.ReturnStatus, columns ...string) error {
+	version := ret.Version
+	ret.Version++
+	result := r.db.Model(ret).
+		Where("status = ? AND version = ?", from, version).
+		Select(append([]string{"status", "version"}, columns...)).
+		Updates(ret)
+	if result.Error != nil {
+		ret.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		ret.Version = version
+		return ErrConcurrentModification
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledPriceRepository defines the interface for scheduled product price
+// data operations
+type ScheduledPriceRepository interface {
+	Create(schedule *model.ScheduledPrice) error
+	GetByID(id uint) (*model.ScheduledPrice, error)
+	GetByProductID(productID uint) ([]model.ScheduledPrice, error)
+	GetActive(productIDs []uint, at time.Time) ([]model.ScheduledPrice, error)
+	FindOverlapping(schedule *model.ScheduledPrice) ([]model.ScheduledPrice, error)
+	Update(schedule *model.ScheduledPrice) error
+	Delete(id uint) error
+}
+
+// scheduledPriceRepository implements ScheduledPriceRepository interface
+type scheduledPriceRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduledPriceRepository creates a new instance of ScheduledPriceRepository
+func NewScheduledPriceRepository() ScheduledPriceRepository {
+	return &scheduledPriceRepository{
+		db: database.DB,
+	}
+}
+
+// Create creates a new scheduled price
+func (r *scheduledPriceRepository) Create(schedule *model.ScheduledPrice) error {
+	if err := r.db.Create(schedule).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a scheduled price by ID
+func (r *scheduledPriceRepository) GetByID(id uint) (*model.ScheduledPrice, error) {
+	var schedule model.ScheduledPrice
+	if err := r.db.First(&schedule, id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetByProductID retrieves the scheduled prices of a product, earliest first
+func (r *scheduledPriceRepository) GetByProductID(productID uint) ([]model.ScheduledPrice, error) {
+	var schedules []model.ScheduledPrice
+	if err := r.db.Where("product_id = ?", productID).
+		Order("starts_at, id").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetActive retrieves the scheduled prices of the given products that are in
+// effect at a given time
+func (r *scheduledPriceRepository) GetActive(productIDs []uint, at time.Time) ([]model.ScheduledPrice, error) {
+	var schedules []model.ScheduledPrice
+	if len(productIDs) == 0 {
+		return schedules, nil
+	}
+	if err := r.db.Where("product_id IN ? AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", productIDs, at, at).
+		Order("product_id, starts_at DESC").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// FindOverlapping retrieves the other scheduled prices of the same product
+// whose period overlaps the period of schedule
+func (r *scheduledPriceRepository) FindOverlapping(schedule *model.ScheduledPrice) ([]model.ScheduledPrice, error) {
+	var schedules []model.ScheduledPrice
+	query := r.db.Where("product_id = ? AND id <> ?", schedule.ProductID, schedule.ID).
+		Where("ends_at IS NULL OR ends_at > ?", schedule.StartsAt)
+	if schedule.EndsAt != nil {
+		query = query.Where("starts_at < ?", *schedule.EndsAt)
+	}
+	if err := query.Order("starts_at, id").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// Update updates an existing scheduled price
+func (r *scheduledPriceRepository) Update(schedule *model.ScheduledPrice) error {
+	if err := r.db.Save(schedule).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a scheduled price
+func (r *scheduledPriceRepository) Delete(id uint) error {
+	result := r.db.Delete(&model.ScheduledPrice{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
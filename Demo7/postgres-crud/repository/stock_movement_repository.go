@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+)
+
+// StockMovementRepository defines the interface for stock ledger operations
+type StockMovementRepository interface {
+	Record(movement *model.StockMovement) error
+	GetByProductID(productID uint) ([]model.StockMovement, error)
+	GetLedgerBalance(productID uint) (int, error)
+	GetByVariantID(variantID uint) ([]model.StockMovement, error)
+	GetVariantLedgerBalance(variantID uint) (int, error)
+	BackfillOpeningBalances() (int64, error)
+}
+
+// stockMovementRepository implements StockMovementRepository interface
+type stockMovementRepository struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepository creates a new instance of StockMovementRepository
+func NewStockMovementRepository() StockMovementRepository {
+	return &stockMovementRepository{
+		db: database.DB,
+	}
+}
+
+// Record appends a movement to the ledger. The matching change to the stock is
+// made with ProductRepository.AdjustStock or VariantRepository.AdjustStock in
+// the same unit of work.
+func (r *stockMovementRepository) Record(movement *model.StockMovement) error {
+	if err := r.db.Create(movement).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByProductID retrieves the stock history of a product, oldest first.
+// Movements of its variants are not included.
+func (r *stockMovementRepository) GetByProductID(productID uint) ([]model.StockMovement, error) {
+	var movements []model.StockMovement
+	if err := r.db.Where("product_id = ? AND variant_id IS NULL", productID).
+		Order("created_at, id").
+		Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// GetLedgerBalance returns the sum of all movement deltas of a product,
+// excluding those of its variants
+func (r *stockMovementRepository) GetLedgerBalance(productID uint) (int, error) {
+	var balance int
+	if err := r.db.Model(&model.StockMovement{}).
+		Where("product_id = ? AND variant_id IS NULL", productID).
+		Select("COALESCE(SUM(delta), 0)").
+		Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// GetByVariantID retrieves the stock history of a variant, oldest first
+func (r *stockMovementRepository) GetByVariantID(variantID uint) ([]model.StockMovement, error) {
+	var movements []model.StockMovement
+	if err := r.db.Where("variant_id = ?", variantID).
+		Order("created_at, id").
+		Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// GetVariantLedgerBalance returns the sum of all movement deltas of a variant
+func (r *stockMovementRepository) GetVariantLedgerBalance(variantID uint) (int, error) {
+	var balance int
+	if err := r.db.Model(&model.StockMovement{}).
+		Where("variant_id = ?", variantID).
+		Select("COALESCE(SUM(delta), 0)").
+		Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// BackfillOpeningBalances records an initial_stock movement for every product
+// that has stock but no ledger entries yet, so that products created before the
+// ledger existed reconcile. It returns the number of movements recorded.
+func (r *stockMovementRepository) BackfillOpeningBalances() (int64, error) {
+	result := r.db.Exec(`
+		INSERT INTO stock_movements (product_id, delta, reason, reference_type, reference_id, actor, note, created_at)
+		SELECT p.id, p.stock, ?, ?, p.id, ?, 'opening balance', NOW()
+		FROM products p
+		WHERE p.stock <> 0
+		  AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.id AND m.variant_id IS NULL)`,
+		model.StockReasonInitial, model.StockReferenceProduct, model.StockActorSystem)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
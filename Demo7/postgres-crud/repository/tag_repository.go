@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepository defines the interface for tag data operations
+type TagRepository interface {
+	Create(tag *model.Tag) error
+	GetByID(id uint) (*model.Tag, error)
+	GetByName(name string) (*model.Tag, error)
+	GetAll() ([]model.Tag, error)
+	Update(tag *model.Tag) error
+	Delete(id uint) error
+	FindOrCreate(names []string) ([]model.Tag, error)
+	ReplaceProductTags(productID uint, tags []model.Tag) error
+}
+
+// tagRepository implements TagRepository interface
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new instance of TagRepository
+func NewTagRepository() TagRepository {
+	return &tagRepository{
+		db: database.DB,
+	}
+}
+
+// Create inserts a new tag into the database
+func (r *tagRepository) Create(tag *model.Tag) error {
+	if err := r.db.Create(tag).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a tag by its ID
+func (r *tagRepository) GetByID(id uint) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByName retrieves a tag by its name
+func (r *tagRepository) GetByName(name string) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.Where("name = ?", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetAll retrieves all tags, ordered by name
+func (r *tagRepository) GetAll() ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := r.db.Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Update renames an existing tag
+func (r *tagRepository) Update(tag *model.Tag) error {
+	result := r.db.Model(tag).Select("name").Updates(tag)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete removes a tag by ID, together with its product assignments
+func (r *tagRepository) Delete(id uint) error {
+	if err := r.db.Exec("DELETE FROM product_tags WHERE tag_id = ?", id).Error; err != nil {
+		return err
+	}
+	result := r.db.Delete(&model.Tag{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindOrCreate retrieves the tags with the given names, creating the ones that
+// do not exist yet
+func (r *tagRepository) FindOrCreate(names []string) ([]model.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]model.Tag, len(names))
+	for i, name := range names {
+		tags[i] = model.Tag{Name: name}
+	}
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	tags = nil
+	if err := r.db.Where("name IN ?", names).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ReplaceProductTags sets the tags of a product, removing any it had before
+func (r *tagRepository) ReplaceProductTags(productID uint, tags []model.Tag) error {
+	if err := r.db.Exec("DELETE FROM product_tags WHERE product_id = ?", productID).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]interface{}, len(tags))
+	for i, tag := range tags {
+		rows[i] = map[string]interface{}{"product_id": productID, "tag_id": tag.ID}
+	}
+	if err := r.db.Table("product_tags").Create(rows).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaxRepository defines the interface for tax rate and order tax line data
+// operations
+type TaxRepository interface {
+	CreateRate(rate *model.TaxRate) error
+	GetRateByID(id uint) (*model.TaxRate, error)
+	GetRates(country string) ([]model.TaxRate, error)
+	RateExists(rate *model.TaxRate) (bool, error)
+	FindEffectiveRate(country, region string, taxClass model.TaxClass, at time.Time) (*model.TaxRate, error)
+	UpdateRate(rate *model.TaxRate) error
+	DeleteRate(id uint) error
+	GetOrderTaxLines(orderID uint) ([]model.OrderTaxLine, error)
+	ReplaceOrderTaxLines(orderID uint, lines []model.OrderTaxLine) error
+}
+
+// taxRepository implements TaxRepository interface
+type taxRepository struct {
+	db *gorm.DB
+}
+
+// NewTaxRepository creates a new instance of TaxRepository
+func NewTaxRepository() TaxRepository {
+	return &taxRepository{
+		db: database.DB,
+	}
+}
+
+// CreateRate inserts a new tax rate into the database
+func (r *taxRepository) CreateRate(rate *model.TaxRate) error {
+	if err := r.db.Create(rate).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRateByID retrieves a tax rate by its ID
+func (r *taxRepository) GetRateByID(id uint) (*model.TaxRate, error) {
+	var rate model.TaxRate
+	if err := r.db.First(&rate, id).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetRates retrieves the tax rates of a country, or of all countries when
+// country is empty, ordered by destination, class and start date
+func (r *taxRepository) GetRates(country string) ([]model.TaxRate, error) {
+	var rates []model.TaxRate
+	query := r.db.Order("country, region, tax_class, effective_from")
+	if country != "" {
+		query = query.Where("country = ?", country)
+	}
+	if err := query.Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// RateExists reports whether a tax rate other than rate itself exists for the
+// same destination and tax class starting at the same time
+func (r *taxRepository) RateExists(rate *model.TaxRate) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.TaxRate{}).
+		Where("country = ? AND region = ? AND tax_class = ?", rate.Country, rate.Region, rate.TaxClass).
+		Where("effective_from = ? AND id <> ?", rate.EffectiveFrom, rate.ID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindEffectiveRate retrieves the rate for a tax class that is in effect at a
+// given time. A rate for the region takes precedence over one for the whole
+// country; among those the one that started last wins.
+func (r *taxRepository) FindEffectiveRate(country, region string, taxClass model.TaxClass, at time.Time) (*model.TaxRate, error) {
+	var rate model.TaxRate
+	if err := r.db.Where("country = ? AND region IN ? AND tax_class = ?", country, []string{region, ""}, taxClass).
+		Where("effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", at, at).
+		Order("region DESC, effective_from DESC").
+		First(&rate).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// UpdateRate writes an existing tax rate
+func (r *taxRepository) UpdateRate(rate *model.TaxRate) error {
+	if err := r.db.Save(rate).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteRate removes a tax rate by its ID
+func (r *taxRepository) DeleteRate(id uint) error {
+	result := r.db.Delete(&model.TaxRate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetOrderTaxLines retrieves the tax lines persisted for an order
+func (r *taxRepository) GetOrderTaxLines(orderID uint) ([]model.OrderTaxLine, error) {
+	var lines []model.OrderTaxLine
+	if err := r.db.Where("order_id = ?", orderID).Order("id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// ReplaceOrderTaxLines replaces the tax lines persisted for an order
+func (r *taxRepository) ReplaceOrderTaxLines(orderID uint, lines []model.OrderTaxLine) error {
+	if err := r.db.Where("order_id = ?", orderID).Delete(&model.OrderTaxLine{}).Error; err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	for i := range lines {
+		lines[i].ID = 0
+		lines[i].OrderID = orderID
+	}
+	return r.db.Omit(clause.Associations).Create(&lines).Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"postgres-crud/database"
+
+	"gorm.io/gorm"
+)
+
+// Repositories groups repository instances bound to the same database handle.
+// Inside a unit of work they all share one transaction.
+type Repositories struct {
+	Orders        OrderRepository
+	Products      ProductRepository
+	Stock         StockMovementRepository
+	PriceLists    PriceListRepository
+	ExchangeRates ExchangeRateRepository
+	Categories    CategoryRepository
+	Tags          TagRepository
+	Variants      VariantRepository
+	Customers     CustomerRepository
+	Addresses     AddressRepository
+	Promotions    PromotionRepository
+	Taxes         TaxRepository
+	PriceHistory  PriceHistoryRepository
+	Schedules     ScheduledPriceRepository
+	Returns       ReturnRepository
+	Invoices      InvoiceRepository
+}
+
+// UnitOfWork defines the interface for running several repository calls atomically
+type UnitOfWork interface {
+	Do(fn func(repos Repositories) error) error
+}
+
+// unitOfWork implements UnitOfWork interface on top of a GORM transaction
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a new instance of UnitOfWork
+func NewUnitOfWork() UnitOfWork {
+	return &unitOfWork{
+		db: database.DB,
+	}
+}
+
+// Do runs fn in a database transaction with transaction-scoped repositories.
+// The transaction is committed when fn returns nil and rolled back when it
+// returns an error or panics.
+func (u *unitOfWork) Do(fn func(repos Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+// newRepositories creates repository instances bound to db
+func newRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Orders:        &orderRepository{db: db},
+		Products:      &productRepository{db: db},
+		Stock:         &stockMovementRepository{db: db},
+		PriceLists:    &priceListRepository{db: db},
+		ExchangeRates: &exchangeRateRepository{db: db},
+		Categories:    &categoryRepository{db: db},
+		Tags:          &tagRepository{db: db},
+		Variants:      &variantRepository{db: db},
+		Customers:     &customerRepository{db: db},
+		Addresses:     &addressRepository{db: db},
+		Promotions:    &promotionRepository{db: db},
+		Taxes:         &taxRepository{db: db},
+		PriceHistory:  &priceHistoryRepository{db: db},
+		Schedules:     &scheduledPriceRepository{db: db},
+		Returns:       &returnRepository{db: db},
+		Invoices:      &invoiceRepository{db: db},
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"postgres-crud/database"
+	"postgres-crud/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VariantRepository defines the interface for product variant data operations
+type VariantRepository interface {
+	Create(variant *model.ProductVariant) error
+	GetByID(id uint) (*model.ProductVariant, error)
+	GetBySKU(sku string) (*model.ProductVariant, error)
+	GetByProductID(productID uint) ([]model.ProductVariant, error)
+	CountByProductID(productID uint) (int64, error)
+	SKUExists(sku string, excludeID uint) (bool, error)
+	Update(variant *model.ProductVariant) error
+	AdjustStock(id uint, delta int) error
+	DeleteByModel(variant *model.ProductVariant) error
+}
+
+// variantRepository implements VariantRepository interface
+type variantRepository struct {
+	db *gorm.DB
+}
+
+// NewVariantRepository creates a new instance of VariantRepository
+func NewVariantRepository() VariantRepository {
+	return &variantRepository{
+		db: database.DB,
+	}
+}
+
+// Create inserts a new variant into the database
+func (r *variantRepository) Create(variant *model.ProductVariant) error {
+	if err := r.db.Omit(clause.Associations).Create(variant).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a variant by its ID
+func (r *variantRepository) GetByID(id uint) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	if err := r.db.First(&variant, id).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// GetBySKU retrieves a variant by its SKU together with its product
+func (r *variantRepository) GetBySKU(sku string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	if err := r.db.Preload("Product.Tags").
+		Where("sku = ?", sku).
+		First(&variant).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// GetByProductID retrieves the variants of a product, ordered by SKU
+func (r *variantRepository) GetByProductID(productID uint) ([]model.ProductVariant, error) {
+	var variants []model.ProductVariant
+	if err := r.db.Where("product_id = ?", productID).
+		Order("sku").
+		Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// CountByProductID returns the number of variants of a product
+func (r *variantRepository) CountByProductID(productID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.ProductVariant{}).
+		Where("product_id = ?", productID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SKUExists reports whether a variant other than excludeID uses sku. Deleted
+// variants are included because their SKUs stay reserved.
+func (r *variantRepository) SKUExists(sku string, excludeID uint) (bool, error) {
+	var count int64
+	if err := r.db.Unscoped().Model(&model.ProductVariant{}).
+		Where("sku = ? AND id <> ?", sku, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Update writes an existing variant if its version still matches the one it
+// was loaded with, and increments the version. Stock is not written; it only
+// changes through stock movements. Returns ErrConcurrentModification if the
+// variant was changed in the meantime.
+func (r *variantRepository) Update(variant *model.ProductVariant) error {
+	version := variant.Version
+	variant.Version++
+	result := r.db.Model(variant).
+		Where("version = ?", version).
+		Select("*").
+		Omit("stock", "created_at", "deleted_at", clause.Associations).
+		Updates(variant)
+	if result.Error != nil {
+		variant.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		variant.Version = version
+		return ErrConcurrentModification
+	}
+	return nil
+}
+
+// AdjustStock adds delta (which may be negative) to a variant's stock in a
+// single conditional UPDATE, in the same way as ProductRepository.AdjustStock.
+// Returns ErrInsufficientStock if stock would go below zero.
+func (r *variantRepository) AdjustStock(id uint, delta int) error {
+	result := r.db.Model(&model.ProductVariant{}).
+		Where("id = ? AND stock + ? >= 0", id, delta).
+		Updates(map[string]interface{}{
+			"stock":   gorm.Expr("stock + ?", delta),
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// DeleteByModel removes a variant using the model instance, provided its
+// version still matches. Returns ErrConcurrentModification if it does not.
+func (r *variantRepository) DeleteByModel(variant *model.ProductVariant) error {
+	result := r.db.Where("version = ?", variant.Version).Delete(variant)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentModification
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+
+	"gorm.io/gorm"
+)
+
+// CategoryService defines the interface for category business logic
+type CategoryService interface {
+	CreateCategory(name, description string, parentID *uint) (*model.Category, error)
+	GetCategoryByID(id uint) (*model.Category, error)
+	GetAllCategories() ([]model.Category, error)
+	UpdateCategory(id uint, name, description string, parentID *uint) (*model.Category, error)
+	DeleteCategory(id uint) error
+}
+
+// categoryService implements CategoryService interface
+type categoryService struct {
+	categoryRepo repository.CategoryRepository
+	uow          repository.UnitOfWork
+}
+
+// NewCategoryService creates a new instance of CategoryService
+func NewCategoryService(categoryRepo repository.CategoryRepository, uow repository.UnitOfWork) CategoryService {
+	return &categoryService{
+		categoryRepo: categoryRepo,
+		uow:          uow,
+	}
+}
+
+// ensureParentCategory checks that a parent category exists. A missing parent
+// is reported as a bad request rather than as the category not being found.
+func ensureParentCategory(categories repository.CategoryRepository, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if _, err := categories.GetByID(*parentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrBadRequest.Code,
+				Message: fmt.Sprintf("parent category %d not found", *parentID),
+			}
+		}
+		return fmt.Errorf("failed to get parent category: %w", err)
+	}
+	return nil
+}
+
+// CreateCategory creates a new category, optionally below a parent category
+func (s *categoryService) CreateCategory(name, description string, parentID *uint) (*model.Category, error) {
+	if name == "" {
+		return nil, fmt.Errorf("category name cannot be empty")
+	}
+	if err := ensureParentCategory(s.categoryRepo, parentID); err != nil {
+		return nil, err
+	}
+
+	category := &model.Category{
+		Name:        name,
+		Description: description,
+		ParentID:    parentID,
+	}
+	if err := s.categoryRepo.Create(category); err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return category, nil
+}
+
+// GetCategoryByID retrieves a category with its direct subcategories
+func (s *categoryService) GetCategoryByID(id uint) (*model.Category, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid category ID")
+	}
+
+	category, err := s.categoryRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "category not found")
+	}
+
+	return category, nil
+}
+
+// GetAllCategories retrieves all categories
+func (s *categoryService) GetAllCategories() ([]model.Category, error) {
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// UpdateCategory renames a category and moves it below another parent, or to
+// the root when parentID is nil. A category cannot be moved below itself or
+// one of its own subcategories.
+func (s *categoryService) UpdateCategory(id uint, name, description string, parentID *uint) (*model.Category, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid category ID")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("category name cannot be empty")
+	}
+
+	var category *model.Category
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Moves are made one at a time, so that no concurrent move can
+		// change the tree between the descendant check and the update
+		if err := repos.Categories.LockTree(); err != nil {
+			return fmt.Errorf("failed to lock category tree: %w", err)
+		}
+
+		var err error
+		category, err = repos.Categories.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "category not found")
+		}
+
+		if parentID != nil {
+			if err := ensureParentCategory(repos.Categories, parentID); err != nil {
+				return err
+			}
+			descendants, err := repos.Categories.GetDescendantIDs(id)
+			if err != nil {
+				return fmt.Errorf("failed to get subcategories: %w", err)
+			}
+			for _, descendant := range descendants {
+				if descendant == *parentID {
+					return &apierrors.APIError{
+						Code:    apierrors.ErrBadRequest.Code,
+						Message: "category cannot be moved below itself or one of its subcategories",
+					}
+				}
+			}
+		}
+
+		category.Name = name
+		category.Description = description
+		category.ParentID = parentID
+		if err := repos.Categories.Update(category); err != nil {
+			return fmt.Errorf("failed to update category: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// DeleteCategory deletes a category that has no subcategories. Products in the
+// category are left without one.
+func (s *categoryService) DeleteCategory(id uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid category ID")
+	}
+
+	return s.uow.Do(func(repos repository.Repositories) error {
+		if _, err := repos.Categories.GetByID(id); err != nil {
+			return notFoundOr(err, "category not found")
+		}
+
+		hasChildren, err := repos.Categories.HasChildren(id)
+		if err != nil {
+			return fmt.Errorf("failed to check subcategories: %w", err)
+		}
+		if hasChildren {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrConflict.Code,
+				Message: "category has subcategories",
+				Details: "move or delete the subcategories first",
+			}
+		}
+
+		if err := repos.Categories.DetachProducts(id); err != nil {
+			return fmt.Errorf("failed to detach products: %w", err)
+		}
+		if err := repos.Categories.Delete(id); err != nil {
+			return notFoundOr(err, "category not found")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"postgres-crud/repository"
+
+	"gorm.io/gorm"
+)
+
+// Sources of a resolved price
+const (
+	PriceSourceBase      = "base"
+	PriceSourcePriceList = "price_list"
+	PriceSourceConverted = "converted"
+)
+
+// ResolvedPrice is a product price in a requested currency. Rate is the rate
+// from the product's own currency that was applied, and 1 when no conversion
+// took place.
+type ResolvedPrice struct {
+	Price  money.Money
+	Rate   money.Rate
+	Source string
+}
+
+// CurrencyService defines the interface for price lists and exchange rates
+type CurrencyService interface {
+	GetPriceList(productID uint) (*model.Product, []model.PriceListEntry, error)
+	SetListPrice(productID uint, price money.Money) (*model.PriceListEntry, error)
+	DeleteListPrice(productID uint, currency string) error
+	GetExchangeRates() ([]model.ExchangeRate, error)
+	SetExchangeRate(base, quote string, rate money.Rate) (*model.ExchangeRate, error)
+	DeleteExchangeRate(base, quote string) error
+	PriceIn(product *model.Product, currency string) (*ResolvedPrice, error)
+}
+
+// currencyService implements CurrencyService interface
+type currencyService struct {
+	priceListRepo    repository.PriceListRepository
+	exchangeRateRepo repository.ExchangeRateRepository
+	productRepo      repository.ProductRepository
+}
+
+// NewCurrencyService creates a new instance of CurrencyService
+func NewCurrencyService(priceListRepo repository.PriceListRepository, exchangeRateRepo repository.ExchangeRateRepository, productRepo repository.ProductRepository) CurrencyService {
+	return &currencyService{
+		priceListRepo:    priceListRepo,
+		exchangeRateRepo: exchangeRateRepo,
+		productRepo:      productRepo,
+	}
+}
+
+// GetPriceList retrieves a product together with its per-currency prices
+func (s *currencyService) GetPriceList(productID uint) (*model.Product, []model.PriceListEntry, error) {
+	if productID == 0 {
+		return nil, nil, fmt.Errorf("invalid product ID")
+	}
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "product not found")
+	}
+
+	entries, err := s.priceListRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get price list: %w", err)
+	}
+
+	return product, entries, nil
+}
+
+// SetListPrice sets the price of a product in the currency of price
+func (s *currencyService) SetListPrice(productID uint, price money.Money) (*model.PriceListEntry, error) {
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+	if price.Currency == "" {
+		return nil, fmt.Errorf("currency cannot be empty")
+	}
+	if price.IsNegative() {
+		return nil, fmt.Errorf("price cannot be negative")
+	}
+
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, notFoundOr(err, "product not found")
+	}
+
+	entry := &model.PriceListEntry{
+		ProductID: productID,
+		Price:     price,
+	}
+	if err := s.priceListRepo.Upsert(entry); err != nil {
+		return nil, fmt.Errorf("failed to set list price: %w", err)
+	}
+
+	return s.priceListRepo.GetPrice(productID, price.Currency)
+}
+
+// DeleteListPrice removes the price of a product in a currency
+func (s *currencyService) DeleteListPrice(productID uint, currency string) error {
+	if productID == 0 {
+		return fmt.Errorf("invalid product ID")
+	}
+
+	if err := s.priceListRepo.Delete(productID, currency); err != nil {
+		return notFoundOr(err, "list price not found")
+	}
+
+	return nil
+}
+
+// GetExchangeRates retrieves all exchange rates
+func (s *currencyService) GetExchangeRates() ([]model.ExchangeRate, error) {
+	rates, err := s.exchangeRateRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// SetExchangeRate sets the rate for converting from base to quote
+func (s *currencyService) SetExchangeRate(base, quote string, rate money.Rate) (*model.ExchangeRate, error) {
+	if base == quote {
+		return nil, fmt.Errorf("base and quote currency must differ")
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("exchange rate must be greater than zero")
+	}
+
+	exchangeRate := &model.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          rate,
+	}
+	if err := s.exchangeRateRepo.Upsert(exchangeRate); err != nil {
+		return nil, fmt.Errorf("failed to set exchange rate: %w", err)
+	}
+
+	return s.exchangeRateRepo.GetRate(base, quote)
+}
+
+// DeleteExchangeRate removes the rate for converting from base to quote
+func (s *currencyService) DeleteExchangeRate(base, quote string) error {
+	if err := s.exchangeRateRepo.Delete(base, quote); err != nil {
+		return notFoundOr(err, "exchange rate not found")
+	}
+	return nil
+}
+
+// PriceIn resolves the price of a product in a currency
+func (s *currencyService) PriceIn(product *model.Product, currency string) (*ResolvedPrice, error) {
+	return resolveProductPrice(s.priceListRepo, s.exchangeRateRepo, product, currency)
+}
+
+// resolveProductPrice prices a product in a currency. In order of preference it
+// uses the product's own price when the currency matches, the price list entry
+// for the currency, or the product's price converted with an exchange rate
+// (stored in either direction). While a scheduled price is in effect the price
+// list is skipped and the scheduled price is converted instead.
+func resolveProductPrice(priceLists repository.PriceListRepository, rates repository.ExchangeRateRepository, product *model.Product, currency string) (*ResolvedPrice, error) {
+	if currency == "" || currency == product.Currency {
+		return &ResolvedPrice{Price: product.Price, Rate: money.OneRate, Source: PriceSourceBase}, nil
+	}
+
+	if product.ScheduledPrice == nil {
+		entry, err := priceLists.GetPrice(product.ID, currency)
+		if err == nil {
+			return &ResolvedPrice{Price: entry.Price, Rate: money.OneRate, Source: PriceSourcePriceList}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get list price: %w", err)
+		}
+	}
+
+	rate, err := findExchangeRate(rates, product.Currency, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResolvedPrice{
+		Price:  product.Price.Convert(rate, currency),
+		Rate:   rate,
+		Source: PriceSourceConverted,
+	}, nil
+}
+
+// resolveVariantPrice prices a product variant in a currency. Variants without
+// a price override are priced like their product; an override is used as is
+// when the currency matches and is otherwise converted with an exchange rate.
+func resolveVariantPrice(priceLists repository.PriceListRepository, rates repository.ExchangeRateRepository, product *model.Product, variant *model.ProductVariant, currency string) (*ResolvedPrice, error) {
+	if variant == nil || variant.Price == nil {
+		return resolveProductPrice(priceLists, rates, product, currency)
+	}
+
+	price := *variant.Price
+	if currency == "" || currency == price.Currency {
+		return &ResolvedPrice{Price: price, Rate: money.OneRate, Source: PriceSourceBase}, nil
+	}
+
+	rate, err := findExchangeRate(rates, price.Currency, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResolvedPrice{
+		Price:  price.Convert(rate, currency),
+		Rate:   rate,
+		Source: PriceSourceConverted,
+	}, nil
+}
+
+// findExchangeRate returns the rate for converting from base to quote, using the
+// inverse of the quote to base rate when only that one is maintained
+func findExchangeRate(rates repository.ExchangeRateRepository, base, quote string) (money.Rate, error) {
+	rate, err := rates.GetRate(base, quote)
+	if err == nil {
+		return rate.Rate, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	inverse, err := rates.GetRate(quote, base)
+	if err == nil {
+		return inverse.Rate.Inverse(), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	return 0, &apierrors.APIError{
+		Code:    apierrors.ErrBadRequest.Code,
+		Message: fmt.Sprintf("no price list entry or exchange rate for %s to %s", base, quote),
+	}
+}
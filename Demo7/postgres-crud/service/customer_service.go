@@ -0,0 +1,360 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CustomerService defines the interface for customer business logic
+type CustomerService interface {
+	CreateCustomer(name, email, phone string) (*model.Customer, error)
+	GetCustomerByID(id uint) (*model.Customer, error)
+	GetAllCustomers() ([]model.Customer, error)
+	UpdateCustomer(id uint, name, email, phone string, expectedVersion *uint) (*model.Customer, error)
+	DeleteCustomer(id uint, expectedVersion *uint) error
+	GetCustomerAddresses(customerID uint) ([]model.Address, error)
+	GetCustomerAddress(customerID, addressID uint) (*model.Address, error)
+	AddCustomerAddress(customerID uint, label string, address model.PostalAddress) (*model.Address, error)
+	UpdateCustomerAddress(customerID, addressID uint, label string, address model.PostalAddress) (*model.Address, error)
+	DeleteCustomerAddress(customerID, addressID uint) error
+}
+
+// customerService implements CustomerService interface
+type customerService struct {
+	customerRepo repository.CustomerRepository
+	addressRepo  repository.AddressRepository
+	uow          repository.UnitOfWork
+}
+
+// NewCustomerService creates a new instance of CustomerService
+func NewCustomerService(customerRepo repository.CustomerRepository, addressRepo repository.AddressRepository, uow repository.UnitOfWork) CustomerService {
+	return &customerService{
+		customerRepo: customerRepo,
+		addressRepo:  addressRepo,
+		uow:          uow,
+	}
+}
+
+// normalizeEmail trims and lower-cases an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ensureEmailFree returns a conflict error if a customer other than id already
+// uses email
+func ensureEmailFree(customers repository.CustomerRepository, email string, id uint) error {
+	exists, err := customers.EmailExists(email, id)
+	if err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if exists {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: "email already exists",
+			Details: fmt.Sprintf("email %q is used by another customer", email),
+		}
+	}
+	return nil
+}
+
+// ensureCustomerExists checks that an order is being placed for an existing
+// customer. A missing customer is reported as a bad request rather than as the
+// order not being found.
+func ensureCustomerExists(customers repository.CustomerRepository, customerID uint) error {
+	if customerID == 0 {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrBadRequest.Code,
+			Message: "customer is required",
+		}
+	}
+	if _, err := customers.GetByID(customerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrBadRequest.Code,
+				Message: fmt.Sprintf("customer %d not found", customerID),
+			}
+		}
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+	return nil
+}
+
+// CreateCustomer creates a new customer
+func (s *customerService) CreateCustomer(name, email, phone string) (*model.Customer, error) {
+	name = strings.TrimSpace(name)
+	email = normalizeEmail(email)
+	if name == "" {
+		return nil, fmt.Errorf("customer name cannot be empty")
+	}
+	if email == "" {
+		return nil, fmt.Errorf("email cannot be empty")
+	}
+
+	customer := &model.Customer{
+		Name:  name,
+		Email: email,
+		Phone: strings.TrimSpace(phone),
+	}
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		if err := ensureEmailFree(repos.Customers, email, 0); err != nil {
+			return err
+		}
+		if err := repos.Customers.Create(customer); err != nil {
+			return fmt.Errorf("failed to create customer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// GetCustomerByID retrieves a customer by its ID
+func (s *customerService) GetCustomerByID(id uint) (*model.Customer, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid customer ID")
+	}
+
+	customer, err := s.customerRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "customer not found")
+	}
+
+	return customer, nil
+}
+
+// GetAllCustomers retrieves all customers
+func (s *customerService) GetAllCustomers() ([]model.Customer, error) {
+	customers, err := s.customerRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all customers: %w", err)
+	}
+
+	return customers, nil
+}
+
+// UpdateCustomer updates a customer's contact details. A non-nil
+// expectedVersion must match the customer's current version.
+func (s *customerService) UpdateCustomer(id uint, name, email, phone string, expectedVersion *uint) (*model.Customer, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid customer ID")
+	}
+	name = strings.TrimSpace(name)
+	email = normalizeEmail(email)
+	if name == "" {
+		return nil, fmt.Errorf("customer name cannot be empty")
+	}
+	if email == "" {
+		return nil, fmt.Errorf("email cannot be empty")
+	}
+
+	var customer *model.Customer
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		customer, err = repos.Customers.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "customer not found")
+		}
+		if err := checkVersion(expectedVersion, customer.Version); err != nil {
+			return err
+		}
+		if err := ensureEmailFree(repos.Customers, email, id); err != nil {
+			return err
+		}
+
+		customer.Name = name
+		customer.Email = email
+		customer.Phone = strings.TrimSpace(phone)
+		if err := repos.Customers.Update(customer); err != nil {
+			return versionConflictOr(err, expectedVersion, "failed to update customer")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// DeleteCustomer deletes a customer that has no orders. A non-nil
+// expectedVersion must match the customer's current version.
+func (s *customerService) DeleteCustomer(id uint, expectedVersion *uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid customer ID")
+	}
+
+	return s.uow.Do(func(repos repository.Repositories) error {
+		customer, err := repos.Customers.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "customer not found")
+		}
+		if err := checkVersion(expectedVersion, customer.Version); err != nil {
+			return err
+		}
+
+		hasOrders, err := repos.Customers.HasOrders(id)
+		if err != nil {
+			return fmt.Errorf("failed to check customer orders: %w", err)
+		}
+		if hasOrders {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrConflict.Code,
+				Message: "customer has orders",
+				Details: "delete the customer's orders first",
+			}
+		}
+
+		if err := repos.Customers.DeleteByModel(customer); err != nil {
+			return versionConflictOr(err, expectedVersion, "failed to delete customer")
+		}
+		return nil
+	})
+}
+
+// normalizePostalAddress trims the lines of an address and upper-cases its
+// postal code and country
+func normalizePostalAddress(address model.PostalAddress) model.PostalAddress {
+	return model.PostalAddress{
+		Name:       strings.TrimSpace(address.Name),
+		Line1:      strings.TrimSpace(address.Line1),
+		Line2:      strings.TrimSpace(address.Line2),
+		City:       strings.TrimSpace(address.City),
+		Region:     strings.TrimSpace(address.Region),
+		PostalCode: strings.ToUpper(strings.TrimSpace(address.PostalCode)),
+		Country:    strings.ToUpper(strings.TrimSpace(address.Country)),
+	}
+}
+
+// validatePostalAddress checks that the required lines of an address are set
+func validatePostalAddress(address model.PostalAddress) error {
+	if address.Name == "" || address.Line1 == "" || address.City == "" || address.Country == "" {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrBadRequest.Code,
+			Message: "address requires a name, first line, city and country",
+		}
+	}
+	return nil
+}
+
+// getCustomerAddress loads a saved address, reporting addresses of other
+// customers as not found
+func getCustomerAddress(addresses repository.AddressRepository, customerID, addressID uint) (*model.Address, error) {
+	address, err := addresses.GetByID(addressID)
+	if err != nil {
+		return nil, notFoundOr(err, "address not found")
+	}
+	if address.CustomerID != customerID {
+		return nil, &apierrors.APIError{
+			Code:    apierrors.ErrNotFound.Code,
+			Message: "address not found",
+			Details: fmt.Sprintf("address %d does not belong to customer %d", addressID, customerID),
+		}
+	}
+	return address, nil
+}
+
+// GetCustomerAddresses retrieves the saved addresses of a customer
+func (s *customerService) GetCustomerAddresses(customerID uint) ([]model.Address, error) {
+	if customerID == 0 {
+		return nil, fmt.Errorf("invalid customer ID")
+	}
+
+	if _, err := s.customerRepo.GetByID(customerID); err != nil {
+		return nil, notFoundOr(err, "customer not found")
+	}
+
+	addresses, err := s.addressRepo.GetByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// GetCustomerAddress retrieves a saved address of a customer
+func (s *customerService) GetCustomerAddress(customerID, addressID uint) (*model.Address, error) {
+	if customerID == 0 || addressID == 0 {
+		return nil, fmt.Errorf("invalid customer or address ID")
+	}
+
+	return getCustomerAddress(s.addressRepo, customerID, addressID)
+}
+
+// AddCustomerAddress saves a new address for a customer
+func (s *customerService) AddCustomerAddress(customerID uint, label string, address model.PostalAddress) (*model.Address, error) {
+	if customerID == 0 {
+		return nil, fmt.Errorf("invalid customer ID")
+	}
+	address = normalizePostalAddress(address)
+	if err := validatePostalAddress(address); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.customerRepo.GetByID(customerID); err != nil {
+		return nil, notFoundOr(err, "customer not found")
+	}
+
+	saved := &model.Address{
+		CustomerID: customerID,
+		Label:      strings.TrimSpace(label),
+		Address:    address,
+	}
+	if err := s.addressRepo.Create(saved); err != nil {
+		return nil, fmt.Errorf("failed to create address: %w", err)
+	}
+
+	return saved, nil
+}
+
+// UpdateCustomerAddress replaces a saved address of a customer. Orders that
+// were shipped or billed to it keep their own copy.
+func (s *customerService) UpdateCustomerAddress(customerID, addressID uint, label string, address model.PostalAddress) (*model.Address, error) {
+	if customerID == 0 || addressID == 0 {
+		return nil, fmt.Errorf("invalid customer or address ID")
+	}
+	address = normalizePostalAddress(address)
+	if err := validatePostalAddress(address); err != nil {
+		return nil, err
+	}
+
+	saved, err := getCustomerAddress(s.addressRepo, customerID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved.Label = strings.TrimSpace(label)
+	saved.Address = address
+	if err := s.addressRepo.Update(saved); err != nil {
+		return nil, fmt.Errorf("failed to update address: %w", err)
+	}
+
+	return saved, nil
+}
+
+// DeleteCustomerAddress removes a saved address of a customer
+func (s *customerService) DeleteCustomerAddress(customerID, addressID uint) error {
+	if customerID == 0 || addressID == 0 {
+		return fmt.Errorf("invalid customer or address ID")
+	}
+
+	saved, err := getCustomerAddress(s.addressRepo, customerID, addressID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addressRepo.Delete(saved); err != nil {
+		return notFoundOr(err, "address not found")
+	}
+
+	return nil
+}
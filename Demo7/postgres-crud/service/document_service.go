@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"postgres-crud/document"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// invoiceSequenceName is the invoice sequence invoice numbers are taken from
+const invoiceSequenceName = "invoice"
+
+// DocumentSettings configures the documents rendered for orders. Company is
+// printed at the top of every document; invoice numbers are InvoicePrefix
+// followed by the sequence number.
+type DocumentSettings struct {
+	CompanyName    string
+	CompanyAddress []string
+	InvoicePrefix  string
+}
+
+// RenderedDocument is a document rendered for download
+type RenderedDocument struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// DocumentService defines the interface for issuing and rendering order
+// documents
+type DocumentService interface {
+	IssueInvoice(orderID uint) (invoice *model.Invoice, issued bool, err error)
+	GetInvoice(orderID uint, format document.Format) (*RenderedDocument, error)
+	GetPackingSlip(orderID uint, format document.Format) (*RenderedDocument, error)
+}
+
+// documentService implements DocumentService interface
+type documentService struct {
+	orderRepo    repository.OrderRepository
+	customerRepo repository.CustomerRepository
+	invoiceRepo  repository.InvoiceRepository
+	taxRepo      repository.TaxRepository
+	uow          repository.UnitOfWork
+	renderer     *document.Renderer
+	settings     DocumentSettings
+}
+
+// NewDocumentService creates a new instance of DocumentService
+func NewDocumentService(orderRepo repository.OrderRepository, customerRepo repository.CustomerRepository, invoiceRepo repository.InvoiceRepository, taxRepo repository.TaxRepository, uow repository.UnitOfWork, renderer *document.Renderer, settings DocumentSettings) DocumentService {
+	return &documentService{
+		orderRepo:    orderRepo,
+		customerRepo: customerRepo,
+		invoiceRepo:  invoiceRepo,
+		taxRepo:      taxRepo,
+		uow:          uow,
+		renderer:     renderer,
+		settings:     settings,
+	}
+}
+
+// IssueInvoice issues the invoice of a placed order, taking the next number
+// from the invoice sequence in the same transaction. An order is invoiced only
+// once: if it already has an invoice, that invoice is returned and issued is
+// false. Draft orders and orders cancelled before they were invoiced cannot be
+// invoiced.
+func (s *documentService) IssueInvoice(orderID uint) (*model.Invoice, bool, error) {
+	if orderID == 0 {
+		return nil, false, fmt.Errorf("invalid order ID")
+	}
+
+	var invoice *model.Invoice
+	issued := false
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		order, err := repos.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+
+		invoice, err = repos.Invoices.GetByOrderID(orderID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		invoice, err = s.issueInvoice(repos, order)
+		if err != nil {
+			return err
+		}
+		issued = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return invoice, issued, nil
+}
+
+// GetInvoice renders the invoice issued for an order from the order's line
+// snapshots and persisted tax lines. Orders that have not been invoiced yet
+// have no invoice to render.
+func (s *documentService) GetInvoice(orderID uint, format document.Format) (*RenderedDocument, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, notFoundOr(err, "order not found")
+	}
+	invoice, err := s.invoiceRepo.GetByOrderID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apierrors.APIError{
+				Code:    apierrors.ErrNotFound.Code,
+				Message: "order has not been invoiced",
+			}
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	items, err := s.orderRepo.GetItems(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	orderTaxLines, err := s.taxRepo.GetOrderTaxLines(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order tax lines: %w", err)
+	}
+	taxLines, err := invoiceTaxLines(orderTaxLines)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := s.orderCustomer(order)
+	doc := document.Invoice{
+		Number:        invoice.Number,
+		IssuedAt:      invoice.IssuedAt,
+		OrderID:       order.ID,
+		OrderDate:     order.CreatedAt,
+		Currency:      order.Currency,
+		Issuer:        s.issuer(),
+		BillTo:        addressParty(order.BillingAddress, customer),
+		Subtotal:      order.Subtotal,
+		DiscountTotal: order.DiscountTotal,
+		TaxLines:      taxLines,
+		TaxTotal:      order.TaxTotal,
+		GrandTotal:    order.GrandTotal,
+		CancelledAt:   order.CancelledAt,
+	}
+	if order.ShippingAddress != order.BillingAddress {
+		doc.ShipTo = addressParty(order.ShippingAddress, customer)
+	}
+	// Line amounts are gross, before the line discount, so that they add up
+	// to the subtotal; the line discounts are part of the discount total
+	for _, item := range items {
+		doc.Lines = append(doc.Lines, document.InvoiceLine{
+			Description:     item.ProductName,
+			SKU:             item.SKU,
+			Quantity:        item.Quantity,
+			UnitPrice:       item.Price,
+			DiscountPercent: item.DiscountPercent,
+			Amount:          item.LineTotal(),
+		})
+	}
+
+	return s.render(doc, format)
+}
+
+// issueInvoice records the invoice of an order under the next invoice number.
+// It is meant to run inside the unit of work that locked the order.
+func (s *documentService) issueInvoice(repos repository.Repositories, order *model.Order) (*model.Invoice, error) {
+	switch order.Status {
+	case model.OrderStatusDraft:
+		return nil, &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: "invoices are issued once the order is placed",
+		}
+	case model.OrderStatusCancelled:
+		return nil, &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: "the order was cancelled before it was invoiced",
+		}
+	}
+
+	sequence, err := repos.Invoices.NextSequence(invoiceSequenceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice number: %w", err)
+	}
+	invoice := &model.Invoice{
+		OrderID:  order.ID,
+		Number:   fmt.Sprintf("%s%06d", s.settings.InvoicePrefix, sequence),
+		Sequence: sequence,
+		IssuedAt: time.Now(),
+	}
+	if err := repos.Invoices.Create(invoice); err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// GetPackingSlip renders the packing slip of a placed order, listing its lines
+// with their quantities
+func (s *documentService) GetPackingSlip(orderID uint, format document.Format) (*RenderedDocument, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, notFoundOr(err, "order not found")
+	}
+	if order.Status == model.OrderStatusDraft || order.Status == model.OrderStatusCancelled {
+		return nil, &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: fmt.Sprintf("packing slips are only available for placed orders, order is %s", order.Status),
+		}
+	}
+
+	items, err := s.orderRepo.GetItems(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	shipping := order.ShippingAddress
+	if shipping.IsZero() {
+		shipping = order.BillingAddress
+	}
+	doc := document.PackingSlip{
+		OrderID:   order.ID,
+		OrderDate: order.CreatedAt,
+		PrintedAt: time.Now(),
+		Issuer:    s.issuer(),
+		ShipTo:    addressParty(shipping, s.orderCustomer(order)),
+	}
+	for _, item := range items {
+		doc.Lines = append(doc.Lines, document.PackingSlipLine{
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+		})
+		doc.TotalQuantity += item.Quantity
+	}
+
+	return s.render(doc, format)
+}
+
+// render renders a document for download
+func (s *documentService) render(doc document.Document, format document.Format) (*RenderedDocument, error) {
+	var content bytes.Buffer
+	if err := s.renderer.Render(&content, doc, format); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", doc.Template(), err)
+	}
+	return &RenderedDocument{
+		FileName:    doc.FileName() + "." + string(format),
+		ContentType: format.ContentType(),
+		Content:     content.Bytes(),
+	}, nil
+}
+
+// issuer returns the company documents are issued by
+func (s *documentService) issuer() document.Party {
+	return document.Party{
+		Name:    s.settings.CompanyName,
+		Address: s.settings.CompanyAddress,
+	}
+}
+
+// orderCustomer returns the customer who placed an order, or nil when the
+// order has none or the customer no longer exists
+func (s *documentService) orderCustomer(order *model.Order) *model.Customer {
+	if order.CustomerID == nil {
+		return nil
+	}
+	customer, err := s.customerRepo.GetByID(*order.CustomerID)
+	if err != nil {
+		return nil
+	}
+	return customer
+}
+
+// addressParty converts a postal address into the lines printed on a
+// document. The customer's name is used when the address has none.
+func addressParty(address model.PostalAddress, customer *model.Customer) document.Party {
+	party := document.Party{Name: address.Name}
+	if party.Name == "" && customer != nil {
+		party.Name = customer.Name
+	}
+
+	city := strings.TrimSpace(address.PostalCode + " " + address.City)
+	for _, line := range []string{address.Line1, address.Line2, city, address.Region, address.Country} {
+		if line != "" {
+			party.Address = append(party.Address, line)
+		}
+	}
+	return party
+}
+
+// invoiceTaxLines sums the tax lines of an order per tax class and rate, in the
+// order they first appear
+func invoiceTaxLines(lines []model.OrderTaxLine) ([]document.InvoiceTaxLine, error) {
+	var summary []document.InvoiceTaxLine
+	index := make(map[string]int)
+	for _, line := range lines {
+		key := fmt.Sprintf("%s/%v", line.TaxClass, line.Rate)
+		i, ok := index[key]
+		if !ok {
+			i = len(summary)
+			index[key] = i
+			summary = append(summary, document.InvoiceTaxLine{
+				TaxClass:      string(line.TaxClass),
+				Rate:          line.Rate,
+				TaxableAmount: line.TaxableAmount,
+				Tax:           line.Tax,
+			})
+			continue
+		}
+		taxable, err := summary[i].TaxableAmount.Add(line.TaxableAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sum tax lines: %w", err)
+		}
+		tax, err := summary[i].Tax.Add(line.Tax)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sum tax lines: %w", err)
+		}
+		summary[i].TaxableAmount = taxable
+		summary[i].Tax = tax
+	}
+	return summary, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/repository"
+
+	"gorm.io/gorm"
+)
+
+// notFoundOr wraps a repository error with the given message. Record-not-found
+// errors are converted into an API not-found error so handlers can return 404.
+func notFoundOr(err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrNotFound.Code,
+			Message: message,
+			Details: err.Error(),
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// checkVersion compares the version a client expects (taken from an If-Match
+// header) with the stored one. A nil expected version skips the check.
+func checkVersion(expected *uint, actual uint) error {
+	if expected != nil && *expected != actual {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrPreconditionFailed.Code,
+			Message: "resource has been modified",
+			Details: fmt.Sprintf("expected version %d, current version %d", *expected, actual),
+		}
+	}
+	return nil
+}
+
+// versionConflictOr wraps a repository error from a compare-and-swap write with
+// the given message. A lost race is reported as a failed precondition when the
+// client sent an expected version and as a conflict otherwise.
+func versionConflictOr(err error, expected *uint, message string) error {
+	if errors.Is(err, repository.ErrConcurrentModification) {
+		code := apierrors.ErrConflict.Code
+		if expected != nil {
+			code = apierrors.ErrPreconditionFailed.Code
+		}
+		return &apierrors.APIError{
+			Code:    code,
+			Message: "resource has been modified",
+			Details: err.Error(),
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
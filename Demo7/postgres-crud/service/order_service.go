@@ -1,54 +1,219 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	apierrors "postgres-crud/internal/errors"
 	"postgres-crud/model"
+	"postgres-crud/money"
 	"postgres-crud/repository"
+	"time"
 )
 
 // OrderService defines the interface for order business logic
 type OrderService interface {
-	CreateOrder(description string) (*model.Order, error)
+	CreateOrder(customerID uint, description, currency string, shipping, billing OrderAddress, lines []OrderLine) (*model.Order, error)
 	GetOrderByID(id uint) (*model.Order, error)
 	GetAllOrders() ([]model.Order, error)
 	GetOrdersByDescription(pattern string) ([]model.Order, error)
-	UpdateOrder(id uint, description string) (*model.Order, error)
+	UpdateOrder(id uint, description string, discountPercent *float64, expectedVersion *uint) (*model.Order, error)
 	UpdateOrderDescription(id uint, description string) error
-	DeleteOrder(id uint) error
+	DeleteOrder(id uint, expectedVersion *uint) error
 	GetOrdersByProductID(productID uint) ([]model.Order, error)
+	GetOrdersByCustomerID(customerID uint, withProducts bool) ([]model.Order, error)
 	GetOrdersWithProducts() ([]model.Order, error)
 	GetOrderByIDWithProducts(id uint) (*model.Order, error)
+	TransitionOrder(id uint, to model.OrderStatus, note string) (*model.Order, error)
+	CancelOrder(id uint, reason model.CancelReason, note string, expectedVersion *uint) (*model.Order, error)
+	GetOrderTransitions(id uint) ([]model.OrderStatusTransition, error)
+	QuoteOrder(order *model.Order) error
+}
+
+// OrderAddress selects an address for a new order: one of the customer's
+// saved addresses by SavedID, or an Address given inline. The zero value
+// selects no address.
+type OrderAddress struct {
+	SavedID *uint
+	Address *model.PostalAddress
+}
+
+// OrderLine is a line item of a new order. ProductID may be 0 when VariantID
+// is given.
+type OrderLine struct {
+	ProductID uint
+	VariantID uint
+	Quantity  int
 }
 
 // orderService implements OrderService interface
 type orderService struct {
-	repo repository.OrderRepository
+	repo         repository.OrderRepository
+	customerRepo repository.CustomerRepository
+	addressRepo  repository.AddressRepository
+	uow          repository.UnitOfWork
+	pricing      PricingEngine
+	refunds      RefundHook
 }
 
-// NewOrderService creates a new instance of OrderService
-func NewOrderService(repo repository.OrderRepository) OrderService {
+// NewOrderService creates a new instance of OrderService. refunds is notified
+// of every cancelled order.
+func NewOrderService(repo repository.OrderRepository, customerRepo repository.CustomerRepository, addressRepo repository.AddressRepository, uow repository.UnitOfWork, pricing PricingEngine, refunds RefundHook) OrderService {
 	return &orderService{
-		repo: repo,
+		repo:         repo,
+		customerRepo: customerRepo,
+		addressRepo:  addressRepo,
+		uow:          uow,
+		pricing:      pricing,
+		refunds:      refunds,
+	}
+}
+
+// resolveOrderAddress returns the address to copy onto a new order. A saved
+// address that is missing or belongs to another customer is a bad request.
+func resolveOrderAddress(addresses repository.AddressRepository, customerID uint, selection OrderAddress) (model.PostalAddress, error) {
+	if selection.SavedID != nil {
+		saved, err := getCustomerAddress(addresses, customerID, *selection.SavedID)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return model.PostalAddress{}, &apierrors.APIError{
+					Code:    apierrors.ErrBadRequest.Code,
+					Message: fmt.Sprintf("address %d not found for customer %d", *selection.SavedID, customerID),
+				}
+			}
+			return model.PostalAddress{}, err
+		}
+		return saved.Address, nil
+	}
+	if selection.Address != nil {
+		address := normalizePostalAddress(*selection.Address)
+		if err := validatePostalAddress(address); err != nil {
+			return model.PostalAddress{}, err
+		}
+		return address, nil
 	}
+	return model.PostalAddress{}, nil
 }
 
-// CreateOrder creates a new order with the given description
-func (s *orderService) CreateOrder(description string) (*model.Order, error) {
+// CreateOrder creates a new order for a customer with the given description.
+// Its lines are priced in currency, or in the default currency when none is
+// given. The shipping and billing addresses are copied onto the order; without
+// a billing address the shipping address is used. An unknown customer is
+// rejected as a bad request.
+//
+// The order is created together with its lines, and their quantities are
+// taken from stock, in one transaction. Every line is checked; if any of them
+// cannot be added, nothing is written and an *apierrors.LineErrors lists the
+// lines that failed and why.
+func (s *orderService) CreateOrder(customerID uint, description, currency string, shipping, billing OrderAddress, lines []OrderLine) (*model.Order, error) {
 	if description == "" {
 		return nil, fmt.Errorf("description cannot be empty")
 	}
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if err := validateOrderLines(lines); err != nil {
+		return nil, err
+	}
+	if err := ensureCustomerExists(s.customerRepo, customerID); err != nil {
+		return nil, err
+	}
+
+	shippingAddress, err := resolveOrderAddress(s.addressRepo, customerID, shipping)
+	if err != nil {
+		return nil, err
+	}
+	billingAddress, err := resolveOrderAddress(s.addressRepo, customerID, billing)
+	if err != nil {
+		return nil, err
+	}
+	if billingAddress.IsZero() {
+		billingAddress = shippingAddress
+	}
 
 	order := &model.Order{
-		Description: description,
+		CustomerID:      &customerID,
+		Description:     description,
+		Status:          model.OrderStatusDraft,
+		Currency:        currency,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddress,
+	}
+
+	if len(lines) == 0 {
+		if err := s.repo.Create(order); err != nil {
+			return nil, fmt.Errorf("failed to create order: %w", err)
+		}
+		return order, nil
 	}
 
-	if err := s.repo.Create(order); err != nil {
-		return nil, fmt.Errorf("failed to create order: %w", err)
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		if err := repos.Orders.Create(order); err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+
+		// Try every line so that all failures are reported at once
+		var failed []apierrors.LineError
+		for i, line := range lines {
+			if _, err := addOrderLine(repos, order, line.ProductID, line.VariantID, line.Quantity); err != nil {
+				failed = append(failed, orderLineError(i, line, err))
+			}
+		}
+		if len(failed) > 0 {
+			return &apierrors.LineErrors{
+				Message: fmt.Sprintf("%d of %d order lines could not be added", len(failed), len(lines)),
+				Lines:   failed,
+			}
+		}
+
+		if err := reevaluateOrderPromotions(repos, order); err != nil {
+			return err
+		}
+
+		order, err = repos.Orders.GetByIDWithProducts(order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reload order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return order, nil
 }
 
+// validateOrderLines checks the lines of a new order before anything is
+// written, reporting every invalid line
+func validateOrderLines(lines []OrderLine) error {
+	var failed []apierrors.LineError
+	for i, line := range lines {
+		switch {
+		case line.ProductID == 0 && line.VariantID == 0:
+			failed = append(failed, orderLineError(i, line, fmt.Errorf("product_id or variant_id is required")))
+		case line.Quantity <= 0:
+			failed = append(failed, orderLineError(i, line, fmt.Errorf("quantity must be greater than 0")))
+		}
+	}
+	if len(failed) > 0 {
+		return &apierrors.LineErrors{
+			Message: fmt.Sprintf("%d of %d order lines are invalid", len(failed), len(lines)),
+			Lines:   failed,
+		}
+	}
+	return nil
+}
+
+// orderLineError describes why a line of a new order failed
+func orderLineError(index int, line OrderLine, err error) apierrors.LineError {
+	return apierrors.LineError{
+		Index:     index,
+		ProductID: line.ProductID,
+		VariantID: line.VariantID,
+		Message:   err.Error(),
+	}
+}
+
 // GetOrderByID retrieves an order by its ID
 func (s *orderService) GetOrderByID(id uint) (*model.Order, error) {
 	if id == 0 {
@@ -83,8 +248,10 @@ func (s *orderService) GetOrdersByDescription(pattern string) ([]model.Order, er
 	return orders, nil
 }
 
-// UpdateOrder updates an order's description
-func (s *orderService) UpdateOrder(id uint, description string) (*model.Order, error) {
+// UpdateOrder updates an order's description and, while the order is a draft,
+// its order-level discount. A non-nil expectedVersion must match the order's
+// current version.
+func (s *orderService) UpdateOrder(id uint, description string, discountPercent *float64, expectedVersion *uint) (*model.Order, error) {
 	if id == 0 {
 		return nil, fmt.Errorf("invalid order ID")
 	}
@@ -92,15 +259,27 @@ func (s *orderService) UpdateOrder(id uint, description string) (*model.Order, e
 	if description == "" {
 		return nil, fmt.Errorf("description cannot be empty")
 	}
+	if discountPercent != nil && (*discountPercent < 0 || *discountPercent > 100) {
+		return nil, fmt.Errorf("discount percent must be between 0 and 100")
+	}
 
 	order, err := s.repo.GetByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("order not found: %w", err)
+		return nil, notFoundOr(err, "order not found")
+	}
+	if err := checkVersion(expectedVersion, order.Version); err != nil {
+		return nil, err
 	}
 
 	order.Description = description
+	if discountPercent != nil && *discountPercent != order.DiscountPercent {
+		if err := ensureOrderEditable(order); err != nil {
+			return nil, err
+		}
+		order.DiscountPercent = *discountPercent
+	}
 	if err := s.repo.Update(order); err != nil {
-		return nil, fmt.Errorf("failed to update order: %w", err)
+		return nil, versionConflictOr(err, expectedVersion, "failed to update order")
 	}
 
 	return order, nil
@@ -123,17 +302,35 @@ func (s *orderService) UpdateOrderDescription(id uint, description string) error
 	return nil
 }
 
-// DeleteOrder deletes an order by ID
-func (s *orderService) DeleteOrder(id uint) error {
+// DeleteOrder deletes an order by ID. Units still reserved by the order are
+// returned to stock in the same transaction. A non-nil expectedVersion must
+// match the order's current version.
+func (s *orderService) DeleteOrder(id uint, expectedVersion *uint) error {
 	if id == 0 {
 		return fmt.Errorf("invalid order ID")
 	}
 
-	if err := s.repo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete order: %w", err)
-	}
-
-	return nil
+	return s.uow.Do(func(repos repository.Repositories) error {
+		order, err := repos.Orders.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if err := checkVersion(expectedVersion, order.Version); err != nil {
+			return err
+		}
+
+		if orderHoldsStock(order.Status) {
+			if err := restockOrderItems(repos, order.ID, model.StockReasonOrderDeleted); err != nil {
+				return err
+			}
+		}
+
+		if err := repos.Orders.DeleteByModel(order); err != nil {
+			return versionConflictOr(err, expectedVersion, "failed to delete order")
+		}
+
+		return nil
+	})
 }
 
 // GetOrdersByProductID retrieves all orders that contain a specific product
@@ -150,6 +347,25 @@ func (s *orderService) GetOrdersByProductID(productID uint) ([]model.Order, erro
 	return orders, nil
 }
 
+// GetOrdersByCustomerID retrieves the orders placed by a customer, optionally
+// with their associated products
+func (s *orderService) GetOrdersByCustomerID(customerID uint, withProducts bool) ([]model.Order, error) {
+	if customerID == 0 {
+		return nil, fmt.Errorf("invalid customer ID")
+	}
+
+	if _, err := s.customerRepo.GetByID(customerID); err != nil {
+		return nil, notFoundOr(err, "customer not found")
+	}
+
+	orders, err := s.repo.GetOrdersByCustomerID(customerID, withProducts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders by customer ID: %w", err)
+	}
+
+	return orders, nil
+}
+
 // GetOrdersWithProducts retrieves all orders with their associated products
 func (s *orderService) GetOrdersWithProducts() ([]model.Order, error) {
 	orders, err := s.repo.GetOrdersWithProducts()
@@ -172,3 +388,196 @@ func (s *orderService) GetOrderByIDWithProducts(id uint) (*model.Order, error) {
 
 	return order, nil
 }
+
+// TransitionOrder moves an order to a new lifecycle status. Moves that the
+// lifecycle does not allow are rejected with an InvalidTransitionError.
+// Cancelling is done as by CancelOrder, with the reason "other".
+func (s *orderService) TransitionOrder(id uint, to model.OrderStatus, note string) (*model.Order, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if !IsValidOrderStatus(to) {
+		return nil, fmt.Errorf("invalid order status %q", to)
+	}
+
+	if to == model.OrderStatusCancelled {
+		return s.CancelOrder(id, model.CancelReasonOther, note, nil)
+	}
+
+	var order *model.Order
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		order, err = repos.Orders.GetByIDForUpdate(id)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+
+		from := order.Status
+		if !CanTransitionOrder(from, to) {
+			return &InvalidTransitionError{From: from, To: to}
+		}
+
+		// Placing an order freezes its totals
+		var columns []string
+		if to == model.OrderStatusPlaced {
+			items, err := repos.Orders.GetItems(order.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get order items: %w", err)
+			}
+			if len(items) == 0 {
+				return &apierrors.APIError{
+					Code:    apierrors.ErrConflict.Code,
+					Message: "cannot place an order without items",
+				}
+			}
+
+			// Promotions may have started, expired or been used up since
+			// the order was last changed
+			if err := reevaluateOrderPromotions(repos, order); err != nil {
+				return err
+			}
+			totals, err := s.pricing.CalculateForOrder(order, items)
+			if err != nil {
+				return err
+			}
+			totals.ApplyTo(order)
+			if err := repos.Taxes.ReplaceOrderTaxLines(order.ID, order.TaxLines); err != nil {
+				return fmt.Errorf("failed to save order tax lines: %w", err)
+			}
+			pricedAt := time.Now()
+			order.PricedAt = &pricedAt
+			columns = append(columns, "subtotal", "discount_total", "promotion_discount", "tax_rate", "tax_total", "grand_total", "priced_at")
+		}
+
+		return changeOrderStatus(repos, order, to, note, columns...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CancelOrder cancels an order that has not been shipped yet. The order is
+// kept with the reason and note and its units are returned to stock in one
+// transaction. Once that has committed, the refund hook is notified; a paid
+// order is refunded its grand total, other orders have nothing to refund. A
+// non-nil expectedVersion must match the order's current version.
+func (s *orderService) CancelOrder(id uint, reason model.CancelReason, note string, expectedVersion *uint) (*model.Order, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if !IsValidCancelReason(reason) {
+		return nil, fmt.Errorf("invalid cancel reason %q", reason)
+	}
+
+	var order *model.Order
+	var from model.OrderStatus
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		order, err = repos.Orders.GetByIDForUpdate(id)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if err := checkVersion(expectedVersion, order.Version); err != nil {
+			return err
+		}
+
+		from = order.Status
+		if !CanTransitionOrder(from, model.OrderStatusCancelled) {
+			return &InvalidTransitionError{From: from, To: model.OrderStatusCancelled}
+		}
+
+		cancelledAt := time.Now()
+		order.CancelReason = reason
+		order.CancelNote = note
+		order.CancelledAt = &cancelledAt
+		if err := changeOrderStatus(repos, order, model.OrderStatusCancelled, note, "cancel_reason", "cancel_note", "cancelled_at"); err != nil {
+			return err
+		}
+
+		// Cancelling an order returns its units to stock
+		return restockOrderItems(repos, order.ID, model.StockReasonOrderCancelled)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only paid orders have money to give back
+	amount := money.New(0, order.Currency)
+	if from == model.OrderStatusPaid {
+		amount = order.GrandTotal
+	}
+	refund := RefundRequest{
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		FromStatus: from,
+		Amount:     amount,
+		Reason:     reason,
+		Note:       note,
+	}
+	if err := s.refunds.RefundOrder(refund); err != nil {
+		// The cancellation stands; the refund has to be settled by hand
+		log.Printf("Order %d: refund of %s %s after cancellation failed: %v", order.ID, amount, amount.Currency, err)
+	}
+
+	return order, nil
+}
+
+// changeOrderStatus moves an order to a new status, writing any additional
+// columns with it, and records the transition. A concurrent status change is
+// reported as a conflict. It is meant to run inside a unit of work.
+func changeOrderStatus(repos repository.Repositories, order *model.Order, to model.OrderStatus, note string, columns ...string) error {
+	from := order.Status
+	order.Status = to
+	if err := repos.Orders.UpdateStatus(order, from, columns...); err != nil {
+		if errors.Is(err, repository.ErrConcurrentModification) {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrConflict.Code,
+				Message: "order status was changed by another request",
+				Details: err.Error(),
+			}
+		}
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	transition := &model.OrderStatusTransition{
+		OrderID:    order.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		Note:       note,
+	}
+	if err := repos.Orders.CreateTransition(transition); err != nil {
+		return fmt.Errorf("failed to record order transition: %w", err)
+	}
+	return nil
+}
+
+// GetOrderTransitions retrieves the status history of an order
+func (s *orderService) GetOrderTransitions(id uint) ([]model.OrderStatusTransition, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, notFoundOr(err, "order not found")
+	}
+
+	transitions, err := s.repo.GetTransitions(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order transitions: %w", err)
+	}
+
+	return transitions, nil
+}
+
+// QuoteOrder calculates the totals of an order from its loaded line items
+// without persisting them. It is used to show estimates for draft orders.
+func (s *orderService) QuoteOrder(order *model.Order) error {
+	totals, err := s.pricing.CalculateForOrder(order, order.Items)
+	if err != nil {
+		return err
+	}
+	totals.ApplyTo(order)
+	return nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Delivered and cancelled are terminal.
+var orderTransitions = map[model.OrderStatus][]model.OrderStatus{
+	model.OrderStatusDraft:   {model.OrderStatusPlaced, model.OrderStatusCancelled},
+	model.OrderStatusPlaced:  {model.OrderStatusPaid, model.OrderStatusCancelled},
+	model.OrderStatusPaid:    {model.OrderStatusShipped, model.OrderStatusCancelled},
+	model.OrderStatusShipped: {model.OrderStatusDelivered},
+}
+
+// IsValidOrderStatus reports whether status is a known order status
+func IsValidOrderStatus(status model.OrderStatus) bool {
+	switch status {
+	case model.OrderStatusDraft, model.OrderStatusPlaced, model.OrderStatusPaid,
+		model.OrderStatusShipped, model.OrderStatusDelivered, model.OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsValidCancelReason reports whether reason is a known cancellation reason
+func IsValidCancelReason(reason model.CancelReason) bool {
+	switch reason {
+	case model.CancelReasonCustomerRequest, model.CancelReasonOutOfStock, model.CancelReasonPaymentFailed,
+		model.CancelReasonFraudSuspected, model.CancelReasonDuplicateOrder, model.CancelReasonOther:
+		return true
+	}
+	return false
+}
+
+// CanTransitionOrder reports whether an order may move from one status to another
+func CanTransitionOrder(from, to model.OrderStatus) bool {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// orderHoldsStock reports whether the units on an order in the given status are
+// still reserved from stock, i.e. the goods have not left the warehouse
+func orderHoldsStock(status model.OrderStatus) bool {
+	switch status {
+	case model.OrderStatusDraft, model.OrderStatusPlaced, model.OrderStatusPaid:
+		return true
+	}
+	return false
+}
+
+// InvalidTransitionError is returned when an order status change is not allowed
+// by the order lifecycle
+type InvalidTransitionError struct {
+	From model.OrderStatus
+	To   model.OrderStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot transition order from %s to %s", e.From, e.To)
+}
+
+// Unwrap allows handlers to treat the error as a conflict
+func (e *InvalidTransitionError) Unwrap() error {
+	return apierrors.ErrConflict
+}
+
+// OrderNotEditableError is returned when the line items of an order that has
+// left the draft status are changed
+type OrderNotEditableError struct {
+	OrderID uint
+	Status  model.OrderStatus
+}
+
+func (e *OrderNotEditableError) Error() string {
+	return fmt.Sprintf("order %d is %s and can no longer be changed", e.OrderID, e.Status)
+}
+
+// Unwrap allows handlers to treat the error as a conflict
+func (e *OrderNotEditableError) Unwrap() error {
+	return apierrors.ErrConflict
+}
+
+// ensureOrderEditable returns an error unless the order is still a draft.
+// Callers that change the order's lines read it with GetByIDForUpdate, so the
+// status cannot change until their transaction ends.
+func ensureOrderEditable(order *model.Order) error {
+	if order.Status != model.OrderStatusDraft {
+		return &OrderNotEditableError{OrderID: order.ID, Status: order.Status}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"math"
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// basisPointsScale is the number of basis points in 100%
+const basisPointsScale = 10000
+
+// PricingLine is a single order line fed into the pricing engine.
+// Amounts are in minor currency units (cents).
+type PricingLine struct {
+	ProductID   uint
+	VariantID   uint
+	TaxClass    model.TaxClass
+	UnitPrice   int64
+	Quantity    int
+	DiscountBps int64
+}
+
+// PricingInput holds everything the pricing engine needs to price an order.
+// PromotionDiscount is the amount taken off by promotions, in minor units.
+// Country and Region are the destination the order is taxed for, at Date.
+type PricingInput struct {
+	Lines             []PricingLine
+	OrderDiscountBps  int64
+	PromotionDiscount int64
+	Currency          string
+	Country           string
+	Region            string
+	Date              time.Time
+}
+
+// PricedLine holds the computed figures for a single order line. Taxable is
+// the net amount less the line's share of the promotion and order discounts.
+type PricedLine struct {
+	Gross    int64
+	Discount int64
+	Net      int64
+	Taxable  int64
+}
+
+// OrderTotals holds the computed figures for an order, in minor currency units.
+// TaxRateBps is the effective tax rate over all lines.
+type OrderTotals struct {
+	Lines             []PricedLine
+	TaxLines          []TaxLine
+	Country           string
+	Region            string
+	Subtotal          int64
+	LineDiscounts     int64
+	PromotionDiscount int64
+	OrderDiscount     int64
+	DiscountTotal     int64
+	TaxableAmount     int64
+	TaxRateBps        int64
+	TaxTotal          int64
+	GrandTotal        int64
+}
+
+// PricingEngine calculates order totals from line items
+type PricingEngine interface {
+	Calculate(input PricingInput) (OrderTotals, error)
+	CalculateForOrder(order *model.Order, items []model.OrderProduct) (OrderTotals, error)
+}
+
+// pricingEngine implements PricingEngine, taxing lines with a TaxCalculator
+type pricingEngine struct {
+	taxes TaxCalculator
+}
+
+// NewPricingEngine creates a new instance of PricingEngine that taxes order
+// lines with taxes
+func NewPricingEngine(taxes TaxCalculator) PricingEngine {
+	return &pricingEngine{
+		taxes: taxes,
+	}
+}
+
+// Calculate prices an order. All arithmetic is done on integer minor units and
+// every rounding step rounds half away from zero, in this order:
+//
+//  1. each line discount is rounded on the line gross (unit price x quantity)
+//  2. promotion discounts, already in minor units, are taken off the sum of
+//     discounted lines, but never more than that sum
+//  3. the order discount is rounded on what remains
+//  4. the promotion and order discounts are spread over the lines in
+//     proportion to their net amounts, giving each line's taxable amount
+//  5. tax is rounded on each line by the tax calculator
+func (e *pricingEngine) Calculate(input PricingInput) (OrderTotals, error) {
+	totals := OrderTotals{
+		Lines:   make([]PricedLine, len(input.Lines)),
+		Country: input.Country,
+		Region:  input.Region,
+	}
+
+	var net int64
+	nets := make([]int64, len(input.Lines))
+	for i, line := range input.Lines {
+		gross := line.UnitPrice * int64(line.Quantity)
+		discount := applyBasisPoints(gross, line.DiscountBps)
+		totals.Lines[i] = PricedLine{
+			Gross:    gross,
+			Discount: discount,
+			Net:      gross - discount,
+		}
+		nets[i] = gross - discount
+		totals.Subtotal += gross
+		totals.LineDiscounts += discount
+		net += gross - discount
+	}
+
+	totals.PromotionDiscount = input.PromotionDiscount
+	if totals.PromotionDiscount > net {
+		totals.PromotionDiscount = net
+	}
+	if totals.PromotionDiscount < 0 {
+		totals.PromotionDiscount = 0
+	}
+	net -= totals.PromotionDiscount
+
+	totals.OrderDiscount = applyBasisPoints(net, input.OrderDiscountBps)
+	totals.DiscountTotal = totals.LineDiscounts + totals.PromotionDiscount + totals.OrderDiscount
+	totals.TaxableAmount = net - totals.OrderDiscount
+
+	shares := allocateProportionally(totals.PromotionDiscount+totals.OrderDiscount, nets)
+	taxable := make([]TaxableLine, len(input.Lines))
+	for i, line := range input.Lines {
+		totals.Lines[i].Taxable = nets[i] - shares[i]
+		taxable[i] = TaxableLine{
+			ProductID: line.ProductID,
+			VariantID: line.VariantID,
+			TaxClass:  line.TaxClass,
+			Amount:    totals.Lines[i].Taxable,
+		}
+	}
+
+	taxLines, err := e.taxes.CalculateTax(TaxRequest{
+		Currency: input.Currency,
+		Country:  input.Country,
+		Region:   input.Region,
+		Date:     input.Date,
+		Lines:    taxable,
+	})
+	if err != nil {
+		return OrderTotals{}, err
+	}
+	totals.TaxLines = taxLines
+	for _, line := range taxLines {
+		totals.TaxTotal += line.Tax
+	}
+	if totals.TaxableAmount > 0 {
+		totals.TaxRateBps = (totals.TaxTotal*basisPointsScale + totals.TaxableAmount/2) / totals.TaxableAmount
+	}
+	totals.GrandTotal = totals.TaxableAmount + totals.TaxTotal
+
+	return totals, nil
+}
+
+// CalculateForOrder prices an order from its persisted line items and the
+// promotions applied to it when it was last evaluated. Lines are taxed by the
+// tax class captured when they were added. The order is taxed for
+// its shipping address, or its billing address when it has none, at the
+// current rates.
+func (e *pricingEngine) CalculateForOrder(order *model.Order, items []model.OrderProduct) (OrderTotals, error) {
+	destination := order.ShippingAddress
+	if destination.IsZero() {
+		destination = order.BillingAddress
+	}
+
+	input := PricingInput{
+		Lines:            make([]PricingLine, len(items)),
+		OrderDiscountBps: percentToBasisPoints(order.DiscountPercent),
+		Currency:         order.Currency,
+		Country:          destination.Country,
+		Region:           destination.Region,
+		Date:             time.Now(),
+	}
+	for _, promotion := range order.Promotions {
+		if promotion.Applied {
+			input.PromotionDiscount += promotion.Discount.Amount
+		}
+	}
+	for i, item := range items {
+		input.Lines[i] = PricingLine{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			TaxClass:    item.TaxClass,
+			UnitPrice:   item.Price.Amount,
+			Quantity:    item.Quantity,
+			DiscountBps: percentToBasisPoints(item.DiscountPercent),
+		}
+	}
+	return e.Calculate(input)
+}
+
+// ApplyTo stores the computed figures on an order, in the order currency
+func (t OrderTotals) ApplyTo(order *model.Order) {
+	order.Subtotal = money.New(t.Subtotal, order.Currency)
+	order.DiscountTotal = money.New(t.DiscountTotal, order.Currency)
+	order.PromotionDiscount = money.New(t.PromotionDiscount, order.Currency)
+	order.TaxRate = float64(t.TaxRateBps) / 100
+	order.TaxTotal = money.New(t.TaxTotal, order.Currency)
+	order.GrandTotal = money.New(t.GrandTotal, order.Currency)
+
+	order.TaxLines = make([]model.OrderTaxLine, len(t.TaxLines))
+	for i, line := range t.TaxLines {
+		order.TaxLines[i] = model.OrderTaxLine{
+			OrderID:       order.ID,
+			ProductID:     line.ProductID,
+			VariantID:     line.VariantID,
+			TaxClass:      line.TaxClass,
+			Country:       strings.ToUpper(strings.TrimSpace(t.Country)),
+			Region:        normalizeTaxRegion(t.Region),
+			TaxableAmount: money.New(line.TaxableAmount, order.Currency),
+			Rate:          float64(line.RateBps) / 100,
+			Tax:           money.New(line.Tax, order.Currency),
+		}
+	}
+}
+
+// applyBasisPoints returns amount * bps / 10000 rounded half away from zero
+func applyBasisPoints(amount, bps int64) int64 {
+	product := amount * bps
+	if product < 0 {
+		return -((-product + basisPointsScale/2) / basisPointsScale)
+	}
+	return (product + basisPointsScale/2) / basisPointsScale
+}
+
+// allocateProportionally splits total over weights in proportion to each
+// weight. Rounding remainders go to the largest fractional shares, earliest
+// first, so the shares always add up to total.
+func allocateProportionally(total int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+	var sum int64
+	for _, weight := range weights {
+		sum += weight
+	}
+	if total == 0 || sum <= 0 {
+		return shares
+	}
+
+	remainders := make([]int64, len(weights))
+	allocated := int64(0)
+	for i, weight := range weights {
+		shares[i] = total * weight / sum
+		remainders[i] = total * weight % sum
+		allocated += shares[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; allocated < total; i++ {
+		shares[order[i%len(order)]]++
+		allocated++
+	}
+	return shares
+}
+
+// percentToBasisPoints converts a percentage with at most two decimals to basis points
+func percentToBasisPoints(percent float64) int64 {
+	return int64(math.Round(percent * 100))
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"testing"
+)
+
+// newTestPricingEngine returns a pricing engine that taxes every line at the
+// default rate, since the inputs have no destination to look rates up for
+func newTestPricingEngine(defaultRatePercent float64) PricingEngine {
+	return NewPricingEngine(NewTableTaxCalculator(nil, defaultRatePercent))
+}
+
+func TestApplyBasisPoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		bps    int64
+		want   int64
+	}{
+		{"zero amount", 0, 2500, 0},
+		{"zero rate", 12345, 0, 0},
+		{"exact", 2000, 1000, 200},
+		{"below half rounds down", 14, 1000, 1},
+		{"half rounds up", 5, 1000, 1},
+		{"half rounds up on odd cents", 15, 1000, 2},
+		{"above half rounds up", 16, 1000, 2},
+		{"negative below half rounds toward zero", -14, 1000, -1},
+		{"negative half rounds away from zero", -5, 1000, -1},
+		{"negative half on odd cents rounds away from zero", -15, 1000, -2},
+		{"negative above half rounds away from zero", -16, 1000, -2},
+		{"fractional rate", 12345, 825, 1018},
+		{"fractional rate at half", 2000, 825, 165},
+		{"full rate", 999, basisPointsScale, 999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyBasisPoints(tt.amount, tt.bps); got != tt.want {
+				t.Errorf("applyBasisPoints(%d, %d) = %d, want %d", tt.amount, tt.bps, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateProportionally(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{"nothing to allocate", 0, []int64{100, 200}, []int64{0, 0}},
+		{"no weights", 100, []int64{0, 0}, []int64{0, 0}},
+		{"single line takes all", 333, []int64{1000}, []int64{333}},
+		{"even split", 100, []int64{500, 500}, []int64{50, 50}},
+		{"tie goes to the earliest line", 1, []int64{500, 500}, []int64{1, 0}},
+		{"remainder goes to the largest fraction", 733, []int64{3998, 467}, []int64{656, 77}},
+		{"three way split", 100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateProportionally(tt.total, tt.weights)
+			if len(got) != len(tt.want) {
+				t.Fatalf("allocateProportionally(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("allocateProportionally(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPricingEngineCalculate(t *testing.T) {
+	tests := []struct {
+		name        string
+		taxRate     float64
+		input       PricingInput
+		want        OrderTotals
+		wantTaxable []int64
+		wantTax     []int64
+	}{
+		{
+			name:    "line discount, then order discount, then tax",
+			taxRate: 8.25,
+			input: PricingInput{
+				Lines: []PricingLine{
+					{ProductID: 1, UnitPrice: 1999, Quantity: 3, DiscountBps: 1000},
+				},
+				OrderDiscountBps: 500,
+			},
+			// 5997 gross, 599.7 -> 600 off the line, 5% of 5397 = 269.85 -> 270
+			// off the order, 8.25% of 5127 = 422.9775 -> 423 tax
+			want: OrderTotals{
+				Subtotal:      5997,
+				LineDiscounts: 600,
+				OrderDiscount: 270,
+				DiscountTotal: 870,
+				TaxableAmount: 5127,
+				TaxRateBps:    825,
+				TaxTotal:      423,
+				GrandTotal:    5550,
+			},
+			wantTaxable: []int64{5127},
+			wantTax:     []int64{423},
+		},
+		{
+			name:    "promotion and order discounts are split over lines by net amount",
+			taxRate: 10,
+			input: PricingInput{
+				Lines: []PricingLine{
+					{ProductID: 1, UnitPrice: 1000, Quantity: 3},
+					{ProductID: 2, UnitPrice: 1000, Quantity: 1},
+				},
+				OrderDiscountBps:  1000,
+				PromotionDiscount: 100,
+			},
+			// 100 promotion, 10% of 3900 = 390 order discount; 490 split 3:1
+			// is 367.5 and 122.5, the tie going to the first line
+			want: OrderTotals{
+				Subtotal:          4000,
+				PromotionDiscount: 100,
+				OrderDiscount:     390,
+				DiscountTotal:     490,
+				TaxableAmount:     3510,
+				TaxRateBps:        1000,
+				TaxTotal:          351,
+				GrandTotal:        3861,
+			},
+			wantTaxable: []int64{2632, 878},
+			wantTax:     []int64{263, 88},
+		},
+		{
+			name:    "promotion is capped at the discounted lines",
+			taxRate: 10,
+			input: PricingInput{
+				Lines: []PricingLine{
+					{ProductID: 1, UnitPrice: 1500, Quantity: 2, DiscountBps: 5000},
+				},
+				OrderDiscountBps:  1000,
+				PromotionDiscount: 5000,
+			},
+			want: OrderTotals{
+				Subtotal:          3000,
+				LineDiscounts:     1500,
+				PromotionDiscount: 1500,
+				DiscountTotal:     3000,
+			},
+			wantTaxable: []int64{0},
+			wantTax:     []int64{0},
+		},
+		{
+			name:    "exempt lines are not taxed",
+			taxRate: 20,
+			input: PricingInput{
+				Lines: []PricingLine{
+					{ProductID: 1, UnitPrice: 1250, Quantity: 1},
+					{ProductID: 2, TaxClass: model.TaxClassExempt, UnitPrice: 1250, Quantity: 1},
+				},
+			},
+			want: OrderTotals{
+				Subtotal:      2500,
+				TaxableAmount: 2500,
+				TaxRateBps:    1000,
+				TaxTotal:      250,
+				GrandTotal:    2750,
+			},
+			wantTaxable: []int64{1250, 1250},
+			wantTax:     []int64{250, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestPricingEngine(tt.taxRate).Calculate(tt.input)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			checks := []struct {
+				field     string
+				got, want int64
+			}{
+				{"Subtotal", got.Subtotal, tt.want.Subtotal},
+				{"LineDiscounts", got.LineDiscounts, tt.want.LineDiscounts},
+				{"PromotionDiscount", got.PromotionDiscount, tt.want.PromotionDiscount},
+				{"OrderDiscount", got.OrderDiscount, tt.want.OrderDiscount},
+				{"DiscountTotal", got.DiscountTotal, tt.want.DiscountTotal},
+				{"TaxableAmount", got.TaxableAmount, tt.want.TaxableAmount},
+				{"TaxRateBps", got.TaxRateBps, tt.want.TaxRateBps},
+				{"TaxTotal", got.TaxTotal, tt.want.TaxTotal},
+				{"GrandTotal", got.GrandTotal, tt.want.GrandTotal},
+			}
+			for _, c := range checks {
+				if c.got != c.want {
+					t.Errorf("%s = %d, want %d", c.field, c.got, c.want)
+				}
+			}
+
+			for i, line := range got.Lines {
+				if line.Taxable != tt.wantTaxable[i] {
+					t.Errorf("Lines[%d].Taxable = %d, want %d", i, line.Taxable, tt.wantTaxable[i])
+				}
+			}
+			for i, line := range got.TaxLines {
+				if line.Tax != tt.wantTax[i] {
+					t.Errorf("TaxLines[%d].Tax = %d, want %d", i, line.Tax, tt.wantTax[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPricingEngineTotalsReconcile(t *testing.T) {
+	engine := newTestPricingEngine(8.25)
+
+	for unitPrice := int64(1); unitPrice <= 2001; unitPrice += 37 {
+		for _, discountBps := range []int64{0, 333, 1250, 5000} {
+			for _, orderDiscountBps := range []int64{0, 500, 1575} {
+				for _, promotion := range []int64{0, 1, 99, 100000} {
+					input := PricingInput{
+						Lines: []PricingLine{
+							{ProductID: 1, UnitPrice: unitPrice, Quantity: 3, DiscountBps: discountBps},
+							{ProductID: 2, UnitPrice: unitPrice + 5, Quantity: 1},
+							{ProductID: 3, TaxClass: model.TaxClassExempt, UnitPrice: 2*unitPrice + 1, Quantity: 2, DiscountBps: discountBps},
+						},
+						OrderDiscountBps:  orderDiscountBps,
+						PromotionDiscount: promotion,
+					}
+					totals, err := engine.Calculate(input)
+					if err != nil {
+						t.Fatalf("Calculate(%+v) error = %v", input, err)
+					}
+
+					if totals.Subtotal-totals.DiscountTotal+totals.TaxTotal != totals.GrandTotal {
+						t.Fatalf("%+v: subtotal %d - discounts %d + tax %d != grand total %d",
+							input, totals.Subtotal, totals.DiscountTotal, totals.TaxTotal, totals.GrandTotal)
+					}
+
+					var taxable, tax int64
+					for _, line := range totals.Lines {
+						taxable += line.Taxable
+					}
+					for _, line := range totals.TaxLines {
+						tax += line.Tax
+					}
+					if taxable != totals.TaxableAmount {
+						t.Fatalf("%+v: line taxable amounts add up to %d, want %d", input, taxable, totals.TaxableAmount)
+					}
+					if tax != totals.TaxTotal {
+						t.Fatalf("%+v: line taxes add up to %d, want %d", input, tax, totals.TaxTotal)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPricingEngineCalculateForOrder(t *testing.T) {
+	order := &model.Order{
+		Currency:        "USD",
+		DiscountPercent: 12.5,
+		Promotions: []model.OrderPromotion{
+			{PromotionID: 1, Applied: true, Discount: money.New(200, "USD")},
+			{PromotionID: 2, Applied: false, Discount: money.New(500, "USD")},
+		},
+	}
+	items := []model.OrderProduct{
+		{ProductID: 1, Quantity: 2, Price: money.New(1999, "USD")},
+		{ProductID: 2, Quantity: 1, Price: money.New(550, "USD"), DiscountPercent: 15,
+			TaxClass: model.TaxClassExempt, Product: model.Product{TaxClass: model.TaxClassStandard}},
+	}
+
+	totals, err := newTestPricingEngine(8).CalculateForOrder(order, items)
+	if err != nil {
+		t.Fatalf("CalculateForOrder() error = %v", err)
+	}
+	totals.ApplyTo(order)
+
+	// 4548 gross, 82.5 -> 83 off the second line, 200 promotion (the
+	// unapplied one is ignored), 12.5% of 4265 = 533.125 -> 533 off the
+	// order, 8% of the first line's 3342 = 267.36 -> 267 tax
+	want := map[string]money.Money{
+		"Subtotal":          money.New(4548, "USD"),
+		"DiscountTotal":     money.New(816, "USD"),
+		"PromotionDiscount": money.New(200, "USD"),
+		"TaxTotal":          money.New(267, "USD"),
+		"GrandTotal":        money.New(3999, "USD"),
+	}
+	got := map[string]money.Money{
+		"Subtotal":          order.Subtotal,
+		"DiscountTotal":     order.DiscountTotal,
+		"PromotionDiscount": order.PromotionDiscount,
+		"TaxTotal":          order.TaxTotal,
+		"GrandTotal":        order.GrandTotal,
+	}
+	for field, w := range want {
+		if got[field] != w {
+			t.Errorf("%s = %v, want %v", field, got[field], w)
+		}
+	}
+	if order.TaxRate != 7.15 {
+		t.Errorf("TaxRate = %v, want 7.15", order.TaxRate)
+	}
+
+	if len(order.TaxLines) != 2 {
+		t.Fatalf("got %d tax lines, want 2", len(order.TaxLines))
+	}
+	wantLines := []struct {
+		taxable, tax int64
+	}{
+		{3342, 267},
+		{390, 0},
+	}
+	for i, w := range wantLines {
+		line := order.TaxLines[i]
+		if line.TaxableAmount.Amount != w.taxable || line.Tax.Amount != w.tax {
+			t.Errorf("TaxLines[%d] = %v taxable, %v tax; want %d, %d", i, line.TaxableAmount, line.Tax, w.taxable, w.tax)
+		}
+	}
+}
@@ -1,67 +1,193 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	apierrors "postgres-crud/internal/errors"
 	"postgres-crud/model"
+	"postgres-crud/money"
 	"postgres-crud/repository"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// ProductFilter holds the criteria for filtering products
+type ProductFilter = repository.ProductFilter
+
 // ProductService defines the interface for product business logic
 type ProductService interface {
-	CreateProduct(name, description string, price float64, stock int) (*model.Product, error)
+	CreateProduct(name, description string, price money.Money, stock int, taxClass model.TaxClass, categoryID *uint, tags []string) (*model.Product, error)
 	GetProductByID(id uint) (*model.Product, error)
+	GetProductAt(id uint, at time.Time) (*model.Product, error)
+	GetPriceHistory(id uint) (*model.Product, []model.ProductPrice, error)
 	GetAllProducts() ([]model.Product, error)
 	GetProductsByName(pattern string) ([]model.Product, error)
-	UpdateProduct(id uint, name, description string, price float64, stock int) (*model.Product, error)
-	DeleteProduct(id uint) error
-	AddProductToOrder(orderID uint, productID uint, quantity int) error
-	RemoveProductFromOrder(orderID uint, productID uint) error
+	UpdateProduct(id uint, name, description string, price money.Money, stock int, taxClass model.TaxClass, categoryID *uint, tags []string, expectedVersion *uint) (*model.Product, error)
+	DeleteProduct(id uint, expectedVersion *uint) error
+	AddProductToOrder(orderID uint, productID uint, variantID uint, quantity int) (*model.OrderProduct, error)
+	RemoveProductFromOrder(orderID uint, productID uint, variantID uint) error
 	GetOrderProducts(orderID uint) ([]model.Product, error)
-	FilterProducts(name, description string, minPrice, maxPrice *float64, minStock, maxStock *int) ([]model.Product, error)
+	GetOrderItems(orderID uint) ([]model.OrderProduct, error)
+	GetOrderItem(orderID uint, productID uint, variantID uint) (*model.OrderProduct, error)
+	UpdateOrderItem(orderID uint, productID uint, variantID uint, quantity int, discountPercent *float64) (*model.OrderProduct, error)
+	FilterProducts(filter ProductFilter) ([]model.Product, error)
 	GetProductsWithOrders() ([]model.Product, error)
 }
 
 // productService implements ProductService interface
 type productService struct {
-	productRepo repository.ProductRepository
-	orderRepo   repository.OrderRepository
+	productRepo      repository.ProductRepository
+	orderRepo        repository.OrderRepository
+	priceHistoryRepo repository.PriceHistoryRepository
+	scheduleRepo     repository.ScheduledPriceRepository
+	uow              repository.UnitOfWork
 }
 
 // NewProductService creates a new instance of ProductService
-func NewProductService(productRepo repository.ProductRepository, orderRepo repository.OrderRepository) ProductService {
+func NewProductService(productRepo repository.ProductRepository, orderRepo repository.OrderRepository, priceHistoryRepo repository.PriceHistoryRepository, scheduleRepo repository.ScheduledPriceRepository, uow repository.UnitOfWork) ProductService {
 	return &productService{
-		productRepo: productRepo,
-		orderRepo:   orderRepo,
+		productRepo:      productRepo,
+		orderRepo:        orderRepo,
+		priceHistoryRepo: priceHistoryRepo,
+		scheduleRepo:     scheduleRepo,
+		uow:              uow,
 	}
 }
 
-// CreateProduct creates a new product
-func (s *productService) CreateProduct(name, description string, price float64, stock int) (*model.Product, error) {
+// recordProductPrice appends the current price of a product to its price
+// history, effective from the given time. It is meant to run inside a unit of
+// work.
+func recordProductPrice(repos repository.Repositories, product *model.Product, from time.Time) error {
+	entry := &model.ProductPrice{
+		ProductID:     product.ID,
+		Price:         product.Price,
+		EffectiveFrom: from,
+	}
+	if err := repos.PriceHistory.Record(entry); err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
+	}
+	return nil
+}
+
+// CreateProduct creates a new product and records its initial stock in the
+// stock ledger. Tags that do not exist yet are created. An empty taxClass
+// defaults to the standard class.
+func (s *productService) CreateProduct(name, description string, price money.Money, stock int, taxClass model.TaxClass, categoryID *uint, tags []string) (*model.Product, error) {
 	if name == "" {
 		return nil, fmt.Errorf("product name cannot be empty")
 	}
-	if price < 0 {
+	if price.IsNegative() {
 		return nil, fmt.Errorf("product price cannot be negative")
 	}
+	if price.Currency == "" {
+		price.Currency = money.DefaultCurrency
+	}
 	if stock < 0 {
 		return nil, fmt.Errorf("product stock cannot be negative")
 	}
+	if taxClass == "" {
+		taxClass = model.TaxClassStandard
+	}
+	if !IsValidTaxClass(taxClass) {
+		return nil, fmt.Errorf("invalid tax class %q", taxClass)
+	}
+
+	if categoryID != nil && *categoryID == 0 {
+		categoryID = nil
+	}
 
 	product := &model.Product{
 		Name:        name,
 		Description: description,
 		Price:       price,
-		Stock:       stock,
+		TaxClass:    taxClass,
+		CategoryID:  categoryID,
+	}
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		if err := ensureCategoryExists(repos, categoryID); err != nil {
+			return err
+		}
+
+		var err error
+		if err = repos.Products.Create(product); err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+		if err := recordProductPrice(repos, product, product.CreatedAt); err != nil {
+			return err
+		}
+		if err := setProductTags(repos, product.ID, tags); err != nil {
+			return err
+		}
+		movement := &model.StockMovement{
+			ProductID:     product.ID,
+			Delta:         stock,
+			Reason:        model.StockReasonInitial,
+			ReferenceType: model.StockReferenceProduct,
+			ReferenceID:   &product.ID,
+			Actor:         model.StockActorSystem,
+		}
+		if err := applyStockMovement(repos, movement); err != nil {
+			return fmt.Errorf("failed to record initial stock: %w", err)
+		}
+
+		// Reload to pick up the stock and version written by the movement
+		product, err = repos.Products.GetByID(product.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reload product: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.productRepo.Create(product); err != nil {
-		return nil, fmt.Errorf("failed to create product: %w", err)
+	if err := applyScheduledPrice(s.scheduleRepo, product, time.Now()); err != nil {
+		return nil, err
 	}
 
 	return product, nil
 }
 
-// GetProductByID retrieves a product by its ID
+// ensureCategoryExists checks that a product is being put into an existing
+// category. A nil or zero ID means no category.
+func ensureCategoryExists(repos repository.Repositories, categoryID *uint) error {
+	if categoryID == nil || *categoryID == 0 {
+		return nil
+	}
+	if _, err := repos.Categories.GetByID(*categoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrBadRequest.Code,
+				Message: fmt.Sprintf("category %d not found", *categoryID),
+			}
+		}
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+	return nil
+}
+
+// setProductTags replaces the tags of a product, creating tags that do not
+// exist yet. A nil slice leaves the tags unchanged.
+func setProductTags(repos repository.Repositories, productID uint, names []string) error {
+	names = normalizeTags(names)
+	if names == nil {
+		return nil
+	}
+
+	tags, err := repos.Tags.FindOrCreate(names)
+	if err != nil {
+		return fmt.Errorf("failed to create tags: %w", err)
+	}
+	if err := repos.Tags.ReplaceProductTags(productID, tags); err != nil {
+		return fmt.Errorf("failed to set product tags: %w", err)
+	}
+	return nil
+}
+
+// GetProductByID retrieves a product by its ID, priced with the scheduled
+// price currently in effect, if any
 func (s *productService) GetProductByID(id uint) (*model.Product, error) {
 	if id == 0 {
 		return nil, fmt.Errorf("invalid product ID")
@@ -72,9 +198,60 @@ func (s *productService) GetProductByID(id uint) (*model.Product, error) {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 
+	if err := applyScheduledPrice(s.scheduleRepo, product, time.Now()); err != nil {
+		return nil, err
+	}
+
 	return product, nil
 }
 
+// GetProductAt retrieves a product with the price it had at a given time,
+// including any scheduled price in effect then. Times before the product's
+// first recorded price are reported as not found.
+func (s *productService) GetProductAt(id uint, at time.Time) (*model.Product, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	entry, err := s.priceHistoryRepo.GetAt(id, at)
+	if err != nil {
+		return nil, notFoundOr(err, "no price recorded for the product at that time")
+	}
+	product.Price = entry.Price
+	product.Currency = entry.Currency
+
+	if err := applyScheduledPrice(s.scheduleRepo, product, at); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// GetPriceHistory retrieves a product together with its price history, oldest
+// first
+func (s *productService) GetPriceHistory(id uint) (*model.Product, []model.ProductPrice, error) {
+	if id == 0 {
+		return nil, nil, fmt.Errorf("invalid product ID")
+	}
+
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "product not found")
+	}
+
+	entries, err := s.priceHistoryRepo.GetByProductID(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	return product, entries, nil
+}
+
 // GetAllProducts retrieves all products
 func (s *productService) GetAllProducts() ([]model.Product, error) {
 	products, err := s.productRepo.GetAll()
@@ -82,6 +259,10 @@ func (s *productService) GetAllProducts() ([]model.Product, error) {
 		return nil, fmt.Errorf("failed to get all products: %w", err)
 	}
 
+	if err := applyScheduledPrices(s.scheduleRepo, products, time.Now()); err != nil {
+		return nil, err
+	}
+
 	return products, nil
 }
 
@@ -92,11 +273,18 @@ func (s *productService) GetProductsByName(pattern string) ([]model.Product, err
 		return nil, fmt.Errorf("failed to get products by name: %w", err)
 	}
 
+	if err := applyScheduledPrices(s.scheduleRepo, products, time.Now()); err != nil {
+		return nil, err
+	}
+
 	return products, nil
 }
 
-// UpdateProduct updates a product
-func (s *productService) UpdateProduct(id uint, name, description string, price float64, stock int) (*model.Product, error) {
+// UpdateProduct updates a product. A change of stock is recorded in the stock
+// ledger in the same transaction. A nil categoryID or tags slice leaves the
+// category or tags unchanged; a zero categoryID removes the category. An empty
+// taxClass leaves the tax class unchanged.
+func (s *productService) UpdateProduct(id uint, name, description string, price money.Money, stock int, taxClass model.TaxClass, categoryID *uint, tags []string, expectedVersion *uint) (*model.Product, error) {
 	if id == 0 {
 		return nil, fmt.Errorf("invalid product ID")
 	}
@@ -104,87 +292,240 @@ func (s *productService) UpdateProduct(id uint, name, description string, price
 	if name == "" {
 		return nil, fmt.Errorf("product name cannot be empty")
 	}
-	if price < 0 {
+	if price.IsNegative() {
 		return nil, fmt.Errorf("product price cannot be negative")
 	}
 	if stock < 0 {
 		return nil, fmt.Errorf("product stock cannot be negative")
 	}
-
-	product, err := s.productRepo.GetByID(id)
+	if taxClass != "" && !IsValidTaxClass(taxClass) {
+		return nil, fmt.Errorf("invalid tax class %q", taxClass)
+	}
+
+	var product *model.Product
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		product, err = repos.Products.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "product not found")
+		}
+		if err := checkVersion(expectedVersion, product.Version); err != nil {
+			return err
+		}
+
+		product.Name = name
+		product.Description = description
+		if price.Currency == "" {
+			price.Currency = product.Currency
+		}
+		priceChanged := price != product.Price
+		product.Price = price
+		if taxClass != "" {
+			product.TaxClass = taxClass
+		}
+		if categoryID != nil {
+			if err := ensureCategoryExists(repos, categoryID); err != nil {
+				return err
+			}
+			product.CategoryID = categoryID
+			if *categoryID == 0 {
+				product.CategoryID = nil
+			}
+		}
+
+		if err := repos.Products.Update(product); err != nil {
+			return versionConflictOr(err, expectedVersion, "failed to update product")
+		}
+		if priceChanged {
+			if err := recordProductPrice(repos, product, product.UpdatedAt); err != nil {
+				return err
+			}
+		}
+
+		// Stock is only changed through the stock ledger
+		if delta := stock - product.Stock; delta != 0 {
+			movement := &model.StockMovement{
+				ProductID:     product.ID,
+				Delta:         delta,
+				Reason:        model.StockReasonProductUpdate,
+				ReferenceType: model.StockReferenceProduct,
+				ReferenceID:   &product.ID,
+				Actor:         model.StockActorSystem,
+			}
+			if err := applyStockMovement(repos, movement); err != nil {
+				return fmt.Errorf("failed to update product stock: %w", err)
+			}
+		}
+
+		if err := setProductTags(repos, id, tags); err != nil {
+			return err
+		}
+
+		// Reload to pick up the stock and version written by the movement and
+		// the current tags
+		product, err = repos.Products.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("failed to reload product: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("product not found: %w", err)
+		return nil, err
 	}
 
-	product.Name = name
-	product.Description = description
-	product.Price = price
-	product.Stock = stock
-
-	if err := s.productRepo.Update(product); err != nil {
-		return nil, fmt.Errorf("failed to update product: %w", err)
+	if err := applyScheduledPrice(s.scheduleRepo, product, time.Now()); err != nil {
+		return nil, err
 	}
 
 	return product, nil
 }
 
-// DeleteProduct deletes a product by ID
-func (s *productService) DeleteProduct(id uint) error {
+// DeleteProduct deletes a product by ID. A non-nil expectedVersion must match
+// the product's current version.
+func (s *productService) DeleteProduct(id uint, expectedVersion *uint) error {
 	if id == 0 {
 		return fmt.Errorf("invalid product ID")
 	}
 
-	if err := s.productRepo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete product: %w", err)
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return notFoundOr(err, "product not found")
+	}
+	if err := checkVersion(expectedVersion, product.Version); err != nil {
+		return err
+	}
+
+	if err := s.productRepo.DeleteByModel(product); err != nil {
+		return versionConflictOr(err, expectedVersion, "failed to delete product")
 	}
 
 	return nil
 }
 
-// AddProductToOrder adds a product to an order
-func (s *productService) AddProductToOrder(orderID uint, productID uint, quantity int) error {
+// AddProductToOrder adds a product, or one of its variants, to an order and
+// takes the quantity from stock. The product is taken from the variant when
+// productID is 0. Products that have variants can only be ordered by variant.
+// The line is priced with the scheduled price in effect, if any.
+func (s *productService) AddProductToOrder(orderID uint, productID uint, variantID uint, quantity int) (*model.OrderProduct, error) {
 	if orderID == 0 {
-		return fmt.Errorf("invalid order ID")
+		return nil, fmt.Errorf("invalid order ID")
 	}
-	if productID == 0 {
-		return fmt.Errorf("invalid product ID")
+	if productID == 0 && variantID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
 	}
 	if quantity <= 0 {
-		return fmt.Errorf("quantity must be greater than 0")
+		return nil, fmt.Errorf("quantity must be greater than 0")
+	}
+
+	var item *model.OrderProduct
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Verify order exists and can still be changed
+		order, err := repos.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if err := ensureOrderEditable(order); err != nil {
+			return err
+		}
+
+		line, err := addOrderLine(repos, order, productID, variantID, quantity)
+		if err != nil {
+			return err
+		}
+
+		if err := reevaluateOrderPromotions(repos, order); err != nil {
+			return err
+		}
+
+		item, err = repos.Orders.GetItem(orderID, line.ProductID, line.VariantID)
+		if err != nil {
+			return fmt.Errorf("failed to get order item: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Verify order exists
-	_, err := s.orderRepo.GetByID(orderID)
+	return item, nil
+}
+
+// addOrderLine adds a line for a product, or one of its variants, to an order
+// and takes the quantity from stock. The product is taken from the variant
+// when productID is 0. The line is priced in the order currency, with the
+// scheduled price in effect if any, and keeps a snapshot of the product. It is
+// meant to run inside a unit of work; the order's promotions are not
+// re-evaluated.
+func addOrderLine(repos repository.Repositories, order *model.Order, productID uint, variantID uint, quantity int) (*model.OrderProduct, error) {
+	// Verify variant and product exist and belong together
+	var variant *model.ProductVariant
+	if variantID != 0 {
+		var err error
+		variant, err = repos.Variants.GetByID(variantID)
+		if err != nil {
+			return nil, fmt.Errorf("variant not found: %w", err)
+		}
+		if productID == 0 {
+			productID = variant.ProductID
+		} else if productID != variant.ProductID {
+			return nil, fmt.Errorf("variant %d does not belong to product %d", variantID, productID)
+		}
+	}
+
+	product, err := repos.Products.GetByID(productID)
 	if err != nil {
-		return fmt.Errorf("order not found: %w", err)
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+	if variant == nil {
+		count, err := repos.Variants.CountByProductID(productID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check product variants: %w", err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("product %d has variants: a variant_id is required", productID)
+		}
 	}
 
-	// Verify product exists and check stock
-	product, err := s.productRepo.GetByID(productID)
+	// Price the line in the order currency
+	if err := applyScheduledPrice(repos.Schedules, product, time.Now()); err != nil {
+		return nil, err
+	}
+	price, err := resolveVariantPrice(repos.PriceLists, repos.ExchangeRates, product, variant, order.Currency)
 	if err != nil {
-		return fmt.Errorf("product not found: %w", err)
+		return nil, err
 	}
 
-	if product.Stock < quantity {
-		return fmt.Errorf("insufficient stock: available %d, requested %d", product.Stock, quantity)
+	// Add product to order with current price and details
+	line := &model.OrderProduct{
+		OrderID:            order.ID,
+		ProductID:          productID,
+		VariantID:          variantID,
+		ProductName:        product.Name,
+		ProductDescription: product.Description,
+		TaxClass:           product.TaxClass,
+		Quantity:           quantity,
+		Price:              price.Price,
+		ExchangeRate:       price.Rate,
 	}
-
-	// Add product to order with current price
-	if err := s.productRepo.AddProductToOrder(orderID, productID, quantity, product.Price); err != nil {
-		return fmt.Errorf("failed to add product to order: %w", err)
+	if variant != nil {
+		line.SKU = variant.SKU
+	}
+	if err := repos.Products.AddProductToOrder(line); err != nil {
+		return nil, fmt.Errorf("failed to add product to order: %w", err)
 	}
 
-	// Update product stock
-	product.Stock -= quantity
-	if err := s.productRepo.Update(product); err != nil {
-		return fmt.Errorf("failed to update product stock: %w", err)
+	// Take the quantity from stock; this fails if not enough is left
+	movement := orderStockMovement(order.ID, productID, variantID, -quantity, model.StockReasonOrderLineAdded)
+	if err := applyStockMovement(repos, movement); err != nil {
+		return nil, fmt.Errorf("failed to update product stock: %w", err)
 	}
 
-	return nil
+	return line, nil
 }
 
-// RemoveProductFromOrder removes a product from an order
-func (s *productService) RemoveProductFromOrder(orderID uint, productID uint) error {
+// RemoveProductFromOrder removes the line for a product variant from an order
+// and returns its quantity to stock
+func (s *productService) RemoveProductFromOrder(orderID uint, productID uint, variantID uint) error {
 	if orderID == 0 {
 		return fmt.Errorf("invalid order ID")
 	}
@@ -192,11 +533,31 @@ func (s *productService) RemoveProductFromOrder(orderID uint, productID uint) er
 		return fmt.Errorf("invalid product ID")
 	}
 
-	if err := s.productRepo.RemoveProductFromOrder(orderID, productID); err != nil {
-		return fmt.Errorf("failed to remove product from order: %w", err)
-	}
+	return s.uow.Do(func(repos repository.Repositories) error {
+		order, err := repos.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if err := ensureOrderEditable(order); err != nil {
+			return err
+		}
 
-	return nil
+		item, err := repos.Orders.GetItem(orderID, productID, variantID)
+		if err != nil {
+			return notFoundOr(err, "order item not found")
+		}
+
+		if err := repos.Products.RemoveProductFromOrder(orderID, productID, variantID); err != nil {
+			return fmt.Errorf("failed to remove product from order: %w", err)
+		}
+
+		movement := orderStockMovement(orderID, productID, variantID, item.Quantity, model.StockReasonOrderLineRemoved)
+		if err := applyStockMovement(repos, movement); err != nil {
+			return fmt.Errorf("failed to restore product stock: %w", err)
+		}
+
+		return reevaluateOrderPromotions(repos, order)
+	})
 }
 
 // GetOrderProducts retrieves all products for an order
@@ -210,15 +571,115 @@ func (s *productService) GetOrderProducts(orderID uint) ([]model.Product, error)
 		return nil, fmt.Errorf("failed to get order products: %w", err)
 	}
 
+	if err := applyScheduledPrices(s.scheduleRepo, products, time.Now()); err != nil {
+		return nil, err
+	}
+
 	return products, nil
 }
 
-// FilterProducts filters products based on multiple criteria
-func (s *productService) FilterProducts(name, description string, minPrice, maxPrice *float64, minStock, maxStock *int) ([]model.Product, error) {
-	products, err := s.productRepo.FilterProducts(name, description, minPrice, maxPrice, minStock, maxStock)
+// GetOrderItems retrieves the line items of an order with quantity and captured price
+func (s *productService) GetOrderItems(orderID uint) ([]model.OrderProduct, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	if _, err := s.orderRepo.GetByID(orderID); err != nil {
+		return nil, notFoundOr(err, "order not found")
+	}
+
+	items, err := s.orderRepo.GetItems(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	return items, nil
+}
+
+// GetOrderItem retrieves a single line item of an order
+func (s *productService) GetOrderItem(orderID uint, productID uint, variantID uint) (*model.OrderProduct, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	item, err := s.orderRepo.GetItem(orderID, productID, variantID)
+	if err != nil {
+		return nil, notFoundOr(err, "order item not found")
+	}
+
+	return item, nil
+}
+
+// UpdateOrderItem changes the quantity and, optionally, the line discount of an
+// existing order line. Additional units are taken from stock and removed units
+// are returned to it.
+func (s *productService) UpdateOrderItem(orderID uint, productID uint, variantID uint, quantity int, discountPercent *float64) (*model.OrderProduct, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be greater than 0")
+	}
+	if discountPercent != nil && (*discountPercent < 0 || *discountPercent > 100) {
+		return nil, fmt.Errorf("discount percent must be between 0 and 100")
+	}
+
+	var item *model.OrderProduct
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		order, err := repos.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if err := ensureOrderEditable(order); err != nil {
+			return err
+		}
+
+		item, err = repos.Orders.GetItem(orderID, productID, variantID)
+		if err != nil {
+			return notFoundOr(err, "order item not found")
+		}
+
+		delta := quantity - item.Quantity
+		item.Quantity = quantity
+		if discountPercent != nil {
+			item.DiscountPercent = *discountPercent
+		}
+		if err := repos.Products.UpdateOrderItem(item); err != nil {
+			return fmt.Errorf("failed to update order item: %w", err)
+		}
+
+		movement := orderStockMovement(orderID, productID, variantID, -delta, model.StockReasonOrderLineChanged)
+		if err := applyStockMovement(repos, movement); err != nil {
+			return fmt.Errorf("failed to update product stock: %w", err)
+		}
+
+		return reevaluateOrderPromotions(repos, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// FilterProducts filters products based on multiple criteria. Price filters
+// match the price currently in effect, scheduled or regular.
+func (s *productService) FilterProducts(filter ProductFilter) ([]model.Product, error) {
+	filter.Tags = normalizeTags(filter.Tags)
+	filter.PricedAt = time.Now()
+	products, err := s.productRepo.FilterProducts(filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to filter products: %w", err)
 	}
+	if err := applyScheduledPrices(s.scheduleRepo, products, filter.PricedAt); err != nil {
+		return nil, err
+	}
 	return products, nil
 }
 
@@ -228,7 +689,8 @@ func (s *productService) GetProductsWithOrders() ([]model.Product, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get products with orders: %w", err)
 	}
+	if err := applyScheduledPrices(s.scheduleRepo, products, time.Now()); err != nil {
+		return nil, err
+	}
 	return products, nil
 }
-
-
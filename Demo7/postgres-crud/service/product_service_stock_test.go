@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"postgres-crud/database"
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"postgres-crud/repository"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// connectTestDatabase connects to the Postgres database named by
+// TEST_DATABASE_URL and migrates it, or skips the test when none is set. The
+// database is shared with other runs, so tests only look at rows they create.
+func connectTestDatabase(t *testing.T) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get test database connection: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(20)
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+	})
+
+	if err := database.Migrate(&model.OrderProduct{}, &model.Customer{}, &model.Address{}, &model.Order{}, &model.Product{}, &model.OrderStatusTransition{}, &model.StockMovement{}, &model.PriceListEntry{}, &model.ExchangeRate{}, &model.Category{}, &model.Tag{}, &model.ProductVariant{}, &model.Promotion{}, &model.OrderPromotion{}, &model.TaxRate{}, &model.OrderTaxLine{}, &model.ProductPrice{}, &model.ScheduledPrice{}, &model.Return{}, &model.ReturnLine{}, &model.Invoice{}, &model.InvoiceSequence{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	if err := database.EnsurePrimaryKey("order_products", "order_id", "product_id", "variant_id"); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+}
+
+// TestAddProductToOrderConcurrentStock fires many more concurrent order line
+// additions at one product than it has stock for. Exactly the stock must be
+// sold, the stock must never go below zero, and the ledger must still add up
+// to the product's stock.
+func TestAddProductToOrderConcurrentStock(t *testing.T) {
+	connectTestDatabase(t)
+
+	const (
+		startingStock = 150
+		orders        = 10
+		attempts      = 400
+	)
+
+	uow := repository.NewUnitOfWork()
+	orderRepo := repository.NewOrderRepository()
+	productRepo := repository.NewProductRepository()
+	stockRepo := repository.NewStockMovementRepository()
+	products := NewProductService(productRepo, orderRepo, repository.NewPriceHistoryRepository(), repository.NewScheduledPriceRepository(), uow)
+
+	name := fmt.Sprintf("stock race %d", time.Now().UnixNano())
+	product, err := products.CreateProduct(name, "", money.New(1000, "USD"), startingStock, model.TaxClassStandard, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+
+	orderIDs := make([]uint, orders)
+	for i := range orderIDs {
+		order := &model.Order{
+			Description: fmt.Sprintf("%s order %d", name, i),
+			Status:      model.OrderStatusDraft,
+			Currency:    "USD",
+		}
+		if err := orderRepo.Create(order); err != nil {
+			t.Fatalf("failed to create order: %v", err)
+		}
+		orderIDs[i] = order.ID
+	}
+
+	// Watch the stock while the orders come in
+	var lowest atomic.Int64
+	lowest.Store(startingStock)
+	stop := make(chan struct{})
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			current, err := productRepo.GetByID(product.ID)
+			if err != nil {
+				continue
+			}
+			if int64(current.Stock) < lowest.Load() {
+				lowest.Store(int64(current.Stock))
+			}
+		}
+	}()
+
+	var sold, rejected atomic.Int64
+	var unexpected sync.Map
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(orderID uint) {
+			defer wg.Done()
+			<-start
+			_, err := products.AddProductToOrder(orderID, product.ID, 0, 1)
+			switch {
+			case err == nil:
+				sold.Add(1)
+			case errors.Is(err, repository.ErrInsufficientStock):
+				rejected.Add(1)
+			default:
+				unexpected.Store(err.Error(), struct{}{})
+			}
+		}(orderIDs[i%orders])
+	}
+	close(start)
+	wg.Wait()
+	close(stop)
+	<-watched
+
+	unexpected.Range(func(message, _ interface{}) bool {
+		t.Errorf("AddProductToOrder() unexpected error: %v", message)
+		return true
+	})
+	if got := sold.Load(); got != startingStock {
+		t.Errorf("%d additions succeeded, want %d", got, startingStock)
+	}
+	if got := rejected.Load(); got != attempts-startingStock {
+		t.Errorf("%d additions were rejected for insufficient stock, want %d", got, attempts-startingStock)
+	}
+	if got := lowest.Load(); got < 0 {
+		t.Errorf("stock went down to %d", got)
+	}
+
+	final, err := productRepo.GetByID(product.ID)
+	if err != nil {
+		t.Fatalf("failed to reload product: %v", err)
+	}
+	if final.Stock != 0 {
+		t.Errorf("final stock = %d, want 0", final.Stock)
+	}
+	balance, err := stockRepo.GetLedgerBalance(product.ID)
+	if err != nil {
+		t.Fatalf("GetLedgerBalance() error = %v", err)
+	}
+	if balance != final.Stock {
+		t.Errorf("ledger balance = %d, product stock = %d", balance, final.Stock)
+	}
+
+	var ordered int
+	for _, orderID := range orderIDs {
+		item, err := orderRepo.GetItem(orderID, product.ID, 0)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("GetItem() error = %v", err)
+		}
+		ordered += item.Quantity
+	}
+	if ordered != startingStock {
+		t.Errorf("orders hold %d units, want %d", ordered, startingStock)
+	}
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"postgres-crud/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PromotionService defines the interface for promotion business logic
+type PromotionService interface {
+	CreatePromotion(promotion model.Promotion) (*model.Promotion, error)
+	GetPromotionByID(id uint) (*model.Promotion, error)
+	GetAllPromotions() ([]model.Promotion, error)
+	UpdatePromotion(id uint, promotion model.Promotion, expectedVersion *uint) (*model.Promotion, error)
+	DeletePromotion(id uint, expectedVersion *uint) error
+	ApplyCoupon(orderID uint, code string) (*model.Order, error)
+	RemoveCoupon(orderID uint, code string) (*model.Order, error)
+}
+
+// promotionService implements PromotionService interface
+type promotionService struct {
+	promotionRepo repository.PromotionRepository
+	uow           repository.UnitOfWork
+}
+
+// NewPromotionService creates a new instance of PromotionService
+func NewPromotionService(promotionRepo repository.PromotionRepository, uow repository.UnitOfWork) PromotionService {
+	return &promotionService{
+		promotionRepo: promotionRepo,
+		uow:           uow,
+	}
+}
+
+// normalizeCouponCode trims and upper-cases a coupon code
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validatePromotion checks that a promotion has the settings its type needs
+func validatePromotion(promotion model.Promotion) error {
+	if strings.TrimSpace(promotion.Name) == "" {
+		return fmt.Errorf("promotion name cannot be empty")
+	}
+
+	switch promotion.Type {
+	case model.PromotionTypePercentage:
+		if promotion.PercentOff <= 0 || promotion.PercentOff > 100 {
+			return fmt.Errorf("percent off must be greater than 0 and at most 100")
+		}
+	case model.PromotionTypeFixedAmount:
+		if promotion.AmountOff.Amount <= 0 {
+			return fmt.Errorf("amount off must be greater than zero")
+		}
+	case model.PromotionTypeBuyXGetY:
+		if promotion.ProductID == nil || *promotion.ProductID == 0 {
+			return fmt.Errorf("buy X get Y promotions require a product")
+		}
+		if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+			return fmt.Errorf("buy and get quantities must be greater than 0")
+		}
+	default:
+		return fmt.Errorf("invalid promotion type %q", promotion.Type)
+	}
+
+	if promotion.MinOrderValue.IsNegative() {
+		return fmt.Errorf("minimum order value cannot be negative")
+	}
+	if promotion.UsageLimitPerCustomer < 0 {
+		return fmt.Errorf("usage limit per customer cannot be negative")
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return fmt.Errorf("promotion must end after it starts")
+	}
+	return nil
+}
+
+// ensureCouponCodeFree returns a conflict error if a promotion other than id
+// already uses code
+func ensureCouponCodeFree(promotions repository.PromotionRepository, code string, id uint) error {
+	if code == "" {
+		return nil
+	}
+	exists, err := promotions.CodeExists(code, id)
+	if err != nil {
+		return fmt.Errorf("failed to check coupon code: %w", err)
+	}
+	if exists {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: fmt.Sprintf("coupon code %q is used by another promotion", code),
+		}
+	}
+	return nil
+}
+
+// CreatePromotion creates a new promotion
+func (s *promotionService) CreatePromotion(promotion model.Promotion) (*model.Promotion, error) {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	promotion.Code = normalizeCouponCode(promotion.Code)
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	promotion.ID = 0
+	promotion.Version = 0
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		if err := ensureCouponCodeFree(repos.Promotions, promotion.Code, 0); err != nil {
+			return err
+		}
+		if err := repos.Promotions.Create(&promotion); err != nil {
+			return fmt.Errorf("failed to create promotion: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+// GetPromotionByID retrieves a promotion by its ID
+func (s *promotionService) GetPromotionByID(id uint) (*model.Promotion, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid promotion ID")
+	}
+
+	promotion, err := s.promotionRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "promotion not found")
+	}
+
+	return promotion, nil
+}
+
+// GetAllPromotions retrieves all promotions
+func (s *promotionService) GetAllPromotions() ([]model.Promotion, error) {
+	promotions, err := s.promotionRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all promotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// UpdatePromotion replaces the settings of a promotion. Orders it was applied
+// to pick up the change when they are next evaluated. A non-nil
+// expectedVersion must match the promotion's current version.
+func (s *promotionService) UpdatePromotion(id uint, promotion model.Promotion, expectedVersion *uint) (*model.Promotion, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid promotion ID")
+	}
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	promotion.Code = normalizeCouponCode(promotion.Code)
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	var updated *model.Promotion
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		updated, err = repos.Promotions.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "promotion not found")
+		}
+		if err := checkVersion(expectedVersion, updated.Version); err != nil {
+			return err
+		}
+		if err := ensureCouponCodeFree(repos.Promotions, promotion.Code, id); err != nil {
+			return err
+		}
+
+		promotion.ID = updated.ID
+		promotion.Version = updated.Version
+		promotion.CreatedAt = updated.CreatedAt
+		*updated = promotion
+		if err := repos.Promotions.Update(updated); err != nil {
+			return versionConflictOr(err, expectedVersion, "failed to update promotion")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeletePromotion deletes a promotion. Orders it was applied to lose it when
+// they are next evaluated. A non-nil expectedVersion must match the
+// promotion's current version.
+func (s *promotionService) DeletePromotion(id uint, expectedVersion *uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid promotion ID")
+	}
+
+	promotion, err := s.promotionRepo.GetByID(id)
+	if err != nil {
+		return notFoundOr(err, "promotion not found")
+	}
+	if err := checkVersion(expectedVersion, promotion.Version); err != nil {
+		return err
+	}
+
+	if err := s.promotionRepo.DeleteByModel(promotion); err != nil {
+		return versionConflictOr(err, expectedVersion, "failed to delete promotion")
+	}
+
+	return nil
+}
+
+// ApplyCoupon applies a coupon to a draft order. The coupon is rejected, and
+// not kept on the order, if it does not currently give a discount.
+func (s *promotionService) ApplyCoupon(orderID uint, code string) (*model.Order, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	code = normalizeCouponCode(code)
+	if code == "" {
+		return nil, fmt.Errorf("coupon code cannot be empty")
+	}
+
+	var order *model.Order
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		order, err = repos.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if err := ensureOrderEditable(order); err != nil {
+			return err
+		}
+
+		coupon, err := repos.Promotions.GetByCode(code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &apierrors.APIError{
+					Code:    apierrors.ErrBadRequest.Code,
+					Message: fmt.Sprintf("coupon %q not found", code),
+				}
+			}
+			return fmt.Errorf("failed to get coupon: %w", err)
+		}
+
+		coupons, err := orderCoupons(repos, orderID)
+		if err != nil {
+			return err
+		}
+		for _, applied := range coupons {
+			if applied.ID == coupon.ID {
+				return &apierrors.APIError{
+					Code:    apierrors.ErrConflict.Code,
+					Message: fmt.Sprintf("coupon %q is already applied to the order", code),
+				}
+			}
+		}
+
+		promotions, err := refreshOrderPromotions(repos, order, append(coupons, *coupon), time.Now())
+		if err != nil {
+			return err
+		}
+		for _, promotion := range promotions {
+			if promotion.PromotionID == coupon.ID && !promotion.Applied {
+				return &apierrors.APIError{
+					Code:    apierrors.ErrConflict.Code,
+					Message: fmt.Sprintf("coupon %q does not apply to the order: %s", code, promotion.Reason),
+				}
+			}
+		}
+
+		order, err = repos.Orders.GetByIDWithProducts(orderID)
+		if err != nil {
+			return fmt.Errorf("failed to reload order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// RemoveCoupon removes a coupon from a draft order
+func (s *promotionService) RemoveCoupon(orderID uint, code string) (*model.Order, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	code = normalizeCouponCode(code)
+
+	var order *model.Order
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		order, err = repos.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if err := ensureOrderEditable(order); err != nil {
+			return err
+		}
+
+		coupons, err := orderCoupons(repos, orderID)
+		if err != nil {
+			return err
+		}
+		remaining := coupons[:0]
+		for _, coupon := range coupons {
+			if coupon.Code != code {
+				remaining = append(remaining, coupon)
+			}
+		}
+		if len(remaining) == len(coupons) {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrNotFound.Code,
+				Message: fmt.Sprintf("coupon %q is not applied to the order", code),
+			}
+		}
+
+		if _, err := refreshOrderPromotions(repos, order, remaining, time.Now()); err != nil {
+			return err
+		}
+
+		order, err = repos.Orders.GetByIDWithProducts(orderID)
+		if err != nil {
+			return fmt.Errorf("failed to reload order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// orderCoupons returns the coupons applied to an order that still exist
+func orderCoupons(repos repository.Repositories, orderID uint) ([]model.Promotion, error) {
+	recorded, err := repos.Promotions.GetOrderPromotions(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order promotions: %w", err)
+	}
+
+	var coupons []model.Promotion
+	for _, promotion := range recorded {
+		if promotion.Promotion.ID != 0 && promotion.Promotion.IsCoupon() {
+			coupons = append(coupons, promotion.Promotion)
+		}
+	}
+	return coupons, nil
+}
+
+// reevaluateOrderPromotions evaluates the promotions of an order again after
+// its line items changed, keeping the coupons applied to it
+func reevaluateOrderPromotions(repos repository.Repositories, order *model.Order) error {
+	coupons, err := orderCoupons(repos, order.ID)
+	if err != nil {
+		return err
+	}
+	_, err = refreshOrderPromotions(repos, order, coupons, time.Now())
+	return err
+}
+
+// hasCustomerUsageLimit reports whether any of the promotions limits how often
+// a customer can use it
+func hasCustomerUsageLimit(promotions []model.Promotion) bool {
+	for _, promotion := range promotions {
+		if promotion.UsageLimitPerCustomer > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshOrderPromotions evaluates the given coupons and all automatic
+// promotions against the current line items of an order and records the
+// outcome on the order. Coupons are recorded whether or not they apply;
+// automatic promotions only when they do. The recorded promotions are also
+// set on order.Promotions, and the order's version is incremented since its
+// content changed.
+func refreshOrderPromotions(repos repository.Repositories, order *model.Order, coupons []model.Promotion, now time.Time) ([]model.OrderPromotion, error) {
+	items, err := repos.Orders.GetItems(order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	automatic, err := repos.Promotions.GetAutomatic()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promotions: %w", err)
+	}
+	previous, err := repos.Promotions.GetOrderPromotions(order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order promotions: %w", err)
+	}
+
+	candidates := append(append([]model.Promotion{}, coupons...), automatic...)
+	ctx := PromotionContext{
+		Currency:   order.Currency,
+		CustomerID: order.CustomerID,
+		Usage:      make(map[uint]int),
+		Now:        now,
+	}
+	if order.CustomerID != nil && hasCustomerUsageLimit(candidates) {
+		// Lock the customer so that their orders are checked against the
+		// limits one at a time
+		if _, err := repos.Customers.GetByIDForUpdate(*order.CustomerID); err != nil {
+			return nil, fmt.Errorf("failed to lock customer: %w", err)
+		}
+		for _, promotion := range candidates {
+			if promotion.UsageLimitPerCustomer == 0 {
+				continue
+			}
+			used, err := repos.Promotions.CountCustomerUsage(promotion.ID, *order.CustomerID, order.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count promotion usage: %w", err)
+			}
+			ctx.Usage[promotion.ID] = used
+		}
+	}
+
+	createdAt := make(map[uint]time.Time, len(previous))
+	for _, promotion := range previous {
+		createdAt[promotion.PromotionID] = promotion.CreatedAt
+	}
+
+	var recorded []model.OrderPromotion
+	for _, result := range EvaluatePromotions(candidates, promotionLines(items), ctx) {
+		if !result.Applied && !result.Promotion.IsCoupon() {
+			continue
+		}
+		recorded = append(recorded, model.OrderPromotion{
+			OrderID:     order.ID,
+			PromotionID: result.Promotion.ID,
+			Code:        result.Promotion.Code,
+			Name:        result.Promotion.Name,
+			Applied:     result.Applied,
+			Reason:      result.Reason,
+			Discount:    money.New(result.Discount, order.Currency),
+			CreatedAt:   createdAt[result.Promotion.ID],
+		})
+	}
+
+	if err := repos.Promotions.ReplaceOrderPromotions(order.ID, recorded); err != nil {
+		return nil, fmt.Errorf("failed to record order promotions: %w", err)
+	}
+
+	if err := repos.Orders.IncrementVersion(order); err != nil {
+		return nil, versionConflictOr(err, nil, "failed to update order version")
+	}
+
+	order.Promotions = recorded
+	return recorded, nil
+}
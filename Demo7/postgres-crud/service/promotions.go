@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"postgres-crud/model"
+	"sort"
+	"time"
+)
+
+// PromotionLine is an order line as seen by the promotion engine. Net is the
+// line total after its line discount, in minor currency units.
+type PromotionLine struct {
+	ProductID uint
+	Quantity  int
+	Net       int64
+}
+
+// PromotionContext holds what promotion eligibility depends on besides the
+// order lines. Usage counts how often each promotion was used on the
+// customer's other orders.
+type PromotionContext struct {
+	Currency   string
+	CustomerID *uint
+	Usage      map[uint]int
+	Now        time.Time
+}
+
+// PromotionResult is the outcome of evaluating one promotion against an order.
+// Promotions that do not apply have a zero Discount and a Reason.
+type PromotionResult struct {
+	Promotion model.Promotion
+	Applied   bool
+	Reason    string
+	Discount  int64
+}
+
+// EvaluatePromotions works out which promotions apply to an order and the
+// discount each gives, in minor units. Stackable promotions are combined in
+// order of priority, each applying to what the previous ones left. A promotion
+// that is not stackable applies alone, and only when it gives a larger
+// discount than the stackable promotions together. Results are returned in
+// the order of promotions.
+func EvaluatePromotions(promotions []model.Promotion, lines []PromotionLine, ctx PromotionContext) []PromotionResult {
+	var subtotal int64
+	for _, line := range lines {
+		subtotal += line.Net
+	}
+
+	results := make([]PromotionResult, len(promotions))
+	var eligible []int
+	for i, promotion := range promotions {
+		results[i].Promotion = promotion
+		if reason := promotionIneligibility(promotion, subtotal, ctx); reason != "" {
+			results[i].Reason = reason
+			continue
+		}
+		if promotionDiscount(promotion, lines, subtotal) == 0 {
+			results[i].Reason = "promotion does not apply to the items in the order"
+			continue
+		}
+		eligible = append(eligible, i)
+	}
+
+	// Higher priority first; older promotions win ties
+	sort.SliceStable(eligible, func(a, b int) bool {
+		pa, pb := promotions[eligible[a]], promotions[eligible[b]]
+		if pa.Priority != pb.Priority {
+			return pa.Priority > pb.Priority
+		}
+		return pa.ID < pb.ID
+	})
+
+	var stacked []int
+	var stackedTotal int64
+	remaining := subtotal
+	best, bestDiscount := -1, int64(0)
+	for _, i := range eligible {
+		promotion := promotions[i]
+		if promotion.Stackable {
+			discount := promotionDiscount(promotion, lines, remaining)
+			results[i].Discount = discount
+			stacked = append(stacked, i)
+			stackedTotal += discount
+			remaining -= discount
+			continue
+		}
+		if discount := promotionDiscount(promotion, lines, subtotal); discount > bestDiscount {
+			best, bestDiscount = i, discount
+		}
+	}
+
+	if best >= 0 && bestDiscount > stackedTotal {
+		for _, i := range eligible {
+			if i == best {
+				results[i].Applied = true
+				results[i].Discount = bestDiscount
+				continue
+			}
+			results[i].Discount = 0
+			results[i].Reason = fmt.Sprintf("cannot be combined with promotion %q", promotions[best].Name)
+		}
+		return results
+	}
+
+	for _, i := range eligible {
+		if promotions[i].Stackable {
+			results[i].Applied = true
+			continue
+		}
+		results[i].Reason = "cannot be combined with other promotions"
+	}
+	return results
+}
+
+// promotionIneligibility returns why a promotion cannot be used on an order,
+// or an empty string if it can
+func promotionIneligibility(promotion model.Promotion, subtotal int64, ctx PromotionContext) string {
+	if !promotion.Active {
+		return "promotion is not active"
+	}
+	if promotion.StartsAt != nil && ctx.Now.Before(*promotion.StartsAt) {
+		return "promotion has not started yet"
+	}
+	if promotion.EndsAt != nil && !ctx.Now.Before(*promotion.EndsAt) {
+		return "promotion has expired"
+	}
+
+	usesAmounts := promotion.Type == model.PromotionTypeFixedAmount || !promotion.MinOrderValue.IsZero()
+	if usesAmounts && promotion.Currency != ctx.Currency {
+		return fmt.Sprintf("promotion only applies to orders in %s", promotion.Currency)
+	}
+	if subtotal < promotion.MinOrderValue.Amount {
+		return fmt.Sprintf("minimum order value of %s %s not reached", promotion.MinOrderValue, promotion.Currency)
+	}
+
+	if promotion.UsageLimitPerCustomer > 0 {
+		if ctx.CustomerID == nil {
+			return "promotion is limited per customer and the order has no customer"
+		}
+		if ctx.Usage[promotion.ID] >= promotion.UsageLimitPerCustomer {
+			return fmt.Sprintf("usage limit of %d per customer reached", promotion.UsageLimitPerCustomer)
+		}
+	}
+
+	return ""
+}
+
+// promotionDiscount returns the discount a promotion gives on the order lines,
+// capped at available
+func promotionDiscount(promotion model.Promotion, lines []PromotionLine, available int64) int64 {
+	var discount int64
+	switch promotion.Type {
+	case model.PromotionTypePercentage:
+		discount = applyBasisPoints(available, percentToBasisPoints(promotion.PercentOff))
+	case model.PromotionTypeFixedAmount:
+		discount = promotion.AmountOff.Amount
+	case model.PromotionTypeBuyXGetY:
+		discount = freeUnitsValue(promotion, lines)
+	}
+
+	if discount > available {
+		discount = available
+	}
+	if discount < 0 {
+		discount = 0
+	}
+	return discount
+}
+
+// freeUnitsValue returns the value of the units a buy X get Y promotion makes
+// free. Of every BuyQuantity + GetQuantity units of the product, GetQuantity
+// are free, starting with the cheapest.
+func freeUnitsValue(promotion model.Promotion, lines []PromotionLine) int64 {
+	if promotion.ProductID == nil || promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+		return 0
+	}
+
+	var matching []PromotionLine
+	units := 0
+	for _, line := range lines {
+		if line.ProductID == *promotion.ProductID && line.Quantity > 0 {
+			matching = append(matching, line)
+			units += line.Quantity
+		}
+	}
+
+	free := units / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+	sort.SliceStable(matching, func(a, b int) bool {
+		return matching[a].Net*int64(matching[b].Quantity) < matching[b].Net*int64(matching[a].Quantity)
+	})
+
+	var value int64
+	for _, line := range matching {
+		if free == 0 {
+			break
+		}
+		n := line.Quantity
+		if n > free {
+			n = free
+		}
+		value += line.Net * int64(n) / int64(line.Quantity)
+		free -= n
+	}
+	return value
+}
+
+// promotionLines converts order items into promotion engine lines
+func promotionLines(items []model.OrderProduct) []PromotionLine {
+	lines := make([]PromotionLine, len(items))
+	for i, item := range items {
+		gross := item.Price.Amount * int64(item.Quantity)
+		lines[i] = PromotionLine{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Net:       gross - applyBasisPoints(gross, percentToBasisPoints(item.DiscountPercent)),
+		}
+	}
+	return lines
+}
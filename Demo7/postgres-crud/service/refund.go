@@ -0,0 +1,53 @@
+package service
+
+import (
+	"postgres-crud/model"
+	"postgres-crud/money"
+)
+
+// RefundRequest describes a cancelled order to a RefundHook. FromStatus is the
+// status the order was cancelled from; Amount is the order's grand total when
+// it was paid and zero otherwise.
+type RefundRequest struct {
+	OrderID    uint
+	CustomerID *uint
+	FromStatus model.OrderStatus
+	Amount     money.Money
+	Reason     model.CancelReason
+	Note       string
+}
+
+// ReturnRefundRequest describes a return to be refunded to a RefundHook.
+// Amount is the returned lines' share of what the order charged for them,
+// including tax. ReturnID identifies the refund: hooks should use it as an
+// idempotency key, so that a return is never paid out twice.
+type ReturnRefundRequest struct {
+	ReturnID   uint
+	OrderID    uint
+	CustomerID *uint
+	Amount     money.Money
+	Reason     string
+}
+
+// RefundHook lets payment integrations react to cancelled orders and refunded
+// returns. Both are called once the cancellation or the refunded return has
+// been committed, so a payment is never made for a change that was rolled
+// back; an error is logged and does not undo the change.
+type RefundHook interface {
+	RefundOrder(req RefundRequest) error
+	RefundReturn(req ReturnRefundRequest) error
+}
+
+// NoopRefundHook is the RefundHook used when no payment integration is
+// configured. It does nothing.
+type NoopRefundHook struct{}
+
+// RefundOrder implements RefundHook
+func (NoopRefundHook) RefundOrder(req RefundRequest) error {
+	return nil
+}
+
+// RefundReturn implements RefundHook
+func (NoopRefundHook) RefundReturn(req ReturnRefundRequest) error {
+	return nil
+}
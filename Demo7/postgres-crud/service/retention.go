@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"postgres-crud/repository"
+	"time"
+)
+
+// RetentionPolicy configures the retention worker. Records soft-deleted longer
+// than Period ago are purged every Interval, BatchSize at a time. In DryRun
+// mode nothing is removed and the worker only reports what it would purge.
+type RetentionPolicy struct {
+	Period    time.Duration
+	Interval  time.Duration
+	BatchSize int
+	DryRun    bool
+}
+
+// RetentionSummary reports what a retention run purged, or would have purged
+// in dry-run mode. KeptProducts are expired products that are kept because
+// orders that remain still have lines for them.
+type RetentionSummary struct {
+	Cutoff       time.Time
+	Orders       int64
+	OrderLines   int64
+	Products     int64
+	KeptProducts int64
+	DryRun       bool
+	Duration     time.Duration
+}
+
+// String formats the summary as a single log line
+func (s RetentionSummary) String() string {
+	verb := "purged"
+	if s.DryRun {
+		verb = "dry run, would purge"
+	}
+	return fmt.Sprintf("Retention: %s %d orders (%d order lines) and %d products deleted before %s; kept %d products still on orders; took %s",
+		verb, s.Orders, s.OrderLines, s.Products, s.Cutoff.Format(time.RFC3339), s.KeptProducts, s.Duration.Round(time.Millisecond))
+}
+
+// RetentionWorker permanently removes orders and products that have been
+// soft-deleted for longer than the retention period. Orders go first, with
+// their line items and other dependent rows, so that products only they
+// referred to can go in the same run.
+type RetentionWorker struct {
+	orderRepo   repository.OrderRepository
+	productRepo repository.ProductRepository
+	uow         repository.UnitOfWork
+	policy      RetentionPolicy
+}
+
+// NewRetentionWorker creates a new instance of RetentionWorker
+func NewRetentionWorker(orderRepo repository.OrderRepository, productRepo repository.ProductRepository, uow repository.UnitOfWork, policy RetentionPolicy) *RetentionWorker {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = 100
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = time.Hour
+	}
+	return &RetentionWorker{
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		uow:         uow,
+		policy:      policy,
+	}
+}
+
+// Start runs the worker in the background, once straight away and then every
+// interval, until ctx is cancelled. Each run logs a summary line.
+func (w *RetentionWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			summary, err := w.RunOnce(ctx)
+			if err != nil {
+				log.Printf("Retention: run failed after purging %d orders and %d products: %v", summary.Orders, summary.Products, err)
+			} else {
+				log.Println(summary)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce purges the records deleted before the retention cutoff. Every batch
+// is purged in its own transaction so that no table is locked for long. On
+// error the summary covers the batches purged before it.
+func (w *RetentionWorker) RunOnce(ctx context.Context) (RetentionSummary, error) {
+	started := time.Now()
+	summary := RetentionSummary{
+		Cutoff: started.Add(-w.policy.Period),
+		DryRun: w.policy.DryRun,
+	}
+
+	if w.policy.DryRun {
+		var err error
+		summary.Orders, summary.OrderLines, err = w.orderRepo.CountDeletedBefore(summary.Cutoff)
+		if err != nil {
+			return summary, fmt.Errorf("failed to count expired orders: %w", err)
+		}
+		summary.Products, summary.KeptProducts, err = w.productRepo.CountDeletedBefore(summary.Cutoff)
+		if err != nil {
+			return summary, fmt.Errorf("failed to count expired products: %w", err)
+		}
+		summary.Duration = time.Since(started)
+		return summary, nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		var purged int
+		var lines int64
+		err := w.uow.Do(func(repos repository.Repositories) error {
+			ids, err := repos.Orders.GetDeletedIDsBefore(summary.Cutoff, w.policy.BatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to find expired orders: %w", err)
+			}
+			lines, err = repos.Orders.PurgeBatch(ids)
+			if err != nil {
+				return fmt.Errorf("failed to purge orders: %w", err)
+			}
+			purged = len(ids)
+			return nil
+		})
+		if err != nil {
+			return summary, err
+		}
+		summary.Orders += int64(purged)
+		summary.OrderLines += lines
+		if purged < w.policy.BatchSize {
+			break
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		var purged int
+		err := w.uow.Do(func(repos repository.Repositories) error {
+			ids, err := repos.Products.GetPurgeableIDsBefore(summary.Cutoff, w.policy.BatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to find expired products: %w", err)
+			}
+			if err := repos.Products.PurgeBatch(ids); err != nil {
+				return fmt.Errorf("failed to purge products: %w", err)
+			}
+			purged = len(ids)
+			return nil
+		})
+		if err != nil {
+			return summary, err
+		}
+		summary.Products += int64(purged)
+		if purged < w.policy.BatchSize {
+			break
+		}
+	}
+
+	_, kept, err := w.productRepo.CountDeletedBefore(summary.Cutoff)
+	if err != nil {
+		return summary, fmt.Errorf("failed to count kept products: %w", err)
+	}
+	summary.KeptProducts = kept
+	summary.Duration = time.Since(started)
+	return summary, nil
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"postgres-crud/repository"
+	"time"
+)
+
+// ReturnService defines the interface for returns of delivered orders
+type ReturnService interface {
+	GetReturns(orderID uint) ([]model.Return, error)
+	GetReturn(orderID, returnID uint) (*model.Return, error)
+	CreateReturn(orderID uint, reason, note string, lines []OrderLine) (*model.Return, error)
+	ApproveReturn(orderID, returnID uint, expectedVersion *uint) (*model.Return, error)
+	ReceiveReturn(orderID, returnID uint, restock bool, expectedVersion *uint) (*model.Return, error)
+	RefundReturn(orderID, returnID uint, expectedVersion *uint) (*model.Return, error)
+}
+
+// returnTransitions lists the status each return status moves on to
+var returnTransitions = map[model.ReturnStatus]model.ReturnStatus{
+	model.ReturnStatusRequested: model.ReturnStatusApproved,
+	model.ReturnStatusApproved:  model.ReturnStatusReceived,
+	model.ReturnStatusReceived:  model.ReturnStatusRefunded,
+}
+
+// returnService implements ReturnService interface
+type returnService struct {
+	returnRepo repository.ReturnRepository
+	orderRepo  repository.OrderRepository
+	uow        repository.UnitOfWork
+	refunds    RefundHook
+}
+
+// NewReturnService creates a new instance of ReturnService. refunds is asked
+// to pay out every refunded return.
+func NewReturnService(returnRepo repository.ReturnRepository, orderRepo repository.OrderRepository, uow repository.UnitOfWork, refunds RefundHook) ReturnService {
+	return &returnService{
+		returnRepo: returnRepo,
+		orderRepo:  orderRepo,
+		uow:        uow,
+		refunds:    refunds,
+	}
+}
+
+// GetReturns retrieves the returns of an order, oldest first
+func (s *returnService) GetReturns(orderID uint) ([]model.Return, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	if _, err := s.orderRepo.GetByID(orderID); err != nil {
+		return nil, notFoundOr(err, "order not found")
+	}
+
+	returns, err := s.returnRepo.GetByOrderID(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get returns: %w", err)
+	}
+
+	return returns, nil
+}
+
+// GetReturn retrieves a return of an order
+func (s *returnService) GetReturn(orderID, returnID uint) (*model.Return, error) {
+	if orderID == 0 || returnID == 0 {
+		return nil, fmt.Errorf("invalid order or return ID")
+	}
+
+	return getOrderReturn(s.returnRepo.GetByID, orderID, returnID)
+}
+
+// getOrderReturn loads a return with get, reporting returns of other orders as
+// not found
+func getOrderReturn(get func(id uint) (*model.Return, error), orderID, returnID uint) (*model.Return, error) {
+	ret, err := get(returnID)
+	if err != nil {
+		return nil, notFoundOr(err, "return not found")
+	}
+	if ret.OrderID != orderID {
+		return nil, &apierrors.APIError{
+			Code:    apierrors.ErrNotFound.Code,
+			Message: "return not found",
+			Details: fmt.Sprintf("return %d does not belong to order %d", returnID, orderID),
+		}
+	}
+	return ret, nil
+}
+
+// CreateReturn requests the return of quantities of a delivered order's lines.
+// A line cannot be returned more often than it was ordered, counting earlier
+// returns of the order. The order row is locked so that concurrent returns are
+// checked one at a time.
+func (s *returnService) CreateReturn(orderID uint, reason, note string, lines []OrderLine) (*model.Return, error) {
+	if orderID == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("reason cannot be empty")
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("a return needs at least one line")
+	}
+	if err := validateOrderLines(lines); err != nil {
+		return nil, err
+	}
+
+	var ret *model.Return
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		order, err := repos.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return notFoundOr(err, "order not found")
+		}
+		if order.Status != model.OrderStatusDelivered {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrConflict.Code,
+				Message: fmt.Sprintf("only delivered orders can be returned, order is %s", order.Status),
+			}
+		}
+
+		items, err := repos.Orders.GetItems(orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order items: %w", err)
+		}
+		taxLines, err := repos.Taxes.GetOrderTaxLines(orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order tax lines: %w", err)
+		}
+		previous, err := repos.Returns.GetLinesByOrderID(orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get returned items: %w", err)
+		}
+		returned := make(map[[2]uint]int, len(previous))
+		for _, line := range previous {
+			returned[[2]uint{line.ProductID, line.VariantID}] += line.Quantity
+		}
+
+		ret = &model.Return{
+			OrderID:      orderID,
+			Status:       model.ReturnStatusRequested,
+			Reason:       reason,
+			Note:         note,
+			RefundAmount: money.New(0, order.Currency),
+		}
+		var failed []apierrors.LineError
+		for i, line := range lines {
+			item := findOrderItem(items, line)
+			if item == nil {
+				failed = append(failed, orderLineError(i, line, fmt.Errorf("order has no line for this product")))
+				continue
+			}
+			key := [2]uint{item.ProductID, item.VariantID}
+			if remaining := item.Quantity - returned[key]; line.Quantity > remaining {
+				failed = append(failed, orderLineError(i, line, fmt.Errorf("only %d of %d units can still be returned", remaining, item.Quantity)))
+				continue
+			}
+			taxLine := findOrderTaxLine(taxLines, item)
+			if taxLine == nil {
+				taxLine = capturedTaxLine(order, item)
+			}
+
+			amount := returnLineAmount(item, taxLine, returned[key], line.Quantity)
+			returned[key] += line.Quantity
+			ret.Lines = append(ret.Lines, model.ReturnLine{
+				OrderID:   orderID,
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				SKU:       item.SKU,
+				Quantity:  line.Quantity,
+				UnitPrice: item.Price,
+				Amount:    amount,
+			})
+			ret.RefundAmount, err = ret.RefundAmount.Add(amount)
+			if err != nil {
+				return fmt.Errorf("failed to compute refund: %w", err)
+			}
+		}
+		if len(failed) > 0 {
+			return &apierrors.LineErrors{
+				Message: fmt.Sprintf("%d of %d return lines failed", len(failed), len(lines)),
+				Lines:   failed,
+			}
+		}
+
+		if err := repos.Returns.Create(ret); err != nil {
+			return fmt.Errorf("failed to create return: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.returnRepo.GetByID(ret.ID)
+}
+
+// findOrderItem returns the line of an order that a requested line refers to.
+// A line given by variant alone matches the order line of that variant.
+func findOrderItem(items []model.OrderProduct, line OrderLine) *model.OrderProduct {
+	for i := range items {
+		if items[i].VariantID != line.VariantID {
+			continue
+		}
+		if line.ProductID == 0 || items[i].ProductID == line.ProductID {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// findOrderTaxLine returns the tax line persisted for an order line when the
+// order was placed
+func findOrderTaxLine(lines []model.OrderTaxLine, item *model.OrderProduct) *model.OrderTaxLine {
+	for i := range lines {
+		if lines[i].ProductID == item.ProductID && lines[i].VariantID == item.VariantID {
+			return &lines[i]
+		}
+	}
+	return nil
+}
+
+// capturedTaxLine reconstructs the tax line of an order line from the price
+// and discount captured on the line and the tax rate captured on the order.
+// It stands in for orders placed before tax lines were persisted.
+func capturedTaxLine(order *model.Order, item *model.OrderProduct) *model.OrderTaxLine {
+	gross := item.Price.Multiply(item.Quantity)
+	taxable := gross.Amount - applyBasisPoints(gross.Amount, percentToBasisPoints(item.DiscountPercent))
+	return &model.OrderTaxLine{
+		OrderID:       order.ID,
+		ProductID:     item.ProductID,
+		VariantID:     item.VariantID,
+		TaxableAmount: money.New(taxable, order.Currency),
+		Rate:          order.TaxRate,
+		Tax:           money.New(applyBasisPoints(taxable, percentToBasisPoints(order.TaxRate)), order.Currency),
+		Currency:      order.Currency,
+	}
+}
+
+// returnLineAmount computes the refund for returning quantity units of an
+// order line of which returned units were returned before. The line was
+// charged its taxable amount plus tax, as persisted when the order was placed,
+// so its share of the line, order and promotion discounts is refunded with it.
+// That charge is pro-rated by quantity cumulatively, so returning every unit,
+// in any number of returns, refunds exactly what the line was charged.
+func returnLineAmount(item *model.OrderProduct, taxLine *model.OrderTaxLine, returned, quantity int) money.Money {
+	charged := taxLine.TaxableAmount.Amount + taxLine.Tax.Amount
+	amount := proRate(charged, returned+quantity, item.Quantity) - proRate(charged, returned, item.Quantity)
+	return money.New(amount, taxLine.Currency)
+}
+
+// proRate returns part/whole of amount, rounded half away from zero
+func proRate(amount int64, part, whole int) int64 {
+	if whole <= 0 {
+		return 0
+	}
+	product := amount * int64(part)
+	divisor := int64(whole)
+	if product < 0 {
+		return -((-product + divisor/2) / divisor)
+	}
+	return (product + divisor/2) / divisor
+}
+
+// ApproveReturn accepts a requested return
+func (s *returnService) ApproveReturn(orderID, returnID uint, expectedVersion *uint) (*model.Return, error) {
+	return s.advanceReturn(orderID, returnID, model.ReturnStatusApproved, expectedVersion, func(repos repository.Repositories, ret *model.Return, now time.Time) ([]string, error) {
+		ret.ApprovedAt = &now
+		return []string{"approved_at"}, nil
+	})
+}
+
+// ReceiveReturn records that the items of an approved return have arrived.
+// With restock, the returned units are put back into stock.
+func (s *returnService) ReceiveReturn(orderID, returnID uint, restock bool, expectedVersion *uint) (*model.Return, error) {
+	return s.advanceReturn(orderID, returnID, model.ReturnStatusReceived, expectedVersion, func(repos repository.Repositories, ret *model.Return, now time.Time) ([]string, error) {
+		if restock {
+			for _, line := range ret.Lines {
+				movement := orderStockMovement(ret.OrderID, line.ProductID, line.VariantID, line.Quantity, model.StockReasonOrderReturned)
+				movement.Note = fmt.Sprintf("return %d", ret.ID)
+				if err := applyStockMovement(repos, movement); err != nil {
+					return nil, fmt.Errorf("failed to restock returned items: %w", err)
+				}
+			}
+		}
+		ret.Restocked = restock
+		ret.ReceivedAt = &now
+		return []string{"restocked", "received_at"}, nil
+	})
+}
+
+// RefundReturn marks a received return refunded and, once that has been
+// committed, pays out its refund amount through the refund hook. The return is
+// locked while it is marked, so it is refunded only once.
+func (s *returnService) RefundReturn(orderID, returnID uint, expectedVersion *uint) (*model.Return, error) {
+	var customerID *uint
+	ret, err := s.advanceReturn(orderID, returnID, model.ReturnStatusRefunded, expectedVersion, func(repos repository.Repositories, ret *model.Return, now time.Time) ([]string, error) {
+		order, err := repos.Orders.GetByID(ret.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order: %w", err)
+		}
+		customerID = order.CustomerID
+		ret.RefundedAt = &now
+		return []string{"refunded_at"}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refund := ReturnRefundRequest{
+		ReturnID:   ret.ID,
+		OrderID:    ret.OrderID,
+		CustomerID: customerID,
+		Amount:     ret.RefundAmount,
+		Reason:     ret.Reason,
+	}
+	if err := s.refunds.RefundReturn(refund); err != nil {
+		// The return stays refunded; the payment has to be settled by hand
+		log.Printf("Return %d: refund of %s %s failed: %v", ret.ID, ret.RefundAmount, ret.RefundAmount.Currency, err)
+	}
+
+	return ret, nil
+}
+
+// advanceReturn moves a return of an order on to status to in a unit of work.
+// apply makes the changes that come with the new status and names the columns
+// it set. Moves out of order are rejected with a conflict.
+func (s *returnService) advanceReturn(orderID, returnID uint, to model.ReturnStatus, expectedVersion *uint, apply func(repos repository.Repositories, ret *model.Return, now time.Time) ([]string, error)) (*model.Return, error) {
+	if orderID == 0 || returnID == 0 {
+		return nil, fmt.Errorf("invalid order or return ID")
+	}
+
+	var ret *model.Return
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		ret, err = getOrderReturn(repos.Returns.GetByIDForUpdate, orderID, returnID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(expectedVersion, ret.Version); err != nil {
+			return err
+		}
+
+		from := ret.Status
+		if returnTransitions[from] != to {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrConflict.Code,
+				Message: fmt.Sprintf("cannot move return from %s to %s", from, to),
+			}
+		}
+
+		columns, err := apply(repos, ret, time.Now())
+		if err != nil {
+			return err
+		}
+		ret.Status = to
+		if err := repos.Returns.UpdateStatus(ret, from, columns...); err != nil {
+			return versionConflictOr(err, expectedVersion, "failed to update return")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"testing"
+)
+
+func TestProRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      int64
+		part, whole int
+		want        int64
+	}{
+		{"none", 1000, 0, 3, 0},
+		{"all", 1000, 3, 3, 1000},
+		{"below half rounds down", 1000, 1, 3, 333},
+		{"above half rounds up", 1000, 2, 3, 667},
+		{"half rounds up", 5, 1, 2, 3},
+		{"negative half rounds away from zero", -5, 1, 2, -3},
+		{"no quantity", 1000, 1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proRate(tt.amount, tt.part, tt.whole); got != tt.want {
+				t.Errorf("proRate(%d, %d, %d) = %d, want %d", tt.amount, tt.part, tt.whole, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReturnLineAmount(t *testing.T) {
+	// A line of 3 units at 10.00 with 10% off, after its share of order
+	// discounts and promotions, taxed at 10%
+	item := &model.OrderProduct{
+		ProductID:       1,
+		Quantity:        3,
+		Price:           money.New(1000, "USD"),
+		DiscountPercent: 10,
+	}
+	taxLine := &model.OrderTaxLine{
+		ProductID:     1,
+		TaxableAmount: money.New(2632, "USD"),
+		Tax:           money.New(263, "USD"),
+		Currency:      "USD",
+	}
+
+	tests := []struct {
+		name               string
+		returned, quantity int
+		want               int64
+	}{
+		{"one unit", 0, 1, 965},
+		{"two units", 0, 2, 1930},
+		{"whole line", 0, 3, 2895},
+		{"second unit", 1, 1, 965},
+		{"last unit", 2, 1, 965},
+		{"rest of the line", 1, 2, 1930},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := returnLineAmount(item, taxLine, tt.returned, tt.quantity)
+			if got != money.New(tt.want, "USD") {
+				t.Errorf("returnLineAmount(returned %d, quantity %d) = %+v, want %d USD", tt.returned, tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReturnLineAmountRefundsWhatWasCharged(t *testing.T) {
+	tests := []struct {
+		name    string
+		taxable int64
+		tax     int64
+		splits  []int
+	}{
+		{"unit by unit", 1000, 0, []int{1, 1, 1}},
+		{"uneven returns", 3342, 267, []int{2, 5}},
+		{"many small returns", 9999, 825, []int{1, 1, 2, 1, 3, 1}},
+		{"single return", 390, 0, []int{4}},
+		{"discounted to nothing", 0, 0, []int{1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var quantity int
+			for _, n := range tt.splits {
+				quantity += n
+			}
+			item := &model.OrderProduct{ProductID: 1, Quantity: quantity}
+			taxLine := &model.OrderTaxLine{
+				ProductID:     1,
+				TaxableAmount: money.New(tt.taxable, "EUR"),
+				Tax:           money.New(tt.tax, "EUR"),
+				Currency:      "EUR",
+			}
+
+			var returned int
+			var refunded int64
+			for _, n := range tt.splits {
+				amount := returnLineAmount(item, taxLine, returned, n)
+				if amount.Currency != "EUR" {
+					t.Fatalf("refund currency = %q, want EUR", amount.Currency)
+				}
+				if amount.IsNegative() {
+					t.Fatalf("returning %d after %d refunds %v", n, returned, amount)
+				}
+				returned += n
+				refunded += amount.Amount
+			}
+			if want := tt.taxable + tt.tax; refunded != want {
+				t.Errorf("returns %v refund %d in total, want %d", tt.splits, refunded, want)
+			}
+		})
+	}
+}
+
+func TestFindOrderTaxLine(t *testing.T) {
+	lines := []model.OrderTaxLine{
+		{ID: 1, ProductID: 1, VariantID: 0},
+		{ID: 2, ProductID: 2, VariantID: 7},
+		{ID: 3, ProductID: 2, VariantID: 8},
+	}
+
+	tests := []struct {
+		name      string
+		productID uint
+		variantID uint
+		want      uint
+	}{
+		{"product without variants", 1, 0, 1},
+		{"variant", 2, 8, 3},
+		{"unknown variant", 2, 9, 0},
+		{"unknown product", 3, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findOrderTaxLine(lines, &model.OrderProduct{ProductID: tt.productID, VariantID: tt.variantID})
+			switch {
+			case tt.want == 0 && got != nil:
+				t.Errorf("findOrderTaxLine() = line %d, want none", got.ID)
+			case tt.want != 0 && (got == nil || got.ID != tt.want):
+				t.Errorf("findOrderTaxLine() = %+v, want line %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapturedTaxLine(t *testing.T) {
+	// An order placed before tax lines were recorded: 3 units at 19.99 with
+	// 10% off the line, taxed at the order's 8.25%
+	order := &model.Order{ID: 9, Currency: "USD", TaxRate: 8.25}
+	item := &model.OrderProduct{
+		ProductID:       4,
+		VariantID:       2,
+		Quantity:        3,
+		Price:           money.New(1999, "USD"),
+		DiscountPercent: 10,
+	}
+
+	taxLine := capturedTaxLine(order, item)
+	// 5997 gross, 599.7 -> 600 off, 8.25% of 5397 = 445.2525 -> 445 tax
+	if taxLine.ProductID != 4 || taxLine.VariantID != 2 {
+		t.Errorf("tax line is for product %d variant %d, want 4 and 2", taxLine.ProductID, taxLine.VariantID)
+	}
+	if taxLine.TaxableAmount != money.New(5397, "USD") {
+		t.Errorf("TaxableAmount = %+v, want 53.97 USD", taxLine.TaxableAmount)
+	}
+	if taxLine.Tax != money.New(445, "USD") {
+		t.Errorf("Tax = %+v, want 4.45 USD", taxLine.Tax)
+	}
+
+	tests := []struct {
+		name               string
+		returned, quantity int
+		want               int64
+	}{
+		{"one unit", 0, 1, 1947},
+		{"two more units", 1, 2, 3895},
+		{"whole line", 0, 3, 5842},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := returnLineAmount(item, taxLine, tt.returned, tt.quantity)
+			if got != money.New(tt.want, "USD") {
+				t.Errorf("returnLineAmount(returned %d, quantity %d) = %+v, want %d USD", tt.returned, tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapturedTaxLineUntaxedOrder(t *testing.T) {
+	order := &model.Order{ID: 9, Currency: "EUR"}
+	item := &model.OrderProduct{ProductID: 1, Quantity: 2, Price: money.New(1250, "EUR")}
+
+	got := returnLineAmount(item, capturedTaxLine(order, item), 0, 1)
+	if got != money.New(1250, "EUR") {
+		t.Errorf("returnLineAmount() = %+v, want 12.50 EUR", got)
+	}
+}
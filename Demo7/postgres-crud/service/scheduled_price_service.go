@@ -0,0 +1,243 @@
+package service
+
+import (
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+	"time"
+)
+
+// ScheduledPriceService defines the interface for scheduling product price
+// changes
+type ScheduledPriceService interface {
+	GetSchedules(productID uint) (*model.Product, []model.ScheduledPrice, error)
+	GetScheduleByID(id uint) (*model.ScheduledPrice, error)
+	CreateSchedule(productID uint, schedule model.ScheduledPrice) (*model.ScheduledPrice, error)
+	UpdateSchedule(id uint, schedule model.ScheduledPrice) (*model.ScheduledPrice, error)
+	DeleteSchedule(id uint) error
+}
+
+// scheduledPriceService implements ScheduledPriceService interface
+type scheduledPriceService struct {
+	scheduleRepo repository.ScheduledPriceRepository
+	productRepo  repository.ProductRepository
+	uow          repository.UnitOfWork
+}
+
+// NewScheduledPriceService creates a new instance of ScheduledPriceService
+func NewScheduledPriceService(scheduleRepo repository.ScheduledPriceRepository, productRepo repository.ProductRepository, uow repository.UnitOfWork) ScheduledPriceService {
+	return &scheduledPriceService{
+		scheduleRepo: scheduleRepo,
+		productRepo:  productRepo,
+		uow:          uow,
+	}
+}
+
+// applyScheduledPrices replaces the price of each product with the scheduled
+// price in effect at a given time, if there is one
+func applyScheduledPrices(schedules repository.ScheduledPriceRepository, products []model.Product, at time.Time) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+
+	active, err := schedules.GetActive(ids, at)
+	if err != nil {
+		return fmt.Errorf("failed to get scheduled prices: %w", err)
+	}
+	byProduct := make(map[uint]*model.ScheduledPrice, len(active))
+	for i := range active {
+		if _, ok := byProduct[active[i].ProductID]; !ok {
+			byProduct[active[i].ProductID] = &active[i]
+		}
+	}
+
+	for i := range products {
+		products[i].ApplyScheduledPrice(byProduct[products[i].ID])
+	}
+	return nil
+}
+
+// applyScheduledPrice replaces the price of a product with the scheduled price
+// in effect at a given time, if there is one
+func applyScheduledPrice(schedules repository.ScheduledPriceRepository, product *model.Product, at time.Time) error {
+	products := []model.Product{*product}
+	if err := applyScheduledPrices(schedules, products, at); err != nil {
+		return err
+	}
+	*product = products[0]
+	return nil
+}
+
+// validateSchedule checks the price and period of a scheduled price against
+// its product
+func validateSchedule(schedule *model.ScheduledPrice, product *model.Product) error {
+	if schedule.Price.Currency == "" {
+		schedule.Price.Currency = product.Currency
+	}
+	if schedule.Price.Currency != product.Currency {
+		return fmt.Errorf("scheduled price must be in the product's currency %s", product.Currency)
+	}
+	if schedule.Price.IsNegative() {
+		return fmt.Errorf("scheduled price cannot be negative")
+	}
+	if schedule.StartsAt.IsZero() {
+		return fmt.Errorf("start time is required")
+	}
+	if schedule.EndsAt != nil {
+		if !schedule.EndsAt.After(schedule.StartsAt) {
+			return fmt.Errorf("scheduled price must end after it starts")
+		}
+		if !schedule.EndsAt.After(time.Now()) {
+			return fmt.Errorf("scheduled price has already ended")
+		}
+	}
+	return nil
+}
+
+// ensureNoOverlap returns a conflict error naming the first other schedule of
+// the product whose period overlaps that of schedule
+func ensureNoOverlap(schedules repository.ScheduledPriceRepository, schedule *model.ScheduledPrice) error {
+	overlapping, err := schedules.FindOverlapping(schedule)
+	if err != nil {
+		return fmt.Errorf("failed to check scheduled prices: %w", err)
+	}
+	if len(overlapping) == 0 {
+		return nil
+	}
+
+	other := overlapping[0]
+	ends := "indefinitely"
+	if other.EndsAt != nil {
+		ends = "until " + other.EndsAt.Format(time.RFC3339)
+	}
+	return &apierrors.APIError{
+		Code: apierrors.ErrConflict.Code,
+		Message: fmt.Sprintf("overlaps scheduled price %d, which runs from %s %s",
+			other.ID, other.StartsAt.Format(time.RFC3339), ends),
+	}
+}
+
+// GetSchedules retrieves a product together with its scheduled prices,
+// earliest first
+func (s *scheduledPriceService) GetSchedules(productID uint) (*model.Product, []model.ScheduledPrice, error) {
+	if productID == 0 {
+		return nil, nil, fmt.Errorf("invalid product ID")
+	}
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "product not found")
+	}
+
+	schedules, err := s.scheduleRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get scheduled prices: %w", err)
+	}
+
+	return product, schedules, nil
+}
+
+// GetScheduleByID retrieves a scheduled price by ID
+func (s *scheduledPriceService) GetScheduleByID(id uint) (*model.ScheduledPrice, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid scheduled price ID")
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "scheduled price not found")
+	}
+
+	return schedule, nil
+}
+
+// CreateSchedule schedules a price for a product. The product row is locked so
+// that concurrent schedules for it are checked for overlaps one at a time.
+func (s *scheduledPriceService) CreateSchedule(productID uint, schedule model.ScheduledPrice) (*model.ScheduledPrice, error) {
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+	schedule.ID = 0
+	schedule.ProductID = productID
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		product, err := repos.Products.GetByIDForUpdate(productID)
+		if err != nil {
+			return notFoundOr(err, "product not found")
+		}
+		if err := validateSchedule(&schedule, product); err != nil {
+			return err
+		}
+		if err := ensureNoOverlap(repos.Schedules, &schedule); err != nil {
+			return err
+		}
+		if err := repos.Schedules.Create(&schedule); err != nil {
+			return fmt.Errorf("failed to create scheduled price: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// UpdateSchedule replaces the price, period and note of a scheduled price.
+// Order lines already priced with it keep their price.
+func (s *scheduledPriceService) UpdateSchedule(id uint, schedule model.ScheduledPrice) (*model.ScheduledPrice, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid scheduled price ID")
+	}
+
+	var existing *model.ScheduledPrice
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		existing, err = repos.Schedules.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "scheduled price not found")
+		}
+		product, err := repos.Products.GetByIDForUpdate(existing.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+
+		existing.Price = schedule.Price
+		existing.StartsAt = schedule.StartsAt
+		existing.EndsAt = schedule.EndsAt
+		existing.Note = schedule.Note
+		if err := validateSchedule(existing, product); err != nil {
+			return err
+		}
+		if err := ensureNoOverlap(repos.Schedules, existing); err != nil {
+			return err
+		}
+		if err := repos.Schedules.Update(existing); err != nil {
+			return fmt.Errorf("failed to update scheduled price: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// DeleteSchedule deletes a scheduled price
+func (s *scheduledPriceService) DeleteSchedule(id uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid scheduled price ID")
+	}
+
+	if err := s.scheduleRepo.Delete(id); err != nil {
+		return notFoundOr(err, "scheduled price not found")
+	}
+
+	return nil
+}
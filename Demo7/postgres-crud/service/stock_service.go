@@ -0,0 +1,279 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+)
+
+// StockHistory holds the stock ledger of a product, or of one of its variants
+// when VariantID is set, alongside its stock
+type StockHistory struct {
+	ProductID     uint
+	VariantID     *uint
+	Stock         int
+	LedgerBalance int
+	Movements     []model.StockMovement
+}
+
+// InSync reports whether the stock matches the sum of its ledger
+func (h *StockHistory) InSync() bool {
+	return h.Stock == h.LedgerBalance
+}
+
+// StockService defines the interface for stock ledger business logic
+type StockService interface {
+	AdjustStock(productID uint, delta int, reason model.StockMovementReason, actor, note string) (*model.StockMovement, error)
+	GetStockHistory(productID uint) (*StockHistory, error)
+	AdjustVariantStock(variantID uint, delta int, reason model.StockMovementReason, actor, note string) (*model.StockMovement, error)
+	GetVariantStockHistory(variantID uint) (*StockHistory, error)
+}
+
+// stockService implements StockService interface
+type stockService struct {
+	stockRepo   repository.StockMovementRepository
+	productRepo repository.ProductRepository
+	variantRepo repository.VariantRepository
+	uow         repository.UnitOfWork
+}
+
+// NewStockService creates a new instance of StockService
+func NewStockService(stockRepo repository.StockMovementRepository, productRepo repository.ProductRepository, variantRepo repository.VariantRepository, uow repository.UnitOfWork) StockService {
+	return &stockService{
+		stockRepo:   stockRepo,
+		productRepo: productRepo,
+		variantRepo: variantRepo,
+		uow:         uow,
+	}
+}
+
+// IsManualStockReason reports whether reason may be used for a manual adjustment
+func IsManualStockReason(reason model.StockMovementReason) bool {
+	switch reason {
+	case model.StockReasonRestock, model.StockReasonDamaged, model.StockReasonLost,
+		model.StockReasonFound, model.StockReasonCorrection:
+		return true
+	}
+	return false
+}
+
+// validateManualAdjustment checks the delta, reason and actor of a manual
+// stock adjustment
+func validateManualAdjustment(delta int, reason model.StockMovementReason, actor string) error {
+	if delta == 0 {
+		return fmt.Errorf("delta cannot be zero")
+	}
+	if !IsManualStockReason(reason) {
+		return fmt.Errorf("invalid reason code %q", reason)
+	}
+	if actor == "" {
+		return fmt.Errorf("actor cannot be empty")
+	}
+	return nil
+}
+
+// AdjustStock records a manual stock adjustment for a product. Products with
+// variants hold their stock on the variants, which are adjusted with
+// AdjustVariantStock instead.
+func (s *stockService) AdjustStock(productID uint, delta int, reason model.StockMovementReason, actor, note string) (*model.StockMovement, error) {
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+	if err := validateManualAdjustment(delta, reason, actor); err != nil {
+		return nil, err
+	}
+
+	movement := &model.StockMovement{
+		ProductID:     productID,
+		Delta:         delta,
+		Reason:        reason,
+		ReferenceType: model.StockReferenceAdjustment,
+		Actor:         actor,
+		Note:          note,
+	}
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		variants, err := repos.Variants.CountByProductID(productID)
+		if err != nil {
+			return fmt.Errorf("failed to check variants: %w", err)
+		}
+		if variants > 0 {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrConflict.Code,
+				Message: "product has variants",
+				Details: fmt.Sprintf("product %d keeps its stock on its variants: adjust them with POST /variants/:id/stock-adjustments", productID),
+			}
+		}
+
+		if err := applyStockMovement(repos, movement); err != nil {
+			return notFoundOr(err, "failed to adjust stock")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// AdjustVariantStock records a manual stock adjustment for a product variant
+func (s *stockService) AdjustVariantStock(variantID uint, delta int, reason model.StockMovementReason, actor, note string) (*model.StockMovement, error) {
+	if variantID == 0 {
+		return nil, fmt.Errorf("invalid variant ID")
+	}
+	if err := validateManualAdjustment(delta, reason, actor); err != nil {
+		return nil, err
+	}
+
+	var movement *model.StockMovement
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		variant, err := repos.Variants.GetByID(variantID)
+		if err != nil {
+			return notFoundOr(err, "variant not found")
+		}
+
+		movement = &model.StockMovement{
+			ProductID:     variant.ProductID,
+			VariantID:     &variant.ID,
+			Delta:         delta,
+			Reason:        reason,
+			ReferenceType: model.StockReferenceAdjustment,
+			Actor:         actor,
+			Note:          note,
+		}
+		if err := applyStockMovement(repos, movement); err != nil {
+			return fmt.Errorf("failed to adjust stock: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// GetStockHistory retrieves the stock ledger of a product and checks it
+// against the product's stock
+func (s *stockService) GetStockHistory(productID uint) (*StockHistory, error) {
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, notFoundOr(err, "product not found")
+	}
+
+	movements, err := s.stockRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock history: %w", err)
+	}
+
+	balance, err := s.stockRepo.GetLedgerBalance(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger balance: %w", err)
+	}
+
+	return &StockHistory{
+		ProductID:     product.ID,
+		Stock:         product.Stock,
+		LedgerBalance: balance,
+		Movements:     movements,
+	}, nil
+}
+
+// GetVariantStockHistory retrieves the stock ledger of a product variant and
+// checks it against the variant's stock
+func (s *stockService) GetVariantStockHistory(variantID uint) (*StockHistory, error) {
+	if variantID == 0 {
+		return nil, fmt.Errorf("invalid variant ID")
+	}
+
+	variant, err := s.variantRepo.GetByID(variantID)
+	if err != nil {
+		return nil, notFoundOr(err, "variant not found")
+	}
+
+	movements, err := s.stockRepo.GetByVariantID(variantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock history: %w", err)
+	}
+
+	balance, err := s.stockRepo.GetVariantLedgerBalance(variantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger balance: %w", err)
+	}
+
+	return &StockHistory{
+		ProductID:     variant.ProductID,
+		VariantID:     &variant.ID,
+		Stock:         variant.Stock,
+		LedgerBalance: balance,
+		Movements:     movements,
+	}, nil
+}
+
+// applyStockMovement changes the stock of a product, or of a variant when the
+// movement has one, by the movement delta and appends the movement to the
+// ledger. It is meant to run inside a unit of work.
+func applyStockMovement(repos repository.Repositories, movement *model.StockMovement) error {
+	if movement.Delta == 0 {
+		return nil
+	}
+
+	if movement.VariantID != nil {
+		if err := repos.Variants.AdjustStock(*movement.VariantID, movement.Delta); err != nil {
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return fmt.Errorf("%w for variant %d: cannot apply change of %d", err, *movement.VariantID, movement.Delta)
+			}
+			return err
+		}
+		return repos.Stock.Record(movement)
+	}
+
+	if err := repos.Products.AdjustStock(movement.ProductID, movement.Delta); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return fmt.Errorf("%w for product %d: cannot apply change of %d", err, movement.ProductID, movement.Delta)
+		}
+		return err
+	}
+	return repos.Stock.Record(movement)
+}
+
+// orderStockMovement builds a system stock movement referencing an order. A
+// variantID of 0 moves the product's own stock.
+func orderStockMovement(orderID uint, productID uint, variantID uint, delta int, reason model.StockMovementReason) *model.StockMovement {
+	movement := &model.StockMovement{
+		ProductID:     productID,
+		Delta:         delta,
+		Reason:        reason,
+		ReferenceType: model.StockReferenceOrder,
+		ReferenceID:   &orderID,
+		Actor:         model.StockActorSystem,
+	}
+	if variantID != 0 {
+		movement.VariantID = &variantID
+	}
+	return movement
+}
+
+// restockOrderItems returns the quantity recorded on every line of an order to
+// stock. It is meant to run inside a unit of work.
+func restockOrderItems(repos repository.Repositories, orderID uint, reason model.StockMovementReason) error {
+	items, err := repos.Orders.GetItems(orderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	for _, item := range items {
+		if err := applyStockMovement(repos, orderStockMovement(orderID, item.ProductID, item.VariantID, item.Quantity, reason)); err != nil {
+			return fmt.Errorf("failed to restore product stock: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TagService defines the interface for tag business logic
+type TagService interface {
+	CreateTag(name string) (*model.Tag, error)
+	GetTagByID(id uint) (*model.Tag, error)
+	GetAllTags() ([]model.Tag, error)
+	UpdateTag(id uint, name string) (*model.Tag, error)
+	DeleteTag(id uint) error
+}
+
+// tagService implements TagService interface
+type tagService struct {
+	tagRepo repository.TagRepository
+	uow     repository.UnitOfWork
+}
+
+// NewTagService creates a new instance of TagService
+func NewTagService(tagRepo repository.TagRepository, uow repository.UnitOfWork) TagService {
+	return &tagService{
+		tagRepo: tagRepo,
+		uow:     uow,
+	}
+}
+
+// normalizeTag trims and lower-cases a tag name
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags normalises tag names and drops empty and duplicate ones. A nil
+// slice stays nil so callers can tell "no change" from "no tags".
+func normalizeTags(names []string) []string {
+	if names == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = normalizeTag(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// ensureTagNameFree returns a conflict error if another tag already has name
+func ensureTagNameFree(tags repository.TagRepository, name string, id uint) error {
+	existing, err := tags.GetByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to check tag name: %w", err)
+	}
+	if existing.ID != id {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: "tag already exists",
+			Details: fmt.Sprintf("tag %q has ID %d", name, existing.ID),
+		}
+	}
+	return nil
+}
+
+// CreateTag creates a new tag
+func (s *tagService) CreateTag(name string) (*model.Tag, error) {
+	name = normalizeTag(name)
+	if name == "" {
+		return nil, fmt.Errorf("tag name cannot be empty")
+	}
+	if err := ensureTagNameFree(s.tagRepo, name, 0); err != nil {
+		return nil, err
+	}
+
+	tag := &model.Tag{Name: name}
+	if err := s.tagRepo.Create(tag); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// GetTagByID retrieves a tag by its ID
+func (s *tagService) GetTagByID(id uint) (*model.Tag, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid tag ID")
+	}
+
+	tag, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "tag not found")
+	}
+
+	return tag, nil
+}
+
+// GetAllTags retrieves all tags
+func (s *tagService) GetAllTags() ([]model.Tag, error) {
+	tags, err := s.tagRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// UpdateTag renames a tag
+func (s *tagService) UpdateTag(id uint, name string) (*model.Tag, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid tag ID")
+	}
+	name = normalizeTag(name)
+	if name == "" {
+		return nil, fmt.Errorf("tag name cannot be empty")
+	}
+
+	tag, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "tag not found")
+	}
+	if err := ensureTagNameFree(s.tagRepo, name, id); err != nil {
+		return nil, err
+	}
+
+	tag.Name = name
+	if err := s.tagRepo.Update(tag); err != nil {
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// DeleteTag deletes a tag and removes it from all products
+func (s *tagService) DeleteTag(id uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid tag ID")
+	}
+
+	return s.uow.Do(func(repos repository.Repositories) error {
+		if err := repos.Tags.Delete(id); err != nil {
+			return notFoundOr(err, "tag not found")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaxableLine is an order line to be taxed. Amount is the line total after
+// all discounts, in minor currency units.
+type TaxableLine struct {
+	ProductID uint
+	VariantID uint
+	TaxClass  model.TaxClass
+	Amount    int64
+}
+
+// TaxRequest describes the lines of an order to be taxed, where they are
+// delivered to and when the tax is calculated
+type TaxRequest struct {
+	Currency string
+	Country  string
+	Region   string
+	Date     time.Time
+	Lines    []TaxableLine
+}
+
+// TaxLine is the tax charged on one order line. Amounts are in minor units.
+type TaxLine struct {
+	ProductID     uint
+	VariantID     uint
+	TaxClass      model.TaxClass
+	TaxableAmount int64
+	RateBps       int64
+	Tax           int64
+}
+
+// TaxCalculator calculates the tax on order lines. It returns one TaxLine for
+// every line in the request, in the same order.
+type TaxCalculator interface {
+	CalculateTax(req TaxRequest) ([]TaxLine, error)
+}
+
+// tableTaxCalculator implements TaxCalculator with the admin-maintained tax
+// rate tables
+type tableTaxCalculator struct {
+	repo           repository.TaxRepository
+	defaultRateBps int64
+}
+
+// NewTableTaxCalculator creates a TaxCalculator that looks rates up in the tax
+// rate tables. Lines with no rate for their destination and tax class, and
+// orders without a destination, are taxed at the default rate, expressed as a
+// percentage (8.25 means 8.25%). Exempt lines are never taxed.
+func NewTableTaxCalculator(repo repository.TaxRepository, defaultRatePercent float64) TaxCalculator {
+	return &tableTaxCalculator{
+		repo:           repo,
+		defaultRateBps: percentToBasisPoints(defaultRatePercent),
+	}
+}
+
+// CalculateTax taxes each line at the rate of its tax class, rounding the tax
+// of every line half away from zero
+func (c *tableTaxCalculator) CalculateTax(req TaxRequest) ([]TaxLine, error) {
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+	region := normalizeTaxRegion(req.Region)
+
+	rates := make(map[model.TaxClass]int64)
+	lines := make([]TaxLine, len(req.Lines))
+	for i, line := range req.Lines {
+		taxClass := line.TaxClass
+		if taxClass == "" {
+			taxClass = model.TaxClassStandard
+		}
+
+		rate, ok := rates[taxClass]
+		if !ok {
+			var err error
+			rate, err = c.rateFor(country, region, taxClass, req.Date)
+			if err != nil {
+				return nil, err
+			}
+			rates[taxClass] = rate
+		}
+
+		lines[i] = TaxLine{
+			ProductID:     line.ProductID,
+			VariantID:     line.VariantID,
+			TaxClass:      taxClass,
+			TaxableAmount: line.Amount,
+			RateBps:       rate,
+			Tax:           applyBasisPoints(line.Amount, rate),
+		}
+	}
+	return lines, nil
+}
+
+// rateFor returns the rate in basis points for a tax class in a destination
+func (c *tableTaxCalculator) rateFor(country, region string, taxClass model.TaxClass, at time.Time) (int64, error) {
+	if taxClass == model.TaxClassExempt {
+		return 0, nil
+	}
+	if country == "" {
+		return c.defaultRateBps, nil
+	}
+
+	rate, err := c.repo.FindEffectiveRate(country, region, taxClass, at)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.defaultRateBps, nil
+		}
+		return 0, fmt.Errorf("failed to get tax rate: %w", err)
+	}
+	return percentToBasisPoints(rate.Rate), nil
+}
+
+// normalizeTaxRegion brings a region code into the form tax rates are stored in
+func normalizeTaxRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+// IsValidTaxClass reports whether taxClass is a known product tax class
+func IsValidTaxClass(taxClass model.TaxClass) bool {
+	switch taxClass {
+	case model.TaxClassStandard, model.TaxClassReduced, model.TaxClassExempt:
+		return true
+	}
+	return false
+}
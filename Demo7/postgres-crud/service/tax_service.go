@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+	"strings"
+)
+
+// TaxService defines the interface for maintaining the tax rate tables
+type TaxService interface {
+	GetTaxRates(country string) ([]model.TaxRate, error)
+	GetTaxRateByID(id uint) (*model.TaxRate, error)
+	CreateTaxRate(rate model.TaxRate) (*model.TaxRate, error)
+	UpdateTaxRate(id uint, rate model.TaxRate) (*model.TaxRate, error)
+	DeleteTaxRate(id uint) error
+}
+
+// taxService implements TaxService interface
+type taxService struct {
+	taxRepo repository.TaxRepository
+	uow     repository.UnitOfWork
+}
+
+// NewTaxService creates a new instance of TaxService
+func NewTaxService(taxRepo repository.TaxRepository, uow repository.UnitOfWork) TaxService {
+	return &taxService{
+		taxRepo: taxRepo,
+		uow:     uow,
+	}
+}
+
+// normalizeTaxRate brings the destination of a tax rate into its stored form
+func normalizeTaxRate(rate *model.TaxRate) {
+	rate.Country = strings.ToUpper(strings.TrimSpace(rate.Country))
+	rate.Region = normalizeTaxRegion(rate.Region)
+}
+
+// validateTaxRate checks the fields of a tax rate
+func validateTaxRate(rate model.TaxRate) error {
+	if len(rate.Country) != 2 {
+		return fmt.Errorf("country must be a two-letter country code")
+	}
+	if !IsValidTaxClass(rate.TaxClass) {
+		return fmt.Errorf("invalid tax class %q", rate.TaxClass)
+	}
+	if rate.Rate < 0 || rate.Rate > 100 {
+		return fmt.Errorf("tax rate must be between 0 and 100")
+	}
+	if rate.EffectiveFrom.IsZero() {
+		return fmt.Errorf("effective from date is required")
+	}
+	if rate.EffectiveTo != nil && !rate.EffectiveTo.After(rate.EffectiveFrom) {
+		return fmt.Errorf("tax rate must end after it becomes effective")
+	}
+	return nil
+}
+
+// ensureTaxRateFree returns a conflict error if another rate for the same
+// destination and tax class starts at the same time
+func ensureTaxRateFree(taxes repository.TaxRepository, rate *model.TaxRate) error {
+	exists, err := taxes.RateExists(rate)
+	if err != nil {
+		return fmt.Errorf("failed to check tax rate: %w", err)
+	}
+	if exists {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: "a tax rate for this destination and tax class already starts at that time",
+		}
+	}
+	return nil
+}
+
+// GetTaxRates retrieves the tax rates of a country, or all tax rates when
+// country is empty
+func (s *taxService) GetTaxRates(country string) ([]model.TaxRate, error) {
+	rates, err := s.taxRepo.GetRates(strings.ToUpper(strings.TrimSpace(country)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax rates: %w", err)
+	}
+	return rates, nil
+}
+
+// GetTaxRateByID retrieves a tax rate by ID
+func (s *taxService) GetTaxRateByID(id uint) (*model.TaxRate, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid tax rate ID")
+	}
+
+	rate, err := s.taxRepo.GetRateByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "tax rate not found")
+	}
+
+	return rate, nil
+}
+
+// CreateTaxRate creates a new tax rate
+func (s *taxService) CreateTaxRate(rate model.TaxRate) (*model.TaxRate, error) {
+	rate.ID = 0
+	normalizeTaxRate(&rate)
+	if err := validateTaxRate(rate); err != nil {
+		return nil, err
+	}
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		if err := ensureTaxRateFree(repos.Taxes, &rate); err != nil {
+			return err
+		}
+		if err := repos.Taxes.CreateRate(&rate); err != nil {
+			return fmt.Errorf("failed to create tax rate: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// UpdateTaxRate replaces the destination, class, rate and dates of a tax rate.
+// Orders that have already been placed keep the tax they were charged.
+func (s *taxService) UpdateTaxRate(id uint, rate model.TaxRate) (*model.TaxRate, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid tax rate ID")
+	}
+	normalizeTaxRate(&rate)
+	if err := validateTaxRate(rate); err != nil {
+		return nil, err
+	}
+
+	var existing *model.TaxRate
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		existing, err = repos.Taxes.GetRateByID(id)
+		if err != nil {
+			return notFoundOr(err, "tax rate not found")
+		}
+
+		existing.Country = rate.Country
+		existing.Region = rate.Region
+		existing.TaxClass = rate.TaxClass
+		existing.Rate = rate.Rate
+		existing.EffectiveFrom = rate.EffectiveFrom
+		existing.EffectiveTo = rate.EffectiveTo
+		if err := ensureTaxRateFree(repos.Taxes, existing); err != nil {
+			return err
+		}
+		if err := repos.Taxes.UpdateRate(existing); err != nil {
+			return fmt.Errorf("failed to update tax rate: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// DeleteTaxRate deletes a tax rate
+func (s *taxService) DeleteTaxRate(id uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid tax rate ID")
+	}
+
+	if err := s.taxRepo.DeleteRate(id); err != nil {
+		return notFoundOr(err, "tax rate not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/repository"
+
+	"gorm.io/gorm"
+)
+
+// TrashService defines the interface for viewing, restoring and permanently
+// removing soft-deleted orders and products
+type TrashService interface {
+	GetDeletedOrders() ([]model.Order, error)
+	GetDeletedProducts() ([]model.Product, error)
+	RestoreOrder(id uint) (*model.Order, error)
+	RestoreProduct(id uint) (*model.Product, error)
+	PurgeOrder(id uint) error
+	PurgeProduct(id uint) error
+}
+
+// trashService implements TrashService interface
+type trashService struct {
+	orderRepo   repository.OrderRepository
+	productRepo repository.ProductRepository
+	uow         repository.UnitOfWork
+}
+
+// NewTrashService creates a new instance of TrashService
+func NewTrashService(orderRepo repository.OrderRepository, productRepo repository.ProductRepository, uow repository.UnitOfWork) TrashService {
+	return &trashService{
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		uow:         uow,
+	}
+}
+
+// GetDeletedOrders retrieves the soft-deleted orders
+func (s *trashService) GetDeletedOrders() ([]model.Order, error) {
+	orders, err := s.orderRepo.GetDeleted()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted orders: %w", err)
+	}
+	return orders, nil
+}
+
+// GetDeletedProducts retrieves the soft-deleted products
+func (s *trashService) GetDeletedProducts() ([]model.Product, error) {
+	products, err := s.productRepo.GetDeleted()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted products: %w", err)
+	}
+	return products, nil
+}
+
+// RestoreOrder brings a soft-deleted order back. Orders whose units were
+// returned to stock when they were deleted take them from stock again, which
+// fails with a conflict if not enough is left or a product has been deleted.
+// Orders of a customer that has since been deleted cannot be restored.
+func (s *trashService) RestoreOrder(id uint) (*model.Order, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid order ID")
+	}
+
+	var order *model.Order
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		order, err = repos.Orders.GetDeletedByID(id)
+		if err != nil {
+			return notFoundOr(err, "deleted order not found")
+		}
+
+		if order.CustomerID != nil {
+			if _, err := repos.Customers.GetByID(*order.CustomerID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return &apierrors.APIError{
+						Code:    apierrors.ErrConflict.Code,
+						Message: fmt.Sprintf("customer %d of the order has been deleted", *order.CustomerID),
+					}
+				}
+				return fmt.Errorf("failed to get customer: %w", err)
+			}
+		}
+
+		if err := repos.Orders.Restore(order); err != nil {
+			if errors.Is(err, repository.ErrConcurrentModification) {
+				return &apierrors.APIError{
+					Code:    apierrors.ErrConflict.Code,
+					Message: "order was restored or changed by another request",
+				}
+			}
+			return fmt.Errorf("failed to restore order: %w", err)
+		}
+
+		// Deleting the order returned its units to stock
+		if orderHoldsStock(order.Status) {
+			items, err := repos.Orders.GetItems(order.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get order items: %w", err)
+			}
+			for _, item := range items {
+				movement := orderStockMovement(order.ID, item.ProductID, item.VariantID, -item.Quantity, model.StockReasonOrderRestored)
+				if err := applyStockMovement(repos, movement); err != nil {
+					if errors.Is(err, repository.ErrInsufficientStock) {
+						return &apierrors.APIError{
+							Code:    apierrors.ErrConflict.Code,
+							Message: fmt.Sprintf("cannot restore order: %v", err),
+						}
+					}
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return &apierrors.APIError{
+							Code:    apierrors.ErrConflict.Code,
+							Message: fmt.Sprintf("cannot restore order: product %d or its variant has been deleted", item.ProductID),
+						}
+					}
+					return fmt.Errorf("failed to take product stock: %w", err)
+				}
+			}
+		}
+
+		order, err = repos.Orders.GetByIDWithProducts(order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reload order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// RestoreProduct brings a soft-deleted product back. A product whose category
+// has been deleted in the meantime is restored without a category.
+func (s *trashService) RestoreProduct(id uint) (*model.Product, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	var product *model.Product
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		product, err = repos.Products.GetDeletedByID(id)
+		if err != nil {
+			return notFoundOr(err, "deleted product not found")
+		}
+
+		if product.CategoryID != nil {
+			if _, err := repos.Categories.GetByID(*product.CategoryID); err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("failed to get category: %w", err)
+				}
+				product.CategoryID = nil
+			}
+		}
+
+		if err := repos.Products.Restore(product); err != nil {
+			if errors.Is(err, repository.ErrConcurrentModification) {
+				return &apierrors.APIError{
+					Code:    apierrors.ErrConflict.Code,
+					Message: "product was restored or changed by another request",
+				}
+			}
+			return fmt.Errorf("failed to restore product: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// PurgeOrder permanently removes a soft-deleted order
+func (s *trashService) PurgeOrder(id uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid order ID")
+	}
+
+	return s.uow.Do(func(repos repository.Repositories) error {
+		if err := repos.Orders.Purge(id); err != nil {
+			return notFoundOr(err, "deleted order not found")
+		}
+		return nil
+	})
+}
+
+// PurgeProduct permanently removes a soft-deleted product. Products that are
+// still on order lines, of live or deleted orders, cannot be purged.
+func (s *trashService) PurgeProduct(id uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid product ID")
+	}
+
+	return s.uow.Do(func(repos repository.Repositories) error {
+		lines, err := repos.Products.CountOrderLines(id)
+		if err != nil {
+			return fmt.Errorf("failed to check product order lines: %w", err)
+		}
+		if lines > 0 {
+			return &apierrors.APIError{
+				Code:    apierrors.ErrConflict.Code,
+				Message: fmt.Sprintf("product %d is on %d order lines and cannot be purged", id, lines),
+			}
+		}
+
+		if err := repos.Products.Purge(id); err != nil {
+			return notFoundOr(err, "deleted product not found")
+		}
+		return nil
+	})
+}
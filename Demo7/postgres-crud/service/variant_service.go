@@ -0,0 +1,276 @@
+package service
+
+import (
+	"fmt"
+	apierrors "postgres-crud/internal/errors"
+	"postgres-crud/model"
+	"postgres-crud/money"
+	"postgres-crud/repository"
+	"strings"
+)
+
+// VariantService defines the interface for product variant business logic
+type VariantService interface {
+	CreateVariant(productID uint, sku string, options model.VariantOptions, price *money.Money, stock int) (*model.ProductVariant, error)
+	GetVariantByID(id uint) (*model.ProductVariant, error)
+	GetVariantBySKU(sku string) (*model.ProductVariant, error)
+	GetProductVariants(productID uint) ([]model.ProductVariant, error)
+	UpdateVariant(id uint, sku string, options model.VariantOptions, price *money.Money, stock int, expectedVersion *uint) (*model.ProductVariant, error)
+	DeleteVariant(id uint, expectedVersion *uint) error
+}
+
+// variantService implements VariantService interface
+type variantService struct {
+	variantRepo repository.VariantRepository
+	productRepo repository.ProductRepository
+	uow         repository.UnitOfWork
+}
+
+// NewVariantService creates a new instance of VariantService
+func NewVariantService(variantRepo repository.VariantRepository, productRepo repository.ProductRepository, uow repository.UnitOfWork) VariantService {
+	return &variantService{
+		variantRepo: variantRepo,
+		productRepo: productRepo,
+		uow:         uow,
+	}
+}
+
+// normalizeSKU trims and upper-cases a SKU
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// ensureSKUFree returns a conflict error if a variant other than id already
+// uses sku
+func ensureSKUFree(variants repository.VariantRepository, sku string, id uint) error {
+	exists, err := variants.SKUExists(sku, id)
+	if err != nil {
+		return fmt.Errorf("failed to check SKU: %w", err)
+	}
+	if exists {
+		return &apierrors.APIError{
+			Code:    apierrors.ErrConflict.Code,
+			Message: "SKU already exists",
+			Details: fmt.Sprintf("SKU %q is used by another variant", sku),
+		}
+	}
+	return nil
+}
+
+// validateVariant checks the SKU, price override and stock of a variant
+func validateVariant(sku string, price *money.Money, stock int) error {
+	if sku == "" {
+		return fmt.Errorf("SKU cannot be empty")
+	}
+	if price != nil && price.IsNegative() {
+		return fmt.Errorf("variant price cannot be negative")
+	}
+	if stock < 0 {
+		return fmt.Errorf("variant stock cannot be negative")
+	}
+	return nil
+}
+
+// CreateVariant creates a variant of a product and records its initial stock
+// in the stock ledger. A price override without a currency is in the product's
+// currency.
+func (s *variantService) CreateVariant(productID uint, sku string, options model.VariantOptions, price *money.Money, stock int) (*model.ProductVariant, error) {
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+	sku = normalizeSKU(sku)
+	if err := validateVariant(sku, price, stock); err != nil {
+		return nil, err
+	}
+
+	var variant *model.ProductVariant
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		product, err := repos.Products.GetByID(productID)
+		if err != nil {
+			return notFoundOr(err, "product not found")
+		}
+		if err := ensureSKUFree(repos.Variants, sku, 0); err != nil {
+			return err
+		}
+
+		if price != nil && price.Currency == "" {
+			price.Currency = product.Currency
+		}
+		if options == nil {
+			options = model.VariantOptions{}
+		}
+		variant = &model.ProductVariant{
+			ProductID: productID,
+			SKU:       sku,
+			Options:   options,
+			Price:     price,
+			Currency:  product.Currency,
+		}
+		if err := repos.Variants.Create(variant); err != nil {
+			return fmt.Errorf("failed to create variant: %w", err)
+		}
+
+		movement := &model.StockMovement{
+			ProductID:     productID,
+			VariantID:     &variant.ID,
+			Delta:         stock,
+			Reason:        model.StockReasonInitial,
+			ReferenceType: model.StockReferenceProduct,
+			ReferenceID:   &productID,
+			Actor:         model.StockActorSystem,
+		}
+		if err := applyStockMovement(repos, movement); err != nil {
+			return fmt.Errorf("failed to record initial stock: %w", err)
+		}
+
+		// Reload to pick up the stock and version written by the movement
+		variant, err = repos.Variants.GetByID(variant.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reload variant: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+// GetVariantByID retrieves a variant by its ID
+func (s *variantService) GetVariantByID(id uint) (*model.ProductVariant, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid variant ID")
+	}
+
+	variant, err := s.variantRepo.GetByID(id)
+	if err != nil {
+		return nil, notFoundOr(err, "variant not found")
+	}
+
+	return variant, nil
+}
+
+// GetVariantBySKU retrieves a variant, together with its product, by SKU
+func (s *variantService) GetVariantBySKU(sku string) (*model.ProductVariant, error) {
+	sku = normalizeSKU(sku)
+	if sku == "" {
+		return nil, fmt.Errorf("SKU cannot be empty")
+	}
+
+	variant, err := s.variantRepo.GetBySKU(sku)
+	if err != nil {
+		return nil, notFoundOr(err, "SKU not found")
+	}
+
+	return variant, nil
+}
+
+// GetProductVariants retrieves the variants of a product
+func (s *variantService) GetProductVariants(productID uint) ([]model.ProductVariant, error) {
+	if productID == 0 {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, notFoundOr(err, "product not found")
+	}
+
+	variants, err := s.variantRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	}
+
+	return variants, nil
+}
+
+// UpdateVariant updates a variant. A nil price removes the price override. A
+// change of stock is recorded in the stock ledger in the same transaction.
+func (s *variantService) UpdateVariant(id uint, sku string, options model.VariantOptions, price *money.Money, stock int, expectedVersion *uint) (*model.ProductVariant, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("invalid variant ID")
+	}
+	sku = normalizeSKU(sku)
+	if err := validateVariant(sku, price, stock); err != nil {
+		return nil, err
+	}
+
+	var variant *model.ProductVariant
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		variant, err = repos.Variants.GetByID(id)
+		if err != nil {
+			return notFoundOr(err, "variant not found")
+		}
+		if err := checkVersion(expectedVersion, variant.Version); err != nil {
+			return err
+		}
+		if err := ensureSKUFree(repos.Variants, sku, id); err != nil {
+			return err
+		}
+
+		if price != nil && price.Currency == "" {
+			price.Currency = variant.Currency
+		}
+		if options == nil {
+			options = model.VariantOptions{}
+		}
+		variant.SKU = sku
+		variant.Options = options
+		variant.Price = price
+
+		if err := repos.Variants.Update(variant); err != nil {
+			return versionConflictOr(err, expectedVersion, "failed to update variant")
+		}
+
+		// Stock is only changed through the stock ledger
+		if delta := stock - variant.Stock; delta != 0 {
+			movement := &model.StockMovement{
+				ProductID:     variant.ProductID,
+				VariantID:     &variant.ID,
+				Delta:         delta,
+				Reason:        model.StockReasonProductUpdate,
+				ReferenceType: model.StockReferenceProduct,
+				ReferenceID:   &variant.ProductID,
+				Actor:         model.StockActorSystem,
+			}
+			if err := applyStockMovement(repos, movement); err != nil {
+				return fmt.Errorf("failed to update variant stock: %w", err)
+			}
+
+			// Reload to pick up the stock and version written by the movement
+			variant, err = repos.Variants.GetByID(id)
+			if err != nil {
+				return fmt.Errorf("failed to reload variant: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+// DeleteVariant deletes a variant by ID. A non-nil expectedVersion must match
+// the variant's current version.
+func (s *variantService) DeleteVariant(id uint, expectedVersion *uint) error {
+	if id == 0 {
+		return fmt.Errorf("invalid variant ID")
+	}
+
+	variant, err := s.variantRepo.GetByID(id)
+	if err != nil {
+		return notFoundOr(err, "variant not found")
+	}
+	if err := checkVersion(expectedVersion, variant.Version); err != nil {
+		return err
+	}
+
+	if err := s.variantRepo.DeleteByModel(variant); err != nil {
+		return versionConflictOr(err, expectedVersion, "failed to delete variant")
+	}
+
+	return nil
+}